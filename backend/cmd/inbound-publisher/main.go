@@ -0,0 +1,107 @@
+// Command inbound-publisher posts fake mail notifications to the backend's
+// /inbound/mails endpoint so event-driven ingestion can be exercised locally.
+// The "sns" format is unsigned and needs VERIFY_SNS_SIGNATURE=false.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func main() {
+	endpoint := flag.String("url", "http://localhost:8080/inbound/mails", "inbound endpoint URL")
+	token := flag.String("token", "", "inbound token (INBOUND_TOKEN)")
+	bucket := flag.String("bucket", "", "bucket name")
+	key := flag.String("key", "", "object key")
+	format := flag.String("format", "plain", "payload format: plain, s3, ses or sns")
+	flag.Parse()
+
+	if *bucket == "" || *key == "" {
+		log.Fatal("-bucket and -key are required")
+	}
+
+	body, err := buildPayload(*format, *bucket, *key)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, *endpoint, bytes.NewReader(body))
+	if err != nil {
+		log.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Inbound-Token", *token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(resp.Body)
+	fmt.Printf("%d %s\n", resp.StatusCode, respBody)
+}
+
+func buildPayload(format, bucket, key string) ([]byte, error) {
+	switch format {
+	case "plain":
+		return json.Marshal(map[string]string{"bucket": bucket, "key": key})
+	case "s3":
+		return json.Marshal(s3Event(bucket, key))
+	case "ses":
+		return json.Marshal(sesReceipt(bucket, key))
+	case "sns":
+		inner, err := json.Marshal(s3Event(bucket, key))
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(map[string]string{
+			"Type":             "Notification",
+			"MessageId":        uuid.NewString(),
+			"TopicArn":         "arn:aws:sns:ap-northeast-1:000000000000:local-mail",
+			"Message":          string(inner),
+			"Timestamp":        time.Now().UTC().Format(time.RFC3339),
+			"SignatureVersion": "1",
+			"Signature":        "",
+			"SigningCertURL":   "",
+		})
+	default:
+		return nil, fmt.Errorf("unknown format: %s", format)
+	}
+}
+
+func s3Event(bucket, key string) map[string]interface{} {
+	return map[string]interface{}{
+		"Records": []map[string]interface{}{
+			{
+				"eventName": "ObjectCreated:Put",
+				"s3": map[string]interface{}{
+					"bucket": map[string]string{"name": bucket},
+					"object": map[string]string{"key": url.QueryEscape(key)},
+				},
+			},
+		},
+	}
+}
+
+func sesReceipt(bucket, key string) map[string]interface{} {
+	return map[string]interface{}{
+		"notificationType": "Received",
+		"receipt": map[string]interface{}{
+			"action": map[string]string{
+				"type":       "S3",
+				"bucketName": bucket,
+				"objectKey":  key,
+			},
+		},
+	}
+}
@@ -0,0 +1,21 @@
+package entity
+
+import "time"
+
+type DomainSyncState struct {
+	DomainID       string     `json:"domain_id" gorm:"column:domain_id;primaryKey"`
+	LastKey        string     `json:"last_key" gorm:"column:last_key"`
+	Status         string     `json:"status" gorm:"column:status"` // "running", "ok" or "failed"
+	LastSynced     int        `json:"last_synced" gorm:"column:last_synced;default:0"`
+	TotalSynced    int64      `json:"total_synced" gorm:"column:total_synced;default:0"`
+	LastError      string     `json:"last_error,omitempty" gorm:"column:last_error;type:text"`
+	LastRunAt      *time.Time `json:"last_run_at,omitempty" gorm:"column:last_run_at"`
+	LastFinishedAt *time.Time `json:"last_finished_at,omitempty" gorm:"column:last_finished_at"`
+	LastSuccessAt  *time.Time `json:"last_success_at,omitempty" gorm:"column:last_success_at"`
+	LastFullScanAt *time.Time `json:"last_full_scan_at,omitempty" gorm:"column:last_full_scan_at"`
+	UpdatedAt      time.Time  `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (DomainSyncState) TableName() string {
+	return "domain_sync_states"
+}
@@ -0,0 +1,53 @@
+package entity
+
+import "time"
+
+// A draft is sending while it is being queued, so a second send is refused.
+const (
+	DraftStatusEditing = "editing"
+	DraftStatusSending = "sending"
+)
+
+// Draft is an unsent compose state owned by a Firebase user. Uploaded files
+// are kept in DraftAttachment; AttachmentRefs point at attachments of stored
+// mails and are resolved when the draft is sent.
+type Draft struct {
+	ID             string            `json:"id" gorm:"column:id;primaryKey"`
+	UID            string            `json:"-" gorm:"column:uid;index"`
+	To             []string          `json:"to" gorm:"column:to_addresses;type:text;serializer:json"`
+	Cc             []string          `json:"cc" gorm:"column:cc_addresses;type:text;serializer:json"`
+	Bcc            []string          `json:"bcc" gorm:"column:bcc_addresses;type:text;serializer:json"`
+	ReplyTo        string            `json:"reply_to" gorm:"column:reply_to"`
+	FromAddress    string            `json:"from_address" gorm:"column:from_address"`
+	Subject        string            `json:"subject" gorm:"column:subject"`
+	Body           string            `json:"body" gorm:"column:body;type:text"`
+	HTMLBody       string            `json:"html_body" gorm:"column:html_body;type:text"`
+	SendType       string            `json:"send_type" gorm:"column:send_type;default:new"`
+	ThreadID       string            `json:"thread_id" gorm:"column:thread_id"`
+	ReplyCode      string            `json:"reply_code" gorm:"column:reply_code"`
+	InReplyTo      string            `json:"in_reply_to" gorm:"column:in_reply_to"`
+	References     []string          `json:"references" gorm:"column:reference_ids;type:text;serializer:json"`
+	AttachmentRefs []AttachmentRef   `json:"attachment_refs" gorm:"column:attachment_refs;type:text;serializer:json"`
+	Status         string            `json:"status" gorm:"column:status;default:editing"`
+	Attachments    []DraftAttachment `json:"attachments" gorm:"foreignKey:DraftID"`
+	CreatedAt      time.Time         `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt      time.Time         `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (Draft) TableName() string {
+	return "drafts"
+}
+
+type DraftAttachment struct {
+	ID          string    `json:"id" gorm:"column:id;primaryKey"`
+	DraftID     string    `json:"draft_id" gorm:"column:draft_id;index"`
+	Filename    string    `json:"filename" gorm:"column:filename"`
+	ContentType string    `json:"content_type" gorm:"column:content_type"`
+	Size        int       `json:"size" gorm:"column:size"`
+	Content     []byte    `json:"-" gorm:"column:content;type:bytea"`
+	CreatedAt   time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+}
+
+func (DraftAttachment) TableName() string {
+	return "draft_attachments"
+}
@@ -0,0 +1,20 @@
+package entity
+
+import "time"
+
+// EmailIdentityStatus is the SES state of a verified address or domain.
+// An address may be sent from when it, or its domain, is verified for sending.
+type EmailIdentityStatus struct {
+	Identity string `json:"identity"`
+	// Type is EMAIL_ADDRESS or DOMAIN
+	Type string `json:"type"`
+	// VerificationStatus is PENDING, SUCCESS, FAILED, TEMPORARY_FAILURE or NOT_STARTED
+	VerificationStatus string `json:"verification_status"`
+	VerifiedForSending bool   `json:"verified_for_sending"`
+	DKIMStatus         string `json:"dkim_status,omitempty"`
+	DKIMSigningEnabled bool   `json:"dkim_signing_enabled"`
+	MailFromDomain     string `json:"mail_from_domain,omitempty"`
+	MailFromStatus     string `json:"mail_from_status,omitempty"`
+	// CheckedAt is when the status was read from SES
+	CheckedAt time.Time `json:"checked_at"`
+}
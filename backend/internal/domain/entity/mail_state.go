@@ -1,17 +1,55 @@
 package entity
 
-import "time"
+import (
+	"net/mail"
+	"strings"
+	"time"
+)
 
 type MailState struct {
-	S3Key            string    `json:"s3_key" gorm:"column:s3_key;primaryKey"`
-	DomainID         string    `json:"domain_id" gorm:"column:domain_id;primaryKey"`
-	RecipientAddress string    `json:"recipient_address" gorm:"column:recipient_address"`
-	IsRead           bool      `json:"is_read" gorm:"column:is_read;default:false"`
-	IsStarred        bool      `json:"is_starred" gorm:"column:is_starred;default:false"`
-	ThreadID         *string   `json:"thread_id,omitempty" gorm:"column:thread_id"`
-	CreatedAt        time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	S3Key            string     `json:"s3_key" gorm:"column:s3_key;primaryKey"`
+	DomainID         string     `json:"domain_id" gorm:"column:domain_id;primaryKey"`
+	RecipientAddress string     `json:"recipient_address" gorm:"column:recipient_address"`
+	IsRead           bool       `json:"is_read" gorm:"column:is_read;default:false"`
+	IsStarred        bool       `json:"is_starred" gorm:"column:is_starred;default:false"`
+	ThreadID         *string    `json:"thread_id,omitempty" gorm:"column:thread_id"`
+	MessageID        string     `json:"message_id" gorm:"column:message_id;index"`
+	InReplyTo        string     `json:"in_reply_to,omitempty" gorm:"column:in_reply_to"`
+	References       string     `json:"references,omitempty" gorm:"column:reference_ids;type:text"`
+	Subject          string     `json:"subject" gorm:"column:subject"`
+	FromAddress      string     `json:"from" gorm:"column:from_address"`
+	ToAddress        string     `json:"to" gorm:"column:to_address"`
+	Cc               string     `json:"cc,omitempty" gorm:"column:cc"`
+	MailDate         time.Time  `json:"date" gorm:"column:mail_date"`
+	Snippet          string     `json:"snippet" gorm:"column:snippet;type:text"`
+	AttachmentCount  int        `json:"attachment_count" gorm:"column:attachment_count;default:0"`
+	AttachmentSize   int64      `json:"attachment_size" gorm:"column:attachment_size;default:0"`
+	IndexedAt        *time.Time `json:"indexed_at,omitempty" gorm:"column:indexed_at"`
+	CreatedAt        time.Time  `json:"created_at" gorm:"column:created_at;autoCreateTime"`
 }
 
 func (MailState) TableName() string {
 	return "mail_states"
 }
+
+// AddressedTo reports whether address is among the mail's recipients.
+func (m *MailState) AddressedTo(address string) bool {
+	for _, field := range []string{m.RecipientAddress, m.ToAddress, m.Cc} {
+		if field == "" {
+			continue
+		}
+		addresses, err := mail.ParseAddressList(field)
+		if err != nil {
+			if strings.Contains(strings.ToLower(field), strings.ToLower(address)) {
+				return true
+			}
+			continue
+		}
+		for _, addr := range addresses {
+			if strings.EqualFold(addr.Address, address) {
+				return true
+			}
+		}
+	}
+	return false
+}
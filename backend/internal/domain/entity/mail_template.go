@@ -0,0 +1,21 @@
+package entity
+
+import "time"
+
+// MailTemplate is a reusable message owned by a Firebase user. Subject and
+// TextBody are Go text/template sources, HTMLBody is an html/template source;
+// all three are rendered with the variables passed to /api/send.
+type MailTemplate struct {
+	ID        string    `json:"id" gorm:"column:id;primaryKey"`
+	UID       string    `json:"-" gorm:"column:uid;index"`
+	Name      string    `json:"name" gorm:"column:name"`
+	Subject   string    `json:"subject" gorm:"column:subject"`
+	TextBody  string    `json:"text_body" gorm:"column:text_body;type:text"`
+	HTMLBody  string    `json:"html_body" gorm:"column:html_body;type:text"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (MailTemplate) TableName() string {
+	return "mail_templates"
+}
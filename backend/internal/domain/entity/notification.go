@@ -0,0 +1,167 @@
+package entity
+
+import (
+	"fmt"
+	"net/mail"
+	"path"
+	"strings"
+	"time"
+)
+
+// Notification channel types.
+const (
+	NotificationChannelDiscord = "discord"
+	NotificationChannelSlack   = "slack"
+	NotificationChannelTeams   = "teams"
+)
+
+// NotificationChannel posts new mail notifications to an incoming webhook.
+// With RecipientAddress set, only mail addressed to it is notified. A channel
+// targeted by a NotificationRule only receives the mail its rules match.
+type NotificationChannel struct {
+	ID               string `json:"id"`
+	Name             string `json:"name,omitempty"`
+	Type             string `json:"type"`
+	WebhookURL       string `json:"webhook_url"`
+	RecipientAddress string `json:"recipient_address,omitempty"`
+}
+
+// NotificationRule routes the mail matching all of its set conditions to
+// ChannelID, mentioning Mention (e.g. a Discord role ID or Slack user ID).
+type NotificationRule struct {
+	ID               string `json:"id"`
+	Name             string `json:"name,omitempty"`
+	RecipientAddress string `json:"recipient_address,omitempty"`
+	// SenderPattern is a glob such as "*@example.com"; without wildcards it
+	// matches senders containing it
+	SenderPattern string `json:"sender_pattern,omitempty"`
+	// SubjectKeywords match when the subject contains any of them
+	SubjectKeywords []string `json:"subject_keywords,omitempty"`
+	// HasAttachment, when set, requires mail with (true) or without (false) attachments
+	HasAttachment *bool  `json:"has_attachment,omitempty"`
+	ChannelID     string `json:"channel_id"`
+	Mention       string `json:"mention,omitempty"`
+}
+
+// Matches reports whether state satisfies every condition of the rule.
+func (r *NotificationRule) Matches(state *MailState) bool {
+	if r.RecipientAddress != "" && !state.AddressedTo(r.RecipientAddress) {
+		return false
+	}
+	if r.SenderPattern != "" && !matchSender(r.SenderPattern, state.FromAddress) {
+		return false
+	}
+	if len(r.SubjectKeywords) > 0 {
+		subject := strings.ToLower(state.Subject)
+		matched := false
+		for _, keyword := range r.SubjectKeywords {
+			if keyword != "" && strings.Contains(subject, strings.ToLower(keyword)) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if r.HasAttachment != nil && *r.HasAttachment != (state.AttachmentCount > 0) {
+		return false
+	}
+	return true
+}
+
+func matchSender(pattern, from string) bool {
+	pattern = strings.ToLower(pattern)
+	sender := strings.ToLower(from)
+	if addr, err := mail.ParseAddress(from); err == nil {
+		sender = strings.ToLower(addr.Address)
+	}
+	if strings.ContainsAny(pattern, "*?[") {
+		matched, err := path.Match(pattern, sender)
+		return err == nil && matched
+	}
+	return strings.Contains(strings.ToLower(from), pattern)
+}
+
+// QuietHours holds notifications from Start until End ("15:04", in
+// TimeZone); they are sent as one digest once quiet hours end. End before
+// Start spans midnight.
+type QuietHours struct {
+	Enabled  bool   `json:"enabled"`
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	TimeZone string `json:"time_zone"`
+}
+
+// Validate checks the times and time zone of enabled quiet hours.
+func (q *QuietHours) Validate() error {
+	if !q.Enabled {
+		return nil
+	}
+	if _, err := time.Parse("15:04", q.Start); err != nil {
+		return fmt.Errorf("invalid quiet hours start: %q", q.Start)
+	}
+	if _, err := time.Parse("15:04", q.End); err != nil {
+		return fmt.Errorf("invalid quiet hours end: %q", q.End)
+	}
+	if _, err := time.LoadLocation(q.TimeZone); err != nil {
+		return fmt.Errorf("invalid time zone: %q", q.TimeZone)
+	}
+	return nil
+}
+
+// Active reports whether now falls within the quiet hours.
+func (q *QuietHours) Active(now time.Time) bool {
+	if q == nil || !q.Enabled || q.Start == q.End {
+		return false
+	}
+	start, err1 := time.Parse("15:04", q.Start)
+	end, err2 := time.Parse("15:04", q.End)
+	location, err3 := time.LoadLocation(q.TimeZone)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return false
+	}
+
+	local := now.In(location)
+	minute := local.Hour()*60 + local.Minute()
+	from := start.Hour()*60 + start.Minute()
+	to := end.Hour()*60 + end.Minute()
+	if from < to {
+		return minute >= from && minute < to
+	}
+	return minute >= from || minute < to
+}
+
+// MailNotification announces newly received mail. Total can exceed
+// len(Mails) when the arrivals are split over several notifications. A
+// digest collects the mail held during quiet hours.
+type MailNotification struct {
+	Total  int
+	Digest bool
+	Mails  []NotifiedMail
+}
+
+type NotifiedMail struct {
+	From      string    `json:"from"`
+	Recipient string    `json:"recipient"`
+	Subject   string    `json:"subject"`
+	Snippet   string    `json:"snippet"`
+	Date      time.Time `json:"date"`
+	// URL opens the mail's thread, or the mail list when it has none
+	URL string `json:"url"`
+	// Mentions are the raw mentions of the rules that matched
+	Mentions []string `json:"mentions,omitempty"`
+}
+
+// HeldNotification is a mail notification withheld during the user's quiet hours.
+type HeldNotification struct {
+	ID        string       `json:"id" gorm:"column:id;primaryKey"`
+	UID       string       `json:"uid" gorm:"column:uid;index"`
+	ChannelID string       `json:"channel_id" gorm:"column:channel_id"`
+	Mail      NotifiedMail `json:"mail" gorm:"column:mail;type:text;serializer:json"`
+	CreatedAt time.Time    `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+}
+
+func (HeldNotification) TableName() string {
+	return "held_notifications"
+}
@@ -0,0 +1,87 @@
+package entity
+
+import "time"
+
+const (
+	OutboxBatchStatusScheduled = "scheduled"
+	OutboxBatchStatusReleased  = "released"
+	OutboxBatchStatusCanceled  = "canceled"
+)
+
+// OutboxBatch kinds. A merge batch sends an individually rendered message to
+// every row of an uploaded CSV instead of one shared message.
+const (
+	OutboxBatchKindSend  = "send"
+	OutboxBatchKindMerge = "merge"
+)
+
+const (
+	OutboxStatusQueued  = "queued"
+	OutboxStatusSending = "sending"
+	OutboxStatusSent    = "sent"
+	OutboxStatusFailed  = "failed"
+)
+
+// OutboxBatch is one send request. It stays scheduled, and can be edited or
+// canceled, until SendAt; the worker then releases it into one OutboxMessage
+// per recipient. Payload holds the message content and recipients.
+type OutboxBatch struct {
+	ID        string    `json:"id" gorm:"column:id;primaryKey"`
+	UID       string    `json:"uid" gorm:"column:uid;index"`
+	ThreadID  string    `json:"thread_id" gorm:"column:thread_id"`
+	Kind      string    `json:"kind" gorm:"column:kind;default:send"`
+	SendType  string    `json:"send_type" gorm:"column:send_type"`
+	Subject   string    `json:"subject" gorm:"column:subject"`
+	Status    string    `json:"status" gorm:"column:status;index"`
+	SendAt    time.Time `json:"send_at" gorm:"column:send_at;index"`
+	Payload   []byte    `json:"-" gorm:"column:payload;type:bytea"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (OutboxBatch) TableName() string {
+	return "outbox_batches"
+}
+
+// OutboxMessage is the delivery of a batch to a single recipient.
+type OutboxMessage struct {
+	ID      string `json:"id" gorm:"column:id;primaryKey"`
+	BatchID string `json:"batch_id" gorm:"column:batch_id;index"`
+	// RowNumber is the 1-based CSV row of a merge batch; 0 for other batches
+	RowNumber      int    `json:"row_number,omitempty" gorm:"column:row_number;default:0"`
+	ManagementCode string `json:"management_code" gorm:"column:management_code"`
+	MessageID      string `json:"message_id" gorm:"column:message_id"`
+	// SESMessageID replaces MessageID in the header of mail sent through SES
+	SESMessageID   string `json:"ses_message_id,omitempty" gorm:"column:ses_message_id"`
+	RecipientEmail string `json:"recipient_email" gorm:"column:recipient_email"`
+	RecipientType  string `json:"recipient_type" gorm:"column:recipient_type"`
+	Status         string `json:"status" gorm:"column:status;index"`
+	// ClaimToken identifies the claim a message is being sent under; a
+	// requeued message gets a new one when it is claimed again.
+	ClaimToken    string     `json:"-" gorm:"column:claim_token"`
+	Attempts      int        `json:"attempts" gorm:"column:attempts;default:0"`
+	NextAttemptAt time.Time  `json:"next_attempt_at" gorm:"column:next_attempt_at;index"`
+	LastError     string     `json:"last_error,omitempty" gorm:"column:last_error"`
+	SentAt        *time.Time `json:"sent_at,omitempty" gorm:"column:sent_at"`
+	CreatedAt     time.Time  `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt     time.Time  `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (OutboxMessage) TableName() string {
+	return "outbox_messages"
+}
+
+// OutboxMergeRow is the rendered content for one row of a merge batch. Rows
+// are kept out of the batch payload so a large merge is read one row at a time.
+type OutboxMergeRow struct {
+	BatchID   string `gorm:"column:batch_id;primaryKey"`
+	RowNumber int    `gorm:"column:row_number;primaryKey"`
+	Email     string `gorm:"column:email"`
+	Subject   string `gorm:"column:subject"`
+	TextBody  string `gorm:"column:text_body;type:text"`
+	HTMLBody  string `gorm:"column:html_body;type:text"`
+}
+
+func (OutboxMergeRow) TableName() string {
+	return "outbox_merge_rows"
+}
@@ -0,0 +1,25 @@
+package entity
+
+// ManagementCodeHeader carries the management code of every outgoing copy.
+const ManagementCodeHeader = "X-Mailer-Management-Code"
+
+// OutgoingMail is a single message copy. To and Cc are written to the headers;
+// the message is delivered only to EnvelopeTo.
+type OutgoingMail struct {
+	From       string
+	To         []string
+	Cc         []string
+	ReplyTo    string
+	EnvelopeTo string
+	// EnvelopeFrom is the SMTP MAIL FROM (bounce address); empty uses From
+	EnvelopeFrom string
+	MessageID    string
+	InReplyTo    string
+	References   []string
+	Subject      string
+	TextBody     string
+	HTMLBody     string
+	Attachments  []Attachment
+	// ManagementCode is written as the ManagementCodeHeader header
+	ManagementCode string
+}
@@ -5,13 +5,23 @@ import "time"
 type ParsedMail struct {
 	S3Key       string       `json:"s3_key"`
 	MessageID   string       `json:"message_id"`
+	InReplyTo   string       `json:"in_reply_to,omitempty"`
+	References  []string     `json:"references,omitempty"`
 	From        string       `json:"from"`
 	To          string       `json:"to"`
+	Cc          string       `json:"cc,omitempty"`
 	Subject     string       `json:"subject"`
 	Body        string       `json:"body"`
 	HTMLBody    string       `json:"html_body,omitempty"`
 	Date        time.Time    `json:"date"`
 	Attachments []Attachment `json:"attachments,omitempty"`
+	// Used for thread linking only
+	ManagementCode string   `json:"-"`
+	DeliveredTo    []string `json:"-"`
+	// Summary fields served from DB on list endpoints
+	Snippet         string `json:"snippet,omitempty"`
+	AttachmentCount int    `json:"attachment_count,omitempty"`
+	AttachmentSize  int64  `json:"attachment_size,omitempty"`
 	// State from DB
 	IsRead    bool    `json:"is_read"`
 	IsStarred bool    `json:"is_starred"`
@@ -24,3 +34,10 @@ type Attachment struct {
 	Size        int    `json:"size"`
 	Content     []byte `json:"-"`
 }
+
+// AttachmentRef points at attachments of a received mail; an empty Indexes
+// re-attaches all of them. Used when forwarding.
+type AttachmentRef struct {
+	S3Key   string `json:"s3_key"`
+	Indexes []int  `json:"indexes,omitempty"`
+}
@@ -2,14 +2,21 @@ package entity
 
 import "time"
 
+const (
+	StorageTypeS3         = "s3"
+	StorageTypeFilesystem = "filesystem"
+)
+
 type S3Domain struct {
 	ID          string    `json:"id" gorm:"column:id;primaryKey"`
 	Name        string    `json:"name" gorm:"column:name"`
-	Bucket      string    `json:"bucket" gorm:"column:bucket"`
+	StorageType string    `json:"storage_type" gorm:"column:storage_type;default:s3"`
+	Bucket      string    `json:"bucket" gorm:"column:bucket"` // directory under LOCAL_STORAGE_ROOT for filesystem storage
 	Region      string    `json:"region" gorm:"column:region"`
 	AccessKeyID string    `json:"access_key_id" gorm:"column:access_key_id"`
 	SecretKey   string    `json:"secret_key" gorm:"column:secret_key"`
 	Endpoint    string    `json:"endpoint" gorm:"column:endpoint"`
+	InsecureTLS bool      `json:"insecure_tls" gorm:"column:insecure_tls;default:false"`
 	CreatedAt   time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
 	UpdatedAt   time.Time `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
 }
@@ -0,0 +1,45 @@
+package entity
+
+import "time"
+
+// SenderIdentity is an address mail may be sent from. Only configured
+// identities can be used, and only by AllowedUIDs.
+type SenderIdentity struct {
+	ID      string `json:"id" gorm:"column:id;primaryKey"`
+	Address string `json:"address" gorm:"column:address;uniqueIndex"`
+	// DisplayName is written to the From header as "DisplayName <Address>".
+	DisplayName string `json:"display_name" gorm:"column:display_name"`
+	// SignatureText and SignatureHTML are appended to the text and HTML bodies,
+	// above the management code.
+	SignatureText string `json:"signature_text" gorm:"column:signature_text;type:text"`
+	SignatureHTML string `json:"signature_html" gorm:"column:signature_html;type:text"`
+	// AllowedUIDs lists the Firebase users who may send from the address; an
+	// empty list allows every user.
+	AllowedUIDs []string `json:"allowed_uids" gorm:"column:allowed_uids;type:text;serializer:json"`
+	// OmitCodeFromBody stops the 【管理コード】 line from being appended to the body;
+	// the code is then only carried in headers and the plus-addressed Reply-To.
+	OmitCodeFromBody bool `json:"omit_code_from_body" gorm:"column:omit_code_from_body;default:false"`
+	// ReplyDomain is a receiving domain; when set, Reply-To becomes reply+<code>@ReplyDomain.
+	ReplyDomain string `json:"reply_domain" gorm:"column:reply_domain"`
+	// ReturnPath is the SMTP MAIL FROM (bounce address) used when relaying
+	// over SMTP; empty uses Address. SES uses the identity's MAIL FROM domain.
+	ReturnPath string    `json:"return_path" gorm:"column:return_path"`
+	CreatedAt  time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt  time.Time `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (SenderIdentity) TableName() string {
+	return "sender_identities"
+}
+
+func (i *SenderIdentity) AllowsUser(uid string) bool {
+	if len(i.AllowedUIDs) == 0 {
+		return true
+	}
+	for _, allowed := range i.AllowedUIDs {
+		if allowed == uid {
+			return true
+		}
+	}
+	return false
+}
@@ -1,16 +1,64 @@
 package entity
 
-import "time"
+import (
+	"strings"
+	"time"
+)
+
+// Delivery states reported by SES notifications. Hard bounces and complaints
+// also put the recipient on the suppression list.
+const (
+	DeliveryStatusSent        = "sent"
+	DeliveryStatusDelivered   = "delivered"
+	DeliveryStatusSoftBounced = "soft_bounced"
+	DeliveryStatusBounced     = "bounced"
+	DeliveryStatusComplained  = "complained"
+)
+
+const (
+	RecipientTypeTo  = "to"
+	RecipientTypeCc  = "cc"
+	RecipientTypeBcc = "bcc"
+)
 
 type SentMail struct {
 	ManagementCode string    `json:"management_code" gorm:"column:management_code;primaryKey"`
 	ParentThreadID string    `json:"parent_thread_id" gorm:"column:parent_thread_id"`
 	RecipientEmail string    `json:"recipient_email" gorm:"column:recipient_email"`
+	MessageID      string    `json:"message_id" gorm:"column:message_id;index"`
+	RecipientType  string    `json:"recipient_type" gorm:"column:recipient_type;default:to"`
+	ToAddresses    string    `json:"to_addresses" gorm:"column:to_addresses"`
+	CcAddresses    string    `json:"cc_addresses,omitempty" gorm:"column:cc_addresses"`
+	ReplyTo        string    `json:"reply_to,omitempty" gorm:"column:reply_to"`
 	Subject        string    `json:"subject" gorm:"column:subject"`
 	Body           string    `json:"body" gorm:"column:body;type:text"`
 	SentAt         time.Time `json:"sent_at" gorm:"column:sent_at;autoCreateTime"`
+	// ParentManagementCode is the code a reply was sent in answer to.
+	ParentManagementCode string `json:"parent_management_code,omitempty" gorm:"column:parent_management_code;index"`
+	// ProviderMessageID is the ID SES assigned; notifications refer to it.
+	ProviderMessageID string `json:"provider_message_id,omitempty" gorm:"column:provider_message_id;index"`
+	// SESMessageID is the Message-ID header SES wrote in place of MessageID;
+	// replies refer to it.
+	SESMessageID      string     `json:"ses_message_id,omitempty" gorm:"column:ses_message_id;index"`
+	DeliveryStatus    string     `json:"delivery_status" gorm:"column:delivery_status;default:sent"`
+	DeliveryDetail    string     `json:"delivery_detail,omitempty" gorm:"column:delivery_detail"`
+	DeliveryUpdatedAt *time.Time `json:"delivery_updated_at,omitempty" gorm:"column:delivery_updated_at"`
 }
 
 func (SentMail) TableName() string {
 	return "sent_mails"
 }
+
+// SESMessageID returns the Message-ID header SES gives a message it sent:
+// the SES message ID at <region>.amazonses.com, or email.amazonses.com in
+// us-east-1.
+func SESMessageID(providerMessageID, region string) string {
+	if providerMessageID == "" || region == "" {
+		return ""
+	}
+	host := strings.ToLower(region) + ".amazonses.com"
+	if host == "us-east-1.amazonses.com" {
+		host = "email.amazonses.com"
+	}
+	return "<" + providerMessageID + "@" + host + ">"
+}
@@ -0,0 +1,23 @@
+package entity
+
+import "time"
+
+const (
+	SuppressionReasonBounce    = "bounce"
+	SuppressionReasonComplaint = "complaint"
+)
+
+// SuppressedAddress is a recipient that hard-bounced or complained. Mail to it
+// is refused until an admin removes the entry. Email is stored lowercased.
+type SuppressedAddress struct {
+	Email          string    `json:"email" gorm:"column:email;primaryKey"`
+	Reason         string    `json:"reason" gorm:"column:reason"`
+	Detail         string    `json:"detail,omitempty" gorm:"column:detail"`
+	ManagementCode string    `json:"management_code,omitempty" gorm:"column:management_code"`
+	CreatedAt      time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt      time.Time `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (SuppressedAddress) TableName() string {
+	return "suppressed_addresses"
+}
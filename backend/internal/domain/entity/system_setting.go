@@ -2,11 +2,38 @@ package entity
 
 import "time"
 
+const (
+	MailTransportSES  = "ses"
+	MailTransportSMTP = "smtp"
+)
+
+const (
+	SMTPSecurityStartTLS = "starttls"
+	SMTPSecurityTLS      = "tls" // implicit TLS, usually port 465
+	SMTPSecurityNone     = "none"
+)
+
+const (
+	SMTPAuthNone  = ""
+	SMTPAuthPlain = "plain"
+	SMTPAuthLogin = "login"
+)
+
 type SystemSetting struct {
-	ID             string    `json:"id" gorm:"column:id;primaryKey"`
-	SESRegion      string    `json:"ses_region" gorm:"column:ses_region"`
-	SESAccessKeyID string    `json:"ses_access_key_id" gorm:"column:ses_access_key_id"`
-	SESSecretKey   string    `json:"ses_secret_key" gorm:"column:ses_secret_key"`
-	CreatedAt      time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
-	UpdatedAt      time.Time `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+	ID             string `json:"id" gorm:"column:id;primaryKey"`
+	SESRegion      string `json:"ses_region" gorm:"column:ses_region"`
+	SESAccessKeyID string `json:"ses_access_key_id" gorm:"column:ses_access_key_id"`
+	SESSecretKey   string `json:"ses_secret_key" gorm:"column:ses_secret_key"`
+	// MailTransport selects the sender; with SMTPFallback, mail that SES
+	// rejects as throttled or unavailable is relayed over SMTP instead.
+	MailTransport string    `json:"mail_transport" gorm:"column:mail_transport;default:ses"`
+	SMTPFallback  bool      `json:"smtp_fallback" gorm:"column:smtp_fallback;default:false"`
+	SMTPHost      string    `json:"smtp_host" gorm:"column:smtp_host"`
+	SMTPPort      int       `json:"smtp_port" gorm:"column:smtp_port"`
+	SMTPSecurity  string    `json:"smtp_security" gorm:"column:smtp_security;default:starttls"`
+	SMTPAuth      string    `json:"smtp_auth" gorm:"column:smtp_auth"`
+	SMTPUsername  string    `json:"smtp_username" gorm:"column:smtp_username"`
+	SMTPPassword  string    `json:"smtp_password" gorm:"column:smtp_password"`
+	CreatedAt     time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt     time.Time `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
 }
@@ -0,0 +1,53 @@
+package entity
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/rikut0904/mailer-backend/pkg/cron"
+)
+
+// UnreadDigest mails a summary of the unread mail of the user's domain to
+// ToAddress on Schedule, a cron expression evaluated in TimeZone.
+type UnreadDigest struct {
+	Enabled   bool   `json:"enabled"`
+	Schedule  string `json:"schedule"`
+	TimeZone  string `json:"time_zone"`
+	ToAddress string `json:"to_address"`
+}
+
+// Validate checks the schedule, time zone and address of an enabled digest.
+func (d *UnreadDigest) Validate() error {
+	if !d.Enabled {
+		return nil
+	}
+	if _, err := cron.Parse(d.Schedule); err != nil {
+		return fmt.Errorf("invalid unread digest schedule: %q", d.Schedule)
+	}
+	if _, err := time.LoadLocation(d.TimeZone); err != nil {
+		return fmt.Errorf("invalid time zone: %q", d.TimeZone)
+	}
+	if _, err := mail.ParseAddress(d.ToAddress); err != nil || strings.TrimSpace(d.ToAddress) == "" {
+		return fmt.Errorf("invalid unread digest address: %q", d.ToAddress)
+	}
+	return nil
+}
+
+// Next returns the first scheduled time after t, or the zero time when the
+// digest is disabled or its settings are invalid.
+func (d *UnreadDigest) Next(t time.Time) time.Time {
+	if d == nil || !d.Enabled {
+		return time.Time{}
+	}
+	schedule, err := cron.Parse(d.Schedule)
+	if err != nil {
+		return time.Time{}
+	}
+	location, err := time.LoadLocation(d.TimeZone)
+	if err != nil {
+		return time.Time{}
+	}
+	return schedule.Next(t.In(location))
+}
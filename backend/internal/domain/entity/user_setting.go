@@ -3,9 +3,21 @@ package entity
 import "time"
 
 type UserSetting struct {
-	UID               string    `json:"uid" gorm:"column:uid;primaryKey"`
-	DiscordWebhookURL string    `json:"discord_webhook_url" gorm:"column:discord_webhook_url"`
-	SelectedDomainID  string    `json:"selected_domain_id" gorm:"column:selected_domain_id"`
-	CreatedAt         time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
-	UpdatedAt         time.Time `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+	UID string `json:"uid" gorm:"column:uid;primaryKey"`
+	// NotificationChannels receive new mail of the user's domain, optionally
+	// limited to one recipient address each.
+	NotificationChannels []NotificationChannel `json:"notification_channels" gorm:"column:notification_channels;type:text;serializer:json"`
+	// NotificationRules are evaluated for every mail; each match notifies its channel
+	NotificationRules []NotificationRule `json:"notification_rules" gorm:"column:notification_rules;type:text;serializer:json"`
+	QuietHours        QuietHours         `json:"quiet_hours" gorm:"column:quiet_hours;type:text;serializer:json"`
+	UnreadDigest      UnreadDigest       `json:"unread_digest" gorm:"column:unread_digest;type:text;serializer:json"`
+	// UnreadDigestEnabled mirrors UnreadDigest.Enabled so enabled digests can
+	// be queried; the repository keeps it in sync.
+	UnreadDigestEnabled bool `json:"-" gorm:"column:unread_digest_enabled;index"`
+	// UnreadDigestSentAt is when the digest schedule last fired; it is not
+	// changed by settings updates.
+	UnreadDigestSentAt *time.Time `json:"unread_digest_sent_at" gorm:"column:unread_digest_sent_at"`
+	SelectedDomainID   string     `json:"selected_domain_id" gorm:"column:selected_domain_id"`
+	CreatedAt          time.Time  `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt          time.Time  `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
 }
@@ -0,0 +1,82 @@
+package entity
+
+import "time"
+
+// Webhook events delivered to subscriptions.
+const (
+	WebhookEventMailReceived = "mail.received"
+	WebhookEventMailSent     = "mail.sent"
+	WebhookEventMailBounced  = "mail.bounced"
+	WebhookEventThreadLinked = "thread.linked"
+	WebhookEventMailDeleted  = "mail.deleted"
+)
+
+// WebhookEvents lists every event a subscription can filter on.
+var WebhookEvents = []string{
+	WebhookEventMailReceived,
+	WebhookEventMailSent,
+	WebhookEventMailBounced,
+	WebhookEventThreadLinked,
+	WebhookEventMailDeleted,
+}
+
+const (
+	WebhookDeliveryStatusPending   = "pending"
+	WebhookDeliveryStatusSending   = "sending"
+	WebhookDeliveryStatusSucceeded = "succeeded"
+	WebhookDeliveryStatusFailed    = "failed"
+)
+
+// WebhookSubscription receives the events in Events as JSON POSTs to URL,
+// signed with HMAC-SHA256 using Secret. An empty Events receives every event.
+type WebhookSubscription struct {
+	ID          string    `json:"id" gorm:"column:id;primaryKey"`
+	URL         string    `json:"url" gorm:"column:url"`
+	Events      []string  `json:"events" gorm:"column:events;type:text;serializer:json"`
+	Secret      string    `json:"secret,omitempty" gorm:"column:secret"`
+	Description string    `json:"description" gorm:"column:description"`
+	Enabled     bool      `json:"enabled" gorm:"column:enabled"`
+	CreatedAt   time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (WebhookSubscription) TableName() string {
+	return "webhook_subscriptions"
+}
+
+// Subscribes reports whether the subscription wants event.
+func (s *WebhookSubscription) Subscribes(event string) bool {
+	if !s.Enabled {
+		return false
+	}
+	if len(s.Events) == 0 {
+		return true
+	}
+	for _, e := range s.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is one event sent to one subscription, kept as the
+// delivery log. Payload is the exact signed request body.
+type WebhookDelivery struct {
+	ID             string     `json:"id" gorm:"column:id;primaryKey"`
+	SubscriptionID string     `json:"subscription_id" gorm:"column:subscription_id;index"`
+	Event          string     `json:"event" gorm:"column:event"`
+	Payload        string     `json:"payload" gorm:"column:payload;type:text"`
+	Status         string     `json:"status" gorm:"column:status;index"`
+	Attempts       int        `json:"attempts" gorm:"column:attempts;default:0"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at" gorm:"column:next_attempt_at;index"`
+	ResponseStatus int        `json:"response_status,omitempty" gorm:"column:response_status"`
+	LastError      string     `json:"last_error,omitempty" gorm:"column:last_error"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty" gorm:"column:delivered_at"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"column:created_at;autoCreateTime;index"`
+	UpdatedAt      time.Time  `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
@@ -0,0 +1,9 @@
+package repository
+
+import "github.com/rikut0904/mailer-backend/internal/domain/entity"
+
+type DomainSyncStateRepository interface {
+	GetByDomainID(domainID string) (*entity.DomainSyncState, error)
+	List() ([]entity.DomainSyncState, error)
+	Save(state *entity.DomainSyncState) error
+}
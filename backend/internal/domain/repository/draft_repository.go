@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/rikut0904/mailer-backend/internal/domain/entity"
+)
+
+// DraftRepository loads attachment metadata with the draft; file contents are
+// only read through ListAttachmentContents.
+type DraftRepository interface {
+	ListByUID(uid string) ([]entity.Draft, error)
+	GetByID(uid, id string) (*entity.Draft, error)
+	Create(draft *entity.Draft) error
+	Update(draft *entity.Draft) error
+	Delete(uid, id string) error
+	// MarkSending moves an editing draft, or one left sending since
+	// staleBefore, to sending; false means another send holds it.
+	MarkSending(uid, id string, staleBefore time.Time) (bool, error)
+	MarkEditing(uid, id string) error
+	AddAttachments(attachments []entity.DraftAttachment) error
+	DeleteAttachment(draftID, attachmentID string) (bool, error)
+	ListAttachmentContents(draftID string) ([]entity.DraftAttachment, error)
+}
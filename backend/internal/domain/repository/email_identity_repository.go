@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/rikut0904/mailer-backend/internal/domain/entity"
+)
+
+var (
+	ErrEmailIdentityNotFound = errors.New("email identity not found")
+	ErrSESNotConfigured      = errors.New("SES settings are not configured")
+	// ErrEmailIdentityUnavailable wraps lookups that failed for a passing
+	// reason, such as throttling or a network error.
+	ErrEmailIdentityUnavailable = errors.New("email identity lookup is temporarily unavailable")
+)
+
+// EmailIdentityRepository reads identity verification from SES.
+type EmailIdentityRepository interface {
+	// ListEmailIdentities returns every identity with its verification status;
+	// DKIM and MAIL FROM details are only filled in by GetEmailIdentity.
+	ListEmailIdentities() ([]entity.EmailIdentityStatus, error)
+	// GetEmailIdentity returns ErrEmailIdentityNotFound for unknown identities.
+	GetEmailIdentity(identity string) (*entity.EmailIdentityStatus, error)
+}
@@ -0,0 +1,12 @@
+package repository
+
+import "github.com/rikut0904/mailer-backend/internal/domain/entity"
+
+type HeldNotificationRepository interface {
+	Create(notifications []entity.HeldNotification) error
+	// ListUIDs returns the users that have held notifications.
+	ListUIDs() ([]string, error)
+	// ListByUID returns a user's held notifications, oldest first.
+	ListByUID(uid string) ([]entity.HeldNotification, error)
+	DeleteByIDs(ids []string) error
+}
@@ -1,5 +1,24 @@
 package repository
 
+import (
+	"errors"
+
+	"github.com/rikut0904/mailer-backend/internal/domain/entity"
+	mimeparser "github.com/rikut0904/mailer-backend/pkg/mime"
+)
+
+// MaxRawMessageSize is the SES v2 limit for a raw message, after encoding.
+// The composer enforces it, so the domain takes its value and error.
+const MaxRawMessageSize = mimeparser.MaxRawMessageSize
+
+var (
+	ErrMessageTooLarge = mimeparser.ErrMessageTooLarge
+	// ErrTemporarySendFailure wraps errors worth retrying, such as throttling.
+	ErrTemporarySendFailure = errors.New("temporary send failure")
+)
+
+// MailSenderRepository returns the provider's message ID (the SES MessageId),
+// which delivery notifications refer to.
 type MailSenderRepository interface {
-	SendRawEmail(from, to, subject, textBody, htmlBody string) error
+	SendRawEmail(mail *entity.OutgoingMail) (string, error)
 }
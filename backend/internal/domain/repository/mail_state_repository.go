@@ -6,10 +6,15 @@ type MailStateRepository interface {
 	FindByS3Key(domainID, s3Key string) (*entity.MailState, error)
 	FindByRecipient(domainID, recipientAddress string, offset, limit int) ([]entity.MailState, int64, error)
 	FindByThreadID(domainID, threadID string) ([]entity.MailState, error)
+	FindByMessageIDs(domainID string, messageIDs []string) ([]entity.MailState, error)
 	Upsert(state *entity.MailState) error
+	UpdateMetadata(state *entity.MailState) error
+	ListRecipients(domainID string) ([]string, error)
 	UpdateReadStatus(domainID, s3Key string, isRead bool) error
 	UpdateStarStatus(domainID, s3Key string, isStarred bool) error
 	UpdateThreadID(domainID, s3Key string, threadID string) error
 	Delete(domainID, s3Key string) error
 	CountUnread(domainID, recipientAddress string) (int64, error)
+	// FindUnread returns up to limit unread mails, newest first.
+	FindUnread(domainID, recipientAddress string, limit int) ([]entity.MailState, error)
 }
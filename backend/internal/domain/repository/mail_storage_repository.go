@@ -1,7 +1,23 @@
 package repository
 
+import (
+	"errors"
+	"io"
+
+	"github.com/rikut0904/mailer-backend/internal/domain/entity"
+)
+
+// ErrObjectNotFound is returned (wrapped) for keys the storage does not hold.
+var ErrObjectNotFound = errors.New("object not found")
+
 type MailStorageRepository interface {
 	ListKeys(prefix string, continuationToken *string, maxKeys int) (keys []string, nextToken *string, err error)
+	ListKeysAfter(prefix string, startAfter string, maxKeys int) (keys []string, err error)
 	GetObject(key string) ([]byte, error)
+	// OpenObject streams an object; seeking lets large downloads serve ranges
+	// without loading the whole object.
+	OpenObject(key string) (io.ReadSeekCloser, error)
 	DeleteObject(key string) error
 }
+
+type MailStorageFactory func(domain *entity.S3Domain) (MailStorageRepository, error)
@@ -0,0 +1,11 @@
+package repository
+
+import "github.com/rikut0904/mailer-backend/internal/domain/entity"
+
+type MailTemplateRepository interface {
+	ListByUID(uid string) ([]entity.MailTemplate, error)
+	GetByID(uid, id string) (*entity.MailTemplate, error)
+	Create(template *entity.MailTemplate) error
+	Update(template *entity.MailTemplate) error
+	Delete(uid, id string) error
+}
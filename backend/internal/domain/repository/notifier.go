@@ -0,0 +1,10 @@
+package repository
+
+import "github.com/rikut0904/mailer-backend/internal/domain/entity"
+
+// Notifier delivers new mail notifications to one kind of chat service. One
+// implementation exists per entity.NotificationChannel type; it splits
+// notifications that exceed the service's message limits.
+type Notifier interface {
+	Notify(webhookURL string, notification *entity.MailNotification) error
+}
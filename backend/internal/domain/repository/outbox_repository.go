@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/rikut0904/mailer-backend/internal/domain/entity"
+)
+
+type OutboxRepository interface {
+	CreateBatch(batch *entity.OutboxBatch) error
+	// CreateMergeBatch stores a merge batch with its rows in one transaction.
+	CreateMergeBatch(batch *entity.OutboxBatch, rows []entity.OutboxMergeRow) error
+	// ListMergeRecipients returns the rows of a merge batch in order, without
+	// their content.
+	ListMergeRecipients(batchID string) ([]entity.OutboxMergeRow, error)
+	GetMergeRow(batchID string, rowNumber int) (*entity.OutboxMergeRow, error)
+	GetBatch(id string) (*entity.OutboxBatch, error)
+	ListBatchesByUID(uid, status string) ([]entity.OutboxBatch, error)
+	ListDueBatches(now time.Time, limit int) ([]entity.OutboxBatch, error)
+	// UpdateScheduledBatch and CancelBatch only apply while the batch is still
+	// scheduled and report whether it was.
+	UpdateScheduledBatch(batch *entity.OutboxBatch) (bool, error)
+	CancelBatch(id string) (bool, error)
+	// ReleaseBatch marks a scheduled batch as released and stores its messages
+	// in one transaction. It reports false if the batch was no longer scheduled.
+	ReleaseBatch(batchID string, messages []entity.OutboxMessage) (bool, error)
+	ListMessages(batchID string) ([]entity.OutboxMessage, error)
+	// ClaimDue moves up to limit queued messages whose NextAttemptAt has passed
+	// to the sending status under a new claim token and returns them. A
+	// message is only claimed once.
+	ClaimDue(now time.Time, limit int) ([]entity.OutboxMessage, error)
+	// RequeueStale returns messages stuck in sending since before the given time to the queue.
+	RequeueStale(before time.Time) (int64, error)
+	// RenewClaim refreshes the claim right before a message is sent. It
+	// reports false if the message was requeued and claimed again meanwhile.
+	RenewClaim(message *entity.OutboxMessage, now time.Time) (bool, error)
+	// FinishMessage stores the outcome of a delivery attempt, and sent when it
+	// is not nil, in one transaction. It reports false, storing nothing, if
+	// the message no longer holds its claim.
+	FinishMessage(message *entity.OutboxMessage, sent *entity.SentMail) (bool, error)
+}
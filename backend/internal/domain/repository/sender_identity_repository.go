@@ -0,0 +1,12 @@
+package repository
+
+import "github.com/rikut0904/mailer-backend/internal/domain/entity"
+
+type SenderIdentityRepository interface {
+	List() ([]entity.SenderIdentity, error)
+	GetByID(id string) (*entity.SenderIdentity, error)
+	FindByAddress(address string) (*entity.SenderIdentity, error)
+	Create(identity *entity.SenderIdentity) error
+	Update(identity *entity.SenderIdentity) error
+	Delete(id string) error
+}
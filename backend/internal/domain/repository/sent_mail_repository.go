@@ -1,10 +1,18 @@
 package repository
 
-import "github.com/rikut0904/mailer-backend/internal/domain/entity"
+import (
+	"time"
+
+	"github.com/rikut0904/mailer-backend/internal/domain/entity"
+)
 
 type SentMailRepository interface {
 	FindByManagementCode(code string) (*entity.SentMail, error)
 	FindByParentThreadID(threadID string) ([]entity.SentMail, error)
+	FindByMessageIDs(messageIDs []string) ([]entity.SentMail, error)
+	FindByRecipientEmail(email string) ([]entity.SentMail, error)
+	FindByProviderMessageID(providerMessageID string) ([]entity.SentMail, error)
+	UpdateDeliveryStatus(managementCode, status, detail string, at time.Time) error
 	Create(sentMail *entity.SentMail) error
 	Delete(managementCode string) error
 }
@@ -0,0 +1,11 @@
+package repository
+
+import "github.com/rikut0904/mailer-backend/internal/domain/entity"
+
+type SuppressionRepository interface {
+	List() ([]entity.SuppressedAddress, error)
+	// FindByEmails matches case-insensitively.
+	FindByEmails(emails []string) ([]entity.SuppressedAddress, error)
+	Upsert(address *entity.SuppressedAddress) error
+	Delete(email string) error
+}
@@ -1,8 +1,21 @@
 package repository
 
-import "github.com/rikut0904/mailer-backend/internal/domain/entity"
+import (
+	"time"
+
+	"github.com/rikut0904/mailer-backend/internal/domain/entity"
+)
 
 type UserSettingRepository interface {
 	GetByUID(uid string) (*entity.UserSetting, error)
 	Upsert(setting *entity.UserSetting) error
+	// ListWithNotificationChannels returns the settings that have at least one
+	// notification channel.
+	ListWithNotificationChannels() ([]entity.UserSetting, error)
+	// ListWithUnreadDigest returns the settings that may have an enabled
+	// unread digest.
+	ListWithUnreadDigest() ([]entity.UserSetting, error)
+	// ClaimUnreadDigest sets UnreadDigestSentAt to sentAt if it still equals
+	// previous (nil for never) and reports whether this call changed it.
+	ClaimUnreadDigest(uid string, previous *time.Time, sentAt time.Time) (bool, error)
 }
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/rikut0904/mailer-backend/internal/domain/entity"
+)
+
+type WebhookRepository interface {
+	ListSubscriptions() ([]entity.WebhookSubscription, error)
+	GetSubscription(id string) (*entity.WebhookSubscription, error)
+	CreateSubscription(subscription *entity.WebhookSubscription) error
+	UpdateSubscription(subscription *entity.WebhookSubscription) error
+	// DeleteSubscription also deletes its delivery log.
+	DeleteSubscription(id string) error
+
+	CreateDeliveries(deliveries []entity.WebhookDelivery) error
+	// ListDeliveries returns the newest deliveries of a subscription first.
+	ListDeliveries(subscriptionID string, limit int) ([]entity.WebhookDelivery, error)
+	// ClaimDueDeliveries moves up to limit pending deliveries whose
+	// NextAttemptAt has passed to sending and returns them.
+	ClaimDueDeliveries(now time.Time, limit int) ([]entity.WebhookDelivery, error)
+	// RequeueStaleDeliveries returns deliveries stuck in sending since before
+	// the given time to pending.
+	RequeueStaleDeliveries(before time.Time) (int64, error)
+	UpdateDelivery(delivery *entity.WebhookDelivery) error
+}
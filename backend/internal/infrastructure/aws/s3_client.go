@@ -2,41 +2,87 @@ package aws
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
 	"io"
+	"net/http"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
 	"github.com/rikut0904/mailer-backend/internal/domain/entity"
 	"github.com/rikut0904/mailer-backend/internal/domain/repository"
 )
 
+const defaultCompatibleRegion = "us-east-1"
+
 type s3Client struct {
 	client     *s3.Client
 	bucketName string
 }
 
 func NewS3ClientFromDomain(domain *entity.S3Domain) (repository.MailStorageRepository, error) {
-	awsCfg, err := awsconfig.LoadDefaultConfig(context.TODO(),
-		awsconfig.WithRegion(domain.Region),
+	region := domain.Region
+	if region == "" && domain.Endpoint != "" {
+		region = defaultCompatibleRegion
+	}
+
+	opts := []func(*awsconfig.LoadOptions) error{
+		awsconfig.WithRegion(region),
 		awsconfig.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(
 			domain.AccessKeyID,
 			domain.SecretKey,
 			"",
 		)),
-	)
+	}
+	if domain.InsecureTLS {
+		opts = append(opts, awsconfig.WithHTTPClient(awshttp.NewBuildableClient().WithTransportOptions(func(tr *http.Transport) {
+			if tr.TLSClientConfig == nil {
+				tr.TLSClientConfig = &tls.Config{}
+			}
+			tr.TLSClientConfig.InsecureSkipVerify = true
+		})))
+	}
+
+	awsCfg, err := awsconfig.LoadDefaultConfig(context.TODO(), opts...)
 	if err != nil {
 		return nil, err
 	}
 
-	client := s3.NewFromConfig(awsCfg)
+	endpoint := normalizeEndpoint(domain.Endpoint)
+	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		if endpoint == "" {
+			return
+		}
+		// S3-compatible stores (MinIO etc.) generally need path-style
+		// addressing and do not support the newer default checksums.
+		o.BaseEndpoint = aws.String(endpoint)
+		o.UsePathStyle = true
+		o.RequestChecksumCalculation = aws.RequestChecksumCalculationWhenRequired
+		o.ResponseChecksumValidation = aws.ResponseChecksumValidationWhenRequired
+	})
 	return &s3Client{
 		client:     client,
 		bucketName: domain.Bucket,
 	}, nil
 }
 
+func normalizeEndpoint(endpoint string) string {
+	endpoint = strings.TrimRight(strings.TrimSpace(endpoint), "/")
+	if endpoint == "" {
+		return ""
+	}
+	if !strings.HasPrefix(endpoint, "http://") && !strings.HasPrefix(endpoint, "https://") {
+		endpoint = "https://" + endpoint
+	}
+	return endpoint
+}
+
 func (s *s3Client) ListKeys(prefix string, continuationToken *string, maxKeys int) ([]string, *string, error) {
 	input := &s3.ListObjectsV2Input{
 		Bucket:  aws.String(s.bucketName),
@@ -67,19 +113,125 @@ func (s *s3Client) ListKeys(prefix string, continuationToken *string, maxKeys in
 	return keys, nextToken, nil
 }
 
+func (s *s3Client) ListKeysAfter(prefix string, startAfter string, maxKeys int) ([]string, error) {
+	input := &s3.ListObjectsV2Input{
+		Bucket:  aws.String(s.bucketName),
+		MaxKeys: aws.Int32(int32(maxKeys)),
+	}
+	if prefix != "" {
+		input.Prefix = aws.String(prefix)
+	}
+	if startAfter != "" {
+		input.StartAfter = aws.String(startAfter)
+	}
+
+	result, err := s.client.ListObjectsV2(context.TODO(), input)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(result.Contents))
+	for _, obj := range result.Contents {
+		keys = append(keys, *obj.Key)
+	}
+	return keys, nil
+}
+
 func (s *s3Client) GetObject(key string) ([]byte, error) {
 	result, err := s.client.GetObject(context.TODO(), &s3.GetObjectInput{
 		Bucket: aws.String(s.bucketName),
 		Key:    aws.String(key),
 	})
 	if err != nil {
-		return nil, err
+		return nil, s3Error(key, err)
 	}
 	defer result.Body.Close()
 
 	return io.ReadAll(result.Body)
 }
 
+func (s *s3Client) OpenObject(key string) (io.ReadSeekCloser, error) {
+	head, err := s.client.HeadObject(context.TODO(), &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, s3Error(key, err)
+	}
+	return &s3ObjectReader{client: s, key: key, size: aws.ToInt64(head.ContentLength)}, nil
+}
+
+// s3ObjectReader reads an object with ranged GetObject requests, starting a
+// new one after each seek.
+type s3ObjectReader struct {
+	client *s3Client
+	key    string
+	size   int64
+	offset int64
+	body   io.ReadCloser
+}
+
+func (r *s3ObjectReader) Read(p []byte) (int, error) {
+	if r.offset >= r.size {
+		return 0, io.EOF
+	}
+	if r.body == nil {
+		result, err := r.client.client.GetObject(context.TODO(), &s3.GetObjectInput{
+			Bucket: aws.String(r.client.bucketName),
+			Key:    aws.String(r.key),
+			Range:  aws.String(fmt.Sprintf("bytes=%d-", r.offset)),
+		})
+		if err != nil {
+			return 0, s3Error(r.key, err)
+		}
+		r.body = result.Body
+	}
+	n, err := r.body.Read(p)
+	r.offset += int64(n)
+	if err == io.EOF && r.offset < r.size {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+func (r *s3ObjectReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		offset += r.size
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("negative position")
+	}
+	if offset != r.offset {
+		r.closeBody()
+		r.offset = offset
+	}
+	return offset, nil
+}
+
+func (r *s3ObjectReader) Close() error {
+	r.closeBody()
+	return nil
+}
+
+func (r *s3ObjectReader) closeBody() {
+	if r.body != nil {
+		r.body.Close()
+		r.body = nil
+	}
+}
+
+// s3Error marks missing keys with repository.ErrObjectNotFound.
+func s3Error(key string, err error) error {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && (apiErr.ErrorCode() == "NoSuchKey" || apiErr.ErrorCode() == "NotFound") {
+		return fmt.Errorf("%w: %s", repository.ErrObjectNotFound, key)
+	}
+	return err
+}
+
 func (s *s3Client) DeleteObject(key string) error {
 	_, err := s.client.DeleteObject(context.TODO(), &s3.DeleteObjectInput{
 		Bucket: aws.String(s.bucketName),
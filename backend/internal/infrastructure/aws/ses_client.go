@@ -2,17 +2,22 @@ package aws
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"mime"
-	"net/mail"
+	"net"
+	"net/http"
 	"strings"
-	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/sesv2"
 	"github.com/aws/aws-sdk-go-v2/service/sesv2/types"
+	"github.com/aws/smithy-go"
+	"github.com/rikut0904/mailer-backend/internal/domain/entity"
 	"github.com/rikut0904/mailer-backend/internal/domain/repository"
+	mimeparser "github.com/rikut0904/mailer-backend/pkg/mime"
 )
 
 type sesClient struct {
@@ -23,17 +28,50 @@ func NewSESClient(settingsRepo repository.SystemSettingRepository) repository.Ma
 	return &sesClient{settingsRepo: settingsRepo}
 }
 
-func (s *sesClient) SendRawEmail(from, to, subject, textBody, htmlBody string) error {
-	if strings.TrimSpace(from) == "" {
-		return fmt.Errorf("from is required")
+func (s *sesClient) SendRawEmail(outgoing *entity.OutgoingMail) (string, error) {
+	if strings.TrimSpace(outgoing.From) == "" {
+		return "", fmt.Errorf("from is required")
 	}
+	if strings.TrimSpace(outgoing.EnvelopeTo) == "" {
+		return "", fmt.Errorf("recipient is required")
+	}
+
+	client, err := newSESv2Client(s.settingsRepo)
+	if err != nil {
+		return "", err
+	}
+
+	raw, err := mimeparser.BuildRawMessage(outgoing)
+	if err != nil {
+		return "", err
+	}
+
+	output, err := client.SendEmail(context.TODO(), &sesv2.SendEmailInput{
+		Destination: &types.Destination{
+			ToAddresses: []string{outgoing.EnvelopeTo},
+		},
+		Content: &types.EmailContent{
+			Raw: &types.RawMessage{
+				Data: raw,
+			},
+		},
+	})
+	if err != nil {
+		return "", classifySendError(err)
+	}
+	return aws.ToString(output.MessageId), nil
+}
 
-	settings, err := s.settingsRepo.Get()
+// newSESv2Client builds a client from the SES credentials in the system
+// settings. Like every SDK client it honours AWS_ENDPOINT_URL_SESV2, so a
+// local SES emulator can stand in for the real API.
+func newSESv2Client(settingsRepo repository.SystemSettingRepository) (*sesv2.Client, error) {
+	settings, err := settingsRepo.Get()
 	if err != nil {
-		return fmt.Errorf("failed to load SES settings: %w", err)
+		return nil, fmt.Errorf("failed to load SES settings: %w", err)
 	}
 	if settings.SESRegion == "" || settings.SESAccessKeyID == "" || settings.SESSecretKey == "" {
-		return fmt.Errorf("SES settings are not configured")
+		return nil, repository.ErrSESNotConfigured
 	}
 
 	awsCfg, err := awsconfig.LoadDefaultConfig(context.TODO(),
@@ -45,45 +83,38 @@ func (s *sesClient) SendRawEmail(from, to, subject, textBody, htmlBody string) e
 		)),
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	client := sesv2.NewFromConfig(awsCfg)
-
-	encodedSubject := mime.QEncoding.Encode("UTF-8", subject)
-
-	boundary := fmt.Sprintf("boundary_%d", time.Now().UnixNano())
-	var rawMsg strings.Builder
-
-	rawMsg.WriteString(fmt.Sprintf("From: %s\r\n", (&mail.Address{Address: from}).String()))
-	rawMsg.WriteString(fmt.Sprintf("To: %s\r\n", to))
-	rawMsg.WriteString(fmt.Sprintf("Subject: %s\r\n", encodedSubject))
-	rawMsg.WriteString("MIME-Version: 1.0\r\n")
-	rawMsg.WriteString(fmt.Sprintf("Content-Type: multipart/alternative; boundary=\"%s\"\r\n", boundary))
-	rawMsg.WriteString("\r\n")
-
-	rawMsg.WriteString(fmt.Sprintf("--%s\r\n", boundary))
-	rawMsg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
-	rawMsg.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
-	rawMsg.WriteString(textBody)
-	rawMsg.WriteString("\r\n")
+	return sesv2.NewFromConfig(awsCfg), nil
+}
 
-	if htmlBody != "" {
-		rawMsg.WriteString(fmt.Sprintf("--%s\r\n", boundary))
-		rawMsg.WriteString("Content-Type: text/html; charset=UTF-8\r\n")
-		rawMsg.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
-		rawMsg.WriteString(htmlBody)
-		rawMsg.WriteString("\r\n")
+// classifySendError marks throttling, server-side and network errors as
+// temporary so the outbox retries them; anything else is final.
+func classifySendError(err error) error {
+	if err == nil {
+		return nil
 	}
 
-	rawMsg.WriteString(fmt.Sprintf("--%s--\r\n", boundary))
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.ErrorCode() {
+		case "Throttling", "ThrottlingException", "TooManyRequestsException", "LimitExceededException",
+			"ServiceUnavailable", "ServiceUnavailableException", "InternalFailure", "InternalServiceError":
+			return fmt.Errorf("%w: %v", repository.ErrTemporarySendFailure, err)
+		}
+	}
 
-	_, err = client.SendEmail(context.TODO(), &sesv2.SendEmailInput{
-		Content: &types.EmailContent{
-			Raw: &types.RawMessage{
-				Data: []byte(rawMsg.String()),
-			},
-		},
-	})
+	var respErr *awshttp.ResponseError
+	if errors.As(err, &respErr) {
+		if code := respErr.HTTPStatusCode(); code == http.StatusTooManyRequests || code >= http.StatusInternalServerError {
+			return fmt.Errorf("%w: %v", repository.ErrTemporarySendFailure, err)
+		}
+		return err
+	}
 
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return fmt.Errorf("%w: %v", repository.ErrTemporarySendFailure, err)
+	}
 	return err
 }
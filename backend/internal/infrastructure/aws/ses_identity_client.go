@@ -0,0 +1,87 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2/types"
+	"github.com/rikut0904/mailer-backend/internal/domain/entity"
+	"github.com/rikut0904/mailer-backend/internal/domain/repository"
+)
+
+type sesIdentityClient struct {
+	settingsRepo repository.SystemSettingRepository
+}
+
+func NewSESIdentityClient(settingsRepo repository.SystemSettingRepository) repository.EmailIdentityRepository {
+	return &sesIdentityClient{settingsRepo: settingsRepo}
+}
+
+func (s *sesIdentityClient) ListEmailIdentities() ([]entity.EmailIdentityStatus, error) {
+	client, err := newSESv2Client(s.settingsRepo)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var identities []entity.EmailIdentityStatus
+	paginator := sesv2.NewListEmailIdentitiesPaginator(client, &sesv2.ListEmailIdentitiesInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, err
+		}
+		for _, info := range page.EmailIdentities {
+			identities = append(identities, entity.EmailIdentityStatus{
+				Identity:           aws.ToString(info.IdentityName),
+				Type:               string(info.IdentityType),
+				VerificationStatus: string(info.VerificationStatus),
+				VerifiedForSending: info.SendingEnabled && info.VerificationStatus == types.VerificationStatusSuccess,
+				CheckedAt:          now,
+			})
+		}
+	}
+	return identities, nil
+}
+
+func (s *sesIdentityClient) GetEmailIdentity(identity string) (*entity.EmailIdentityStatus, error) {
+	client, err := newSESv2Client(s.settingsRepo)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := client.GetEmailIdentity(context.TODO(), &sesv2.GetEmailIdentityInput{
+		EmailIdentity: aws.String(identity),
+	})
+	if err != nil {
+		var notFound *types.NotFoundException
+		if errors.As(err, &notFound) {
+			return nil, repository.ErrEmailIdentityNotFound
+		}
+		if errors.Is(classifySendError(err), repository.ErrTemporarySendFailure) {
+			return nil, fmt.Errorf("%w: %v", repository.ErrEmailIdentityUnavailable, err)
+		}
+		return nil, err
+	}
+
+	status := &entity.EmailIdentityStatus{
+		Identity:           identity,
+		Type:               string(output.IdentityType),
+		VerificationStatus: string(output.VerificationStatus),
+		VerifiedForSending: output.VerifiedForSendingStatus,
+		CheckedAt:          time.Now(),
+	}
+	if dkim := output.DkimAttributes; dkim != nil {
+		status.DKIMStatus = string(dkim.Status)
+		status.DKIMSigningEnabled = dkim.SigningEnabled
+	}
+	if mailFrom := output.MailFromAttributes; mailFrom != nil {
+		status.MailFromDomain = aws.ToString(mailFrom.MailFromDomain)
+		status.MailFromStatus = string(mailFrom.MailFromDomainStatus)
+	}
+	return status, nil
+}
@@ -0,0 +1,199 @@
+package aws
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+var snsHostRegex = regexp.MustCompile(`^sns\.[a-z0-9-]+\.amazonaws\.com(\.cn)?$`)
+
+type SNSMessage struct {
+	Type             string `json:"Type"`
+	MessageID        string `json:"MessageId"`
+	Token            string `json:"Token,omitempty"`
+	TopicArn         string `json:"TopicArn"`
+	Subject          string `json:"Subject,omitempty"`
+	Message          string `json:"Message"`
+	Timestamp        string `json:"Timestamp"`
+	SignatureVersion string `json:"SignatureVersion"`
+	Signature        string `json:"Signature"`
+	SigningCertURL   string `json:"SigningCertURL"`
+	SubscribeURL     string `json:"SubscribeURL,omitempty"`
+	UnsubscribeURL   string `json:"UnsubscribeURL,omitempty"`
+}
+
+func (m *SNSMessage) IsSubscriptionConfirmation() bool {
+	return m.Type == "SubscriptionConfirmation"
+}
+
+func (m *SNSMessage) IsNotification() bool {
+	return m.Type == "Notification"
+}
+
+type SNSVerifier struct {
+	httpClient *http.Client
+	mu         sync.Mutex
+	certs      map[string]*x509.Certificate
+}
+
+func NewSNSVerifier() *SNSVerifier {
+	return &SNSVerifier{
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		certs:      map[string]*x509.Certificate{},
+	}
+}
+
+func (v *SNSVerifier) Verify(msg *SNSMessage) error {
+	if err := validateSNSURL(msg.SigningCertURL); err != nil {
+		return fmt.Errorf("invalid signing cert url: %w", err)
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(msg.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature encoding: %w", err)
+	}
+
+	cert, err := v.certificate(msg.SigningCertURL)
+	if err != nil {
+		return err
+	}
+	pub, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("unsupported signing key type")
+	}
+
+	payload := []byte(snsStringToSign(msg))
+	switch msg.SignatureVersion {
+	case "1":
+		sum := sha1.Sum(payload)
+		err = rsa.VerifyPKCS1v15(pub, crypto.SHA1, sum[:], signature)
+	case "2":
+		sum := sha256.Sum256(payload)
+		err = rsa.VerifyPKCS1v15(pub, crypto.SHA256, sum[:], signature)
+	default:
+		return fmt.Errorf("unsupported signature version: %s", msg.SignatureVersion)
+	}
+	if err != nil {
+		return fmt.Errorf("signature mismatch: %w", err)
+	}
+	return nil
+}
+
+func (v *SNSVerifier) ConfirmSubscription(msg *SNSMessage) error {
+	if err := validateSNSURL(msg.SubscribeURL); err != nil {
+		return fmt.Errorf("invalid subscribe url: %w", err)
+	}
+
+	resp, err := v.httpClient.Get(msg.SubscribeURL)
+	if err != nil {
+		return fmt.Errorf("failed to confirm subscription: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("subscription confirmation returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (v *SNSVerifier) certificate(certURL string) (*x509.Certificate, error) {
+	v.mu.Lock()
+	cert, ok := v.certs[certURL]
+	v.mu.Unlock()
+	if ok {
+		return cert, nil
+	}
+
+	resp, err := v.httpClient.Get(certURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch signing cert: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("signing cert returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing cert: %w", err)
+	}
+
+	block, _ := pem.Decode(body)
+	if block == nil {
+		return nil, fmt.Errorf("signing cert is not PEM encoded")
+	}
+	cert, err = x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing cert: %w", err)
+	}
+
+	v.mu.Lock()
+	v.certs[certURL] = cert
+	v.mu.Unlock()
+
+	return cert, nil
+}
+
+func validateSNSURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "https" {
+		return fmt.Errorf("scheme must be https")
+	}
+	if !snsHostRegex.MatchString(u.Hostname()) {
+		return fmt.Errorf("unexpected host %s", u.Hostname())
+	}
+	return nil
+}
+
+func snsStringToSign(msg *SNSMessage) string {
+	var fields [][2]string
+	if msg.IsNotification() {
+		fields = [][2]string{
+			{"Message", msg.Message},
+			{"MessageId", msg.MessageID},
+			{"Subject", msg.Subject},
+			{"Timestamp", msg.Timestamp},
+			{"TopicArn", msg.TopicArn},
+			{"Type", msg.Type},
+		}
+	} else {
+		fields = [][2]string{
+			{"Message", msg.Message},
+			{"MessageId", msg.MessageID},
+			{"SubscribeURL", msg.SubscribeURL},
+			{"Timestamp", msg.Timestamp},
+			{"Token", msg.Token},
+			{"TopicArn", msg.TopicArn},
+			{"Type", msg.Type},
+		}
+	}
+
+	var b strings.Builder
+	for _, f := range fields {
+		if f[0] == "Subject" && f[1] == "" {
+			continue
+		}
+		b.WriteString(f[0])
+		b.WriteString("\n")
+		b.WriteString(f[1])
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,35 @@
+package database
+
+import (
+	"github.com/rikut0904/mailer-backend/internal/domain/entity"
+	"github.com/rikut0904/mailer-backend/internal/domain/repository"
+	"gorm.io/gorm"
+)
+
+type domainSyncStateRepository struct {
+	db *gorm.DB
+}
+
+func NewDomainSyncStateRepository(db *gorm.DB) repository.DomainSyncStateRepository {
+	return &domainSyncStateRepository{db: db}
+}
+
+func (r *domainSyncStateRepository) GetByDomainID(domainID string) (*entity.DomainSyncState, error) {
+	var state entity.DomainSyncState
+	if err := r.db.Where("domain_id = ?", domainID).First(&state).Error; err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+func (r *domainSyncStateRepository) List() ([]entity.DomainSyncState, error) {
+	var states []entity.DomainSyncState
+	if err := r.db.Order("domain_id").Find(&states).Error; err != nil {
+		return nil, err
+	}
+	return states, nil
+}
+
+func (r *domainSyncStateRepository) Save(state *entity.DomainSyncState) error {
+	return r.db.Save(state).Error
+}
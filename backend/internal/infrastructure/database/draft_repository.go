@@ -0,0 +1,101 @@
+package database
+
+import (
+	"time"
+
+	"github.com/rikut0904/mailer-backend/internal/domain/entity"
+	"github.com/rikut0904/mailer-backend/internal/domain/repository"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type draftRepository struct {
+	db *gorm.DB
+}
+
+func NewDraftRepository(db *gorm.DB) repository.DraftRepository {
+	return &draftRepository{db: db}
+}
+
+func (r *draftRepository) ListByUID(uid string) ([]entity.Draft, error) {
+	var drafts []entity.Draft
+	if err := r.withAttachments().Where("uid = ?", uid).Order("updated_at DESC").Find(&drafts).Error; err != nil {
+		return nil, err
+	}
+	return drafts, nil
+}
+
+func (r *draftRepository) GetByID(uid, id string) (*entity.Draft, error) {
+	var draft entity.Draft
+	if err := r.withAttachments().Where("id = ? AND uid = ?", id, uid).First(&draft).Error; err != nil {
+		return nil, err
+	}
+	return &draft, nil
+}
+
+func (r *draftRepository) Create(draft *entity.Draft) error {
+	return r.db.Omit(clause.Associations).Create(draft).Error
+}
+
+// Update never writes attachments; they are loaded without their content.
+// The status is only changed through MarkSending and MarkEditing.
+func (r *draftRepository) Update(draft *entity.Draft) error {
+	return r.db.Omit(clause.Associations, "status").Save(draft).Error
+}
+
+func (r *draftRepository) Delete(uid, id string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		owned := tx.Model(&entity.Draft{}).Select("id").Where("id = ? AND uid = ?", id, uid)
+		if err := tx.Where("draft_id IN (?)", owned).Delete(&entity.DraftAttachment{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&entity.Draft{}, "id = ? AND uid = ?", id, uid)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+func (r *draftRepository) MarkSending(uid, id string, staleBefore time.Time) (bool, error) {
+	result := r.db.Model(&entity.Draft{}).
+		Where("id = ? AND uid = ?", id, uid).
+		Where("status = ? OR (status = ? AND updated_at < ?)", entity.DraftStatusEditing, entity.DraftStatusSending, staleBefore).
+		Updates(map[string]interface{}{"status": entity.DraftStatusSending, "updated_at": time.Now()})
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *draftRepository) MarkEditing(uid, id string) error {
+	return r.db.Model(&entity.Draft{}).
+		Where("id = ? AND uid = ?", id, uid).
+		Update("status", entity.DraftStatusEditing).Error
+}
+
+func (r *draftRepository) AddAttachments(attachments []entity.DraftAttachment) error {
+	if len(attachments) == 0 {
+		return nil
+	}
+	return r.db.Create(&attachments).Error
+}
+
+func (r *draftRepository) DeleteAttachment(draftID, attachmentID string) (bool, error) {
+	result := r.db.Delete(&entity.DraftAttachment{}, "id = ? AND draft_id = ?", attachmentID, draftID)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *draftRepository) ListAttachmentContents(draftID string) ([]entity.DraftAttachment, error) {
+	var attachments []entity.DraftAttachment
+	if err := r.db.Where("draft_id = ?", draftID).Order("created_at ASC").Find(&attachments).Error; err != nil {
+		return nil, err
+	}
+	return attachments, nil
+}
+
+func (r *draftRepository) withAttachments() *gorm.DB {
+	return r.db.Preload("Attachments", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id", "draft_id", "filename", "content_type", "size", "created_at").Order("created_at ASC")
+	})
+}
@@ -0,0 +1,45 @@
+package database
+
+import (
+	"github.com/rikut0904/mailer-backend/internal/domain/entity"
+	"github.com/rikut0904/mailer-backend/internal/domain/repository"
+	"gorm.io/gorm"
+)
+
+type heldNotificationRepository struct {
+	db *gorm.DB
+}
+
+func NewHeldNotificationRepository(db *gorm.DB) repository.HeldNotificationRepository {
+	return &heldNotificationRepository{db: db}
+}
+
+func (r *heldNotificationRepository) Create(notifications []entity.HeldNotification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	return r.db.Create(&notifications).Error
+}
+
+func (r *heldNotificationRepository) ListUIDs() ([]string, error) {
+	var uids []string
+	if err := r.db.Model(&entity.HeldNotification{}).Distinct("uid").Pluck("uid", &uids).Error; err != nil {
+		return nil, err
+	}
+	return uids, nil
+}
+
+func (r *heldNotificationRepository) ListByUID(uid string) ([]entity.HeldNotification, error) {
+	var notifications []entity.HeldNotification
+	if err := r.db.Where("uid = ?", uid).Order("created_at ASC").Find(&notifications).Error; err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+func (r *heldNotificationRepository) DeleteByIDs(ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.Where("id IN ?", ids).Delete(&entity.HeldNotification{}).Error
+}
@@ -51,6 +51,17 @@ func (r *mailStateRepository) FindByThreadID(domainID, threadID string) ([]entit
 	return states, nil
 }
 
+func (r *mailStateRepository) FindByMessageIDs(domainID string, messageIDs []string) ([]entity.MailState, error) {
+	var states []entity.MailState
+	if len(messageIDs) == 0 {
+		return states, nil
+	}
+	if err := r.db.Where("domain_id = ? AND message_id IN ?", domainID, messageIDs).Find(&states).Error; err != nil {
+		return nil, err
+	}
+	return states, nil
+}
+
 func (r *mailStateRepository) Upsert(state *entity.MailState) error {
 	return r.db.Clauses(clause.OnConflict{
 		Columns:   []clause.Column{{Name: "domain_id"}, {Name: "s3_key"}},
@@ -58,6 +69,32 @@ func (r *mailStateRepository) Upsert(state *entity.MailState) error {
 	}).Create(state).Error
 }
 
+func (r *mailStateRepository) UpdateMetadata(state *entity.MailState) error {
+	return r.db.Model(&entity.MailState{}).Where("domain_id = ? AND s3_key = ?", state.DomainID, state.S3Key).Updates(map[string]interface{}{
+		"recipient_address": state.RecipientAddress,
+		"message_id":        state.MessageID,
+		"in_reply_to":       state.InReplyTo,
+		"reference_ids":     state.References,
+		"subject":           state.Subject,
+		"from_address":      state.FromAddress,
+		"to_address":        state.ToAddress,
+		"cc":                state.Cc,
+		"mail_date":         state.MailDate,
+		"snippet":           state.Snippet,
+		"attachment_count":  state.AttachmentCount,
+		"attachment_size":   state.AttachmentSize,
+		"indexed_at":        state.IndexedAt,
+	}).Error
+}
+
+func (r *mailStateRepository) ListRecipients(domainID string) ([]string, error) {
+	var recipients []string
+	if err := r.db.Model(&entity.MailState{}).Where("domain_id = ? AND recipient_address <> ''", domainID).Distinct().Pluck("recipient_address", &recipients).Error; err != nil {
+		return nil, err
+	}
+	return recipients, nil
+}
+
 func (r *mailStateRepository) UpdateReadStatus(domainID, s3Key string, isRead bool) error {
 	return r.db.Model(&entity.MailState{}).Where("domain_id = ? AND s3_key = ?", domainID, s3Key).Update("is_read", isRead).Error
 }
@@ -85,3 +122,15 @@ func (r *mailStateRepository) CountUnread(domainID, recipientAddress string) (in
 	}
 	return count, nil
 }
+
+func (r *mailStateRepository) FindUnread(domainID, recipientAddress string, limit int) ([]entity.MailState, error) {
+	var states []entity.MailState
+	query := r.db.Where("domain_id = ? AND is_read = ?", domainID, false)
+	if recipientAddress != "" {
+		query = query.Where("recipient_address = ?", recipientAddress)
+	}
+	if err := query.Order("created_at DESC").Limit(limit).Find(&states).Error; err != nil {
+		return nil, err
+	}
+	return states, nil
+}
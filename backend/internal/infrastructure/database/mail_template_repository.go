@@ -0,0 +1,50 @@
+package database
+
+import (
+	"github.com/rikut0904/mailer-backend/internal/domain/entity"
+	"github.com/rikut0904/mailer-backend/internal/domain/repository"
+	"gorm.io/gorm"
+)
+
+type mailTemplateRepository struct {
+	db *gorm.DB
+}
+
+func NewMailTemplateRepository(db *gorm.DB) repository.MailTemplateRepository {
+	return &mailTemplateRepository{db: db}
+}
+
+func (r *mailTemplateRepository) ListByUID(uid string) ([]entity.MailTemplate, error) {
+	var templates []entity.MailTemplate
+	if err := r.db.Where("uid = ?", uid).Order("name ASC").Find(&templates).Error; err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+func (r *mailTemplateRepository) GetByID(uid, id string) (*entity.MailTemplate, error) {
+	var template entity.MailTemplate
+	if err := r.db.Where("id = ? AND uid = ?", id, uid).First(&template).Error; err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+func (r *mailTemplateRepository) Create(template *entity.MailTemplate) error {
+	return r.db.Create(template).Error
+}
+
+func (r *mailTemplateRepository) Update(template *entity.MailTemplate) error {
+	return r.db.Save(template).Error
+}
+
+func (r *mailTemplateRepository) Delete(uid, id string) error {
+	result := r.db.Delete(&entity.MailTemplate{}, "id = ? AND uid = ?", id, uid)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
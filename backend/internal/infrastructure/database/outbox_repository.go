@@ -0,0 +1,193 @@
+package database
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rikut0904/mailer-backend/internal/domain/entity"
+	"github.com/rikut0904/mailer-backend/internal/domain/repository"
+	"gorm.io/gorm"
+)
+
+// outboxInsertBatchSize keeps a large merge release under the PostgreSQL
+// limit of 65535 bind parameters per statement.
+const outboxInsertBatchSize = 500
+
+type outboxRepository struct {
+	db *gorm.DB
+}
+
+func NewOutboxRepository(db *gorm.DB) repository.OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+func (r *outboxRepository) CreateBatch(batch *entity.OutboxBatch) error {
+	return r.db.Create(batch).Error
+}
+
+func (r *outboxRepository) CreateMergeBatch(batch *entity.OutboxBatch, rows []entity.OutboxMergeRow) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(batch).Error; err != nil {
+			return err
+		}
+		return tx.CreateInBatches(&rows, outboxInsertBatchSize).Error
+	})
+}
+
+func (r *outboxRepository) ListMergeRecipients(batchID string) ([]entity.OutboxMergeRow, error) {
+	var rows []entity.OutboxMergeRow
+	if err := r.db.Select("batch_id", "row_number", "email").
+		Where("batch_id = ?", batchID).Order("row_number ASC").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *outboxRepository) GetMergeRow(batchID string, rowNumber int) (*entity.OutboxMergeRow, error) {
+	var row entity.OutboxMergeRow
+	if err := r.db.Where("batch_id = ? AND row_number = ?", batchID, rowNumber).First(&row).Error; err != nil {
+		return nil, err
+	}
+	return &row, nil
+}
+
+func (r *outboxRepository) GetBatch(id string) (*entity.OutboxBatch, error) {
+	var batch entity.OutboxBatch
+	if err := r.db.Where("id = ?", id).First(&batch).Error; err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+func (r *outboxRepository) ListBatchesByUID(uid, status string) ([]entity.OutboxBatch, error) {
+	var batches []entity.OutboxBatch
+	if err := r.db.Where("uid = ? AND status = ?", uid, status).Order("send_at ASC").Find(&batches).Error; err != nil {
+		return nil, err
+	}
+	return batches, nil
+}
+
+func (r *outboxRepository) ListDueBatches(now time.Time, limit int) ([]entity.OutboxBatch, error) {
+	var batches []entity.OutboxBatch
+	if err := r.db.Where("status = ? AND send_at <= ?", entity.OutboxBatchStatusScheduled, now).
+		Order("send_at ASC").Limit(limit).Find(&batches).Error; err != nil {
+		return nil, err
+	}
+	return batches, nil
+}
+
+func (r *outboxRepository) UpdateScheduledBatch(batch *entity.OutboxBatch) (bool, error) {
+	result := r.db.Model(&entity.OutboxBatch{}).
+		Where("id = ? AND status = ?", batch.ID, entity.OutboxBatchStatusScheduled).
+		Updates(map[string]interface{}{
+			"subject": batch.Subject,
+			"send_at": batch.SendAt,
+			"payload": batch.Payload,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *outboxRepository) CancelBatch(id string) (bool, error) {
+	result := r.db.Model(&entity.OutboxBatch{}).
+		Where("id = ? AND status = ?", id, entity.OutboxBatchStatusScheduled).
+		Update("status", entity.OutboxBatchStatusCanceled)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *outboxRepository) ReleaseBatch(batchID string, messages []entity.OutboxMessage) (bool, error) {
+	released := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&entity.OutboxBatch{}).
+			Where("id = ? AND status = ?", batchID, entity.OutboxBatchStatusScheduled).
+			Update("status", entity.OutboxBatchStatusReleased)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != 1 {
+			return nil
+		}
+		released = true
+		if len(messages) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(&messages, outboxInsertBatchSize).Error
+	})
+	return released && err == nil, err
+}
+
+func (r *outboxRepository) ListMessages(batchID string) ([]entity.OutboxMessage, error) {
+	var messages []entity.OutboxMessage
+	if err := r.db.Where("batch_id = ?", batchID).Order("row_number ASC, created_at ASC, id ASC").Find(&messages).Error; err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+func (r *outboxRepository) ClaimDue(now time.Time, limit int) ([]entity.OutboxMessage, error) {
+	var candidates []entity.OutboxMessage
+	if err := r.db.Where("status = ? AND next_attempt_at <= ?", entity.OutboxStatusQueued, now).
+		Order("next_attempt_at ASC").Limit(limit).Find(&candidates).Error; err != nil {
+		return nil, err
+	}
+
+	claimed := make([]entity.OutboxMessage, 0, len(candidates))
+	for _, message := range candidates {
+		token := uuid.NewString()
+		result := r.db.Model(&entity.OutboxMessage{}).
+			Where("id = ? AND status = ?", message.ID, entity.OutboxStatusQueued).
+			Updates(map[string]interface{}{"status": entity.OutboxStatusSending, "claim_token": token, "updated_at": now})
+		if result.Error != nil {
+			return claimed, result.Error
+		}
+		if result.RowsAffected == 1 {
+			message.Status = entity.OutboxStatusSending
+			message.ClaimToken = token
+			message.UpdatedAt = now
+			claimed = append(claimed, message)
+		}
+	}
+	return claimed, nil
+}
+
+func (r *outboxRepository) RequeueStale(before time.Time) (int64, error) {
+	result := r.db.Model(&entity.OutboxMessage{}).
+		Where("status = ? AND updated_at < ?", entity.OutboxStatusSending, before).
+		Update("status", entity.OutboxStatusQueued)
+	return result.RowsAffected, result.Error
+}
+
+func (r *outboxRepository) RenewClaim(message *entity.OutboxMessage, now time.Time) (bool, error) {
+	result := r.db.Model(&entity.OutboxMessage{}).
+		Where("id = ? AND status = ? AND claim_token = ?", message.ID, entity.OutboxStatusSending, message.ClaimToken).
+		Update("updated_at", now)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *outboxRepository) FinishMessage(message *entity.OutboxMessage, sent *entity.SentMail) (bool, error) {
+	finished := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&entity.OutboxMessage{}).
+			Where("id = ? AND status = ? AND claim_token = ?", message.ID, entity.OutboxStatusSending, message.ClaimToken).
+			Updates(map[string]interface{}{
+				"status":          message.Status,
+				"attempts":        message.Attempts,
+				"next_attempt_at": message.NextAttemptAt,
+				"last_error":      message.LastError,
+				"sent_at":         message.SentAt,
+				"ses_message_id":  message.SESMessageID,
+				"updated_at":      time.Now(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != 1 {
+			return nil
+		}
+		finished = true
+		if sent == nil {
+			return nil
+		}
+		return tx.Create(sent).Error
+	})
+	return finished && err == nil, err
+}
@@ -1,6 +1,7 @@
 package database
 
 import (
+	"github.com/google/uuid"
 	"github.com/rikut0904/mailer-backend/internal/domain/entity"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -18,7 +19,10 @@ func NewPostgresDB(dsn string) (*gorm.DB, error) {
 }
 
 func AutoMigrate(db *gorm.DB) error {
-	return db.AutoMigrate(
+	backfillDigests := db.Migrator().HasTable(&entity.UserSetting{}) &&
+		!db.Migrator().HasColumn(&entity.UserSetting{}, "unread_digest_enabled")
+
+	if err := db.AutoMigrate(
 		&entity.MailState{},
 		&entity.ThreadGroup{},
 		&entity.SentMail{},
@@ -26,5 +30,84 @@ func AutoMigrate(db *gorm.DB) error {
 		&entity.User{},
 		&entity.S3Domain{},
 		&entity.SystemSetting{},
-	)
+		&entity.DomainSyncState{},
+		&entity.SenderIdentity{},
+		&entity.OutboxBatch{},
+		&entity.OutboxMessage{},
+		&entity.OutboxMergeRow{},
+		&entity.Draft{},
+		&entity.DraftAttachment{},
+		&entity.SuppressedAddress{},
+		&entity.MailTemplate{},
+		&entity.WebhookSubscription{},
+		&entity.WebhookDelivery{},
+		&entity.HeldNotification{},
+	); err != nil {
+		return err
+	}
+	if err := migrateDiscordWebhookURLs(db); err != nil {
+		return err
+	}
+	if backfillDigests {
+		return backfillUnreadDigestEnabled(db)
+	}
+	return nil
+}
+
+// backfillUnreadDigestEnabled fills the unread_digest_enabled column from the
+// unread_digest settings stored before it was added.
+func backfillUnreadDigestEnabled(db *gorm.DB) error {
+	var settings []entity.UserSetting
+	if err := db.Select("uid", "unread_digest").
+		Where("unread_digest IS NOT NULL AND unread_digest <> ''").Find(&settings).Error; err != nil {
+		return err
+	}
+	for _, setting := range settings {
+		if !setting.UnreadDigest.Enabled {
+			continue
+		}
+		if err := db.Model(&entity.UserSetting{}).Where("uid = ?", setting.UID).
+			UpdateColumn("unread_digest_enabled", true).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateDiscordWebhookURLs moves the former user_settings.discord_webhook_url
+// column into notification_channels and drops it.
+func migrateDiscordWebhookURLs(db *gorm.DB) error {
+	if !db.Migrator().HasColumn(&entity.UserSetting{}, "discord_webhook_url") {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		var rows []struct {
+			UID               string
+			DiscordWebhookURL string
+		}
+		if err := tx.Table("user_settings").Select("uid, discord_webhook_url").
+			Where("discord_webhook_url <> ''").Find(&rows).Error; err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			setting := entity.UserSetting{UID: row.UID}
+			if err := tx.Where("uid = ?", row.UID).First(&setting).Error; err != nil {
+				return err
+			}
+			if len(setting.NotificationChannels) > 0 {
+				continue
+			}
+			setting.NotificationChannels = []entity.NotificationChannel{{
+				ID:         uuid.New().String(),
+				Type:       entity.NotificationChannelDiscord,
+				WebhookURL: row.DiscordWebhookURL,
+			}}
+			if err := tx.Model(&setting).Select("notification_channels").Updates(&setting).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Migrator().DropColumn(&entity.UserSetting{}, "discord_webhook_url")
+	})
 }
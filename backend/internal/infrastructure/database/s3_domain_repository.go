@@ -30,6 +30,14 @@ func (r *s3DomainRepository) GetByID(id string) (*entity.S3Domain, error) {
 	return &domain, nil
 }
 
+func (r *s3DomainRepository) FindByBucket(bucket string) ([]entity.S3Domain, error) {
+	var domains []entity.S3Domain
+	if err := r.db.Where("bucket = ?", bucket).Order("created_at asc").Find(&domains).Error; err != nil {
+		return nil, err
+	}
+	return domains, nil
+}
+
 func (r *s3DomainRepository) Create(domain *entity.S3Domain) error {
 	return r.db.Create(domain).Error
 }
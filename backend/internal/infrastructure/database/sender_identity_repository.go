@@ -0,0 +1,51 @@
+package database
+
+import (
+	"github.com/rikut0904/mailer-backend/internal/domain/entity"
+	"github.com/rikut0904/mailer-backend/internal/domain/repository"
+	"gorm.io/gorm"
+)
+
+type senderIdentityRepository struct {
+	db *gorm.DB
+}
+
+func NewSenderIdentityRepository(db *gorm.DB) repository.SenderIdentityRepository {
+	return &senderIdentityRepository{db: db}
+}
+
+func (r *senderIdentityRepository) List() ([]entity.SenderIdentity, error) {
+	var identities []entity.SenderIdentity
+	if err := r.db.Order("address asc").Find(&identities).Error; err != nil {
+		return nil, err
+	}
+	return identities, nil
+}
+
+func (r *senderIdentityRepository) GetByID(id string) (*entity.SenderIdentity, error) {
+	var identity entity.SenderIdentity
+	if err := r.db.Where("id = ?", id).First(&identity).Error; err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (r *senderIdentityRepository) FindByAddress(address string) (*entity.SenderIdentity, error) {
+	var identity entity.SenderIdentity
+	if err := r.db.Where("LOWER(address) = LOWER(?)", address).First(&identity).Error; err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (r *senderIdentityRepository) Create(identity *entity.SenderIdentity) error {
+	return r.db.Create(identity).Error
+}
+
+func (r *senderIdentityRepository) Update(identity *entity.SenderIdentity) error {
+	return r.db.Save(identity).Error
+}
+
+func (r *senderIdentityRepository) Delete(id string) error {
+	return r.db.Delete(&entity.SenderIdentity{}, "id = ?", id).Error
+}
@@ -1,6 +1,8 @@
 package database
 
 import (
+	"time"
+
 	"github.com/rikut0904/mailer-backend/internal/domain/entity"
 	"github.com/rikut0904/mailer-backend/internal/domain/repository"
 	"gorm.io/gorm"
@@ -30,6 +32,43 @@ func (r *sentMailRepository) FindByParentThreadID(threadID string) ([]entity.Sen
 	return sentMails, nil
 }
 
+func (r *sentMailRepository) FindByMessageIDs(messageIDs []string) ([]entity.SentMail, error) {
+	var sentMails []entity.SentMail
+	if len(messageIDs) == 0 {
+		return sentMails, nil
+	}
+	if err := r.db.Where("message_id IN ? OR ses_message_id IN ?", messageIDs, messageIDs).Find(&sentMails).Error; err != nil {
+		return nil, err
+	}
+	return sentMails, nil
+}
+
+func (r *sentMailRepository) FindByRecipientEmail(email string) ([]entity.SentMail, error) {
+	var sentMails []entity.SentMail
+	if err := r.db.Where("LOWER(recipient_email) = LOWER(?)", email).Order("sent_at DESC").Find(&sentMails).Error; err != nil {
+		return nil, err
+	}
+	return sentMails, nil
+}
+
+func (r *sentMailRepository) FindByProviderMessageID(providerMessageID string) ([]entity.SentMail, error) {
+	var sentMails []entity.SentMail
+	if err := r.db.Where("provider_message_id = ?", providerMessageID).Find(&sentMails).Error; err != nil {
+		return nil, err
+	}
+	return sentMails, nil
+}
+
+func (r *sentMailRepository) UpdateDeliveryStatus(managementCode, status, detail string, at time.Time) error {
+	return r.db.Model(&entity.SentMail{}).
+		Where("management_code = ?", managementCode).
+		Updates(map[string]interface{}{
+			"delivery_status":     status,
+			"delivery_detail":     detail,
+			"delivery_updated_at": at,
+		}).Error
+}
+
 func (r *sentMailRepository) Create(sentMail *entity.SentMail) error {
 	return r.db.Create(sentMail).Error
 }
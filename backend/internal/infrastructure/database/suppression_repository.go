@@ -0,0 +1,54 @@
+package database
+
+import (
+	"strings"
+
+	"github.com/rikut0904/mailer-backend/internal/domain/entity"
+	"github.com/rikut0904/mailer-backend/internal/domain/repository"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type suppressionRepository struct {
+	db *gorm.DB
+}
+
+func NewSuppressionRepository(db *gorm.DB) repository.SuppressionRepository {
+	return &suppressionRepository{db: db}
+}
+
+func (r *suppressionRepository) List() ([]entity.SuppressedAddress, error) {
+	var addresses []entity.SuppressedAddress
+	if err := r.db.Order("updated_at DESC").Find(&addresses).Error; err != nil {
+		return nil, err
+	}
+	return addresses, nil
+}
+
+func (r *suppressionRepository) FindByEmails(emails []string) ([]entity.SuppressedAddress, error) {
+	var addresses []entity.SuppressedAddress
+	if len(emails) == 0 {
+		return addresses, nil
+	}
+
+	lowered := make([]string, 0, len(emails))
+	for _, email := range emails {
+		lowered = append(lowered, strings.ToLower(strings.TrimSpace(email)))
+	}
+	if err := r.db.Where("email IN ?", lowered).Find(&addresses).Error; err != nil {
+		return nil, err
+	}
+	return addresses, nil
+}
+
+func (r *suppressionRepository) Upsert(address *entity.SuppressedAddress) error {
+	address.Email = strings.ToLower(strings.TrimSpace(address.Email))
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "email"}},
+		DoUpdates: clause.AssignmentColumns([]string{"reason", "detail", "management_code", "updated_at"}),
+	}).Create(address).Error
+}
+
+func (r *suppressionRepository) Delete(email string) error {
+	return r.db.Delete(&entity.SuppressedAddress{}, "email = ?", strings.ToLower(strings.TrimSpace(email))).Error
+}
@@ -28,7 +28,11 @@ func (r *systemSettingRepository) Get() (*entity.SystemSetting, error) {
 func (r *systemSettingRepository) Upsert(setting *entity.SystemSetting) error {
 	setting.ID = systemSettingID
 	return r.db.Clauses(clause.OnConflict{
-		Columns:   []clause.Column{{Name: "id"}},
-		DoUpdates: clause.AssignmentColumns([]string{"ses_region", "ses_access_key_id", "ses_secret_key", "updated_at"}),
+		Columns: []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"ses_region", "ses_access_key_id", "ses_secret_key",
+			"mail_transport", "smtp_fallback", "smtp_host", "smtp_port", "smtp_security",
+			"smtp_auth", "smtp_username", "smtp_password", "updated_at",
+		}),
 	}).Create(setting).Error
 }
@@ -1,6 +1,8 @@
 package database
 
 import (
+	"time"
+
 	"github.com/rikut0904/mailer-backend/internal/domain/entity"
 	"github.com/rikut0904/mailer-backend/internal/domain/repository"
 	"gorm.io/gorm"
@@ -24,8 +26,40 @@ func (r *userSettingRepository) GetByUID(uid string) (*entity.UserSetting, error
 }
 
 func (r *userSettingRepository) Upsert(setting *entity.UserSetting) error {
+	setting.UnreadDigestEnabled = setting.UnreadDigest.Enabled
 	return r.db.Clauses(clause.OnConflict{
 		Columns:   []clause.Column{{Name: "uid"}},
-		DoUpdates: clause.AssignmentColumns([]string{"discord_webhook_url", "selected_domain_id", "updated_at"}),
+		DoUpdates: clause.AssignmentColumns([]string{"notification_channels", "notification_rules", "quiet_hours", "unread_digest", "unread_digest_enabled", "selected_domain_id", "updated_at"}),
 	}).Create(setting).Error
 }
+
+func (r *userSettingRepository) ListWithNotificationChannels() ([]entity.UserSetting, error) {
+	var settings []entity.UserSetting
+	if err := r.db.Where("notification_channels IS NOT NULL AND notification_channels NOT IN ('', 'null', '[]')").
+		Find(&settings).Error; err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+func (r *userSettingRepository) ListWithUnreadDigest() ([]entity.UserSetting, error) {
+	var settings []entity.UserSetting
+	if err := r.db.Where("unread_digest_enabled = ?", true).Find(&settings).Error; err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+func (r *userSettingRepository) ClaimUnreadDigest(uid string, previous *time.Time, sentAt time.Time) (bool, error) {
+	query := r.db.Model(&entity.UserSetting{}).Where("uid = ?", uid)
+	if previous == nil {
+		query = query.Where("unread_digest_sent_at IS NULL")
+	} else {
+		query = query.Where("unread_digest_sent_at = ?", *previous)
+	}
+	result := query.UpdateColumn("unread_digest_sent_at", sentAt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
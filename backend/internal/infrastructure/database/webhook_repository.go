@@ -0,0 +1,118 @@
+package database
+
+import (
+	"time"
+
+	"github.com/rikut0904/mailer-backend/internal/domain/entity"
+	"github.com/rikut0904/mailer-backend/internal/domain/repository"
+	"gorm.io/gorm"
+)
+
+// webhookInsertBatchSize keeps a burst of deliveries under the PostgreSQL
+// limit of 65535 bind parameters per statement.
+const webhookInsertBatchSize = 500
+
+type webhookRepository struct {
+	db *gorm.DB
+}
+
+func NewWebhookRepository(db *gorm.DB) repository.WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+func (r *webhookRepository) ListSubscriptions() ([]entity.WebhookSubscription, error) {
+	var subscriptions []entity.WebhookSubscription
+	if err := r.db.Order("created_at ASC").Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+func (r *webhookRepository) GetSubscription(id string) (*entity.WebhookSubscription, error) {
+	var subscription entity.WebhookSubscription
+	if err := r.db.Where("id = ?", id).First(&subscription).Error; err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+func (r *webhookRepository) CreateSubscription(subscription *entity.WebhookSubscription) error {
+	return r.db.Create(subscription).Error
+}
+
+func (r *webhookRepository) UpdateSubscription(subscription *entity.WebhookSubscription) error {
+	result := r.db.Model(&entity.WebhookSubscription{}).Where("id = ?", subscription.ID).
+		Select("url", "events", "secret", "description", "enabled", "updated_at").
+		Updates(subscription)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *webhookRepository) DeleteSubscription(id string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ?", id).Delete(&entity.WebhookSubscription{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Where("subscription_id = ?", id).Delete(&entity.WebhookDelivery{}).Error
+	})
+}
+
+func (r *webhookRepository) CreateDeliveries(deliveries []entity.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return r.db.CreateInBatches(&deliveries, webhookInsertBatchSize).Error
+}
+
+func (r *webhookRepository) ListDeliveries(subscriptionID string, limit int) ([]entity.WebhookDelivery, error) {
+	var deliveries []entity.WebhookDelivery
+	if err := r.db.Where("subscription_id = ?", subscriptionID).
+		Order("created_at DESC").Limit(limit).Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (r *webhookRepository) ClaimDueDeliveries(now time.Time, limit int) ([]entity.WebhookDelivery, error) {
+	var candidates []entity.WebhookDelivery
+	if err := r.db.Where("status = ? AND next_attempt_at <= ?", entity.WebhookDeliveryStatusPending, now).
+		Order("next_attempt_at ASC").Limit(limit).Find(&candidates).Error; err != nil {
+		return nil, err
+	}
+
+	claimed := make([]entity.WebhookDelivery, 0, len(candidates))
+	for _, delivery := range candidates {
+		result := r.db.Model(&entity.WebhookDelivery{}).
+			Where("id = ? AND status = ?", delivery.ID, entity.WebhookDeliveryStatusPending).
+			Updates(map[string]interface{}{"status": entity.WebhookDeliveryStatusSending, "updated_at": now})
+		if result.Error != nil {
+			return claimed, result.Error
+		}
+		if result.RowsAffected == 1 {
+			delivery.Status = entity.WebhookDeliveryStatusSending
+			delivery.UpdatedAt = now
+			claimed = append(claimed, delivery)
+		}
+	}
+	return claimed, nil
+}
+
+func (r *webhookRepository) RequeueStaleDeliveries(before time.Time) (int64, error) {
+	result := r.db.Model(&entity.WebhookDelivery{}).
+		Where("status = ? AND updated_at < ?", entity.WebhookDeliveryStatusSending, before).
+		Update("status", entity.WebhookDeliveryStatusPending)
+	return result.RowsAffected, result.Error
+}
+
+func (r *webhookRepository) UpdateDelivery(delivery *entity.WebhookDelivery) error {
+	return r.db.Save(delivery).Error
+}
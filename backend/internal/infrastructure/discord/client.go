@@ -4,41 +4,133 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"strconv"
+	"sync"
+	"time"
 )
 
+// MaxEmbeds is the number of embeds Discord accepts in one webhook message.
+const MaxEmbeds = 10
+
+// maxRateLimitRetries bounds how often a message is resent after a 429.
+const maxRateLimitRetries = 3
+
 type Client struct {
 	webhookURL string
+	httpClient *http.Client
+
+	mu sync.Mutex
+	// blockedUntil holds, per webhook URL, when its rate-limit bucket resets
+	// after Discord reported it exhausted.
+	blockedUntil map[string]time.Time
 }
 
 func NewClient(webhookURL string) *Client {
-	return &Client{webhookURL: webhookURL}
+	return &Client{
+		webhookURL:   webhookURL,
+		httpClient:   &http.Client{Timeout: 10 * time.Second},
+		blockedUntil: make(map[string]time.Time),
+	}
 }
 
 type WebhookPayload struct {
-	Content string `json:"content"`
+	Content string  `json:"content,omitempty"`
+	Embeds  []Embed `json:"embeds,omitempty"`
+}
+
+type Embed struct {
+	Title       string       `json:"title,omitempty"`
+	Description string       `json:"description,omitempty"`
+	URL         string       `json:"url,omitempty"`
+	Timestamp   string       `json:"timestamp,omitempty"`
+	Color       int          `json:"color,omitempty"`
+	Author      *EmbedAuthor `json:"author,omitempty"`
+	Footer      *EmbedFooter `json:"footer,omitempty"`
+}
+
+type EmbedAuthor struct {
+	Name string `json:"name"`
+}
+
+type EmbedFooter struct {
+	Text string `json:"text"`
 }
 
 func (c *Client) SendNotification(message string) error {
 	if c.webhookURL == "" {
 		return nil
 	}
+	return c.Post(c.webhookURL, WebhookPayload{Content: message})
+}
 
-	payload := WebhookPayload{Content: message}
+// Post sends payload to webhookURL. It waits out the bucket reset when an
+// earlier response reported no remaining requests, and on 429 retries after
+// the Retry-After Discord returned.
+func (c *Client) Post(webhookURL string, payload WebhookPayload) error {
 	body, err := json.Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("failed to marshal payload: %w", err)
 	}
 
-	resp, err := http.Post(c.webhookURL, "application/json", bytes.NewReader(body))
+	for attempt := 0; ; attempt++ {
+		c.waitForBucket(webhookURL)
+
+		resp, err := c.httpClient.Post(webhookURL, "application/json", bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("failed to send webhook: %w", err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		c.recordRateLimit(webhookURL, resp)
+
+		if resp.StatusCode == http.StatusTooManyRequests && attempt < maxRateLimitRetries {
+			c.block(webhookURL, retryAfter(resp.Header))
+			continue
+		}
+		if resp.StatusCode >= 400 {
+			return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+		}
+		return nil
+	}
+}
+
+func (c *Client) waitForBucket(webhookURL string) {
+	c.mu.Lock()
+	until := c.blockedUntil[webhookURL]
+	c.mu.Unlock()
+
+	if wait := time.Until(until); wait > 0 {
+		time.Sleep(wait)
+	}
+}
+
+// recordRateLimit reads Discord's X-RateLimit-Remaining and
+// X-RateLimit-Reset-After headers.
+func (c *Client) recordRateLimit(webhookURL string, resp *http.Response) {
+	if resp.Header.Get("X-RateLimit-Remaining") != "0" {
+		return
+	}
+	resetAfter, err := strconv.ParseFloat(resp.Header.Get("X-RateLimit-Reset-After"), 64)
 	if err != nil {
-		return fmt.Errorf("failed to send webhook: %w", err)
+		return
 	}
-	defer resp.Body.Close()
+	c.block(webhookURL, time.Duration(resetAfter*float64(time.Second)))
+}
 
-	if resp.StatusCode >= 400 {
-		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+func (c *Client) block(webhookURL string, wait time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if until := time.Now().Add(wait); until.After(c.blockedUntil[webhookURL]) {
+		c.blockedUntil[webhookURL] = until
 	}
+}
 
-	return nil
+func retryAfter(header http.Header) time.Duration {
+	if seconds, err := strconv.ParseFloat(header.Get("Retry-After"), 64); err == nil {
+		return time.Duration(seconds * float64(time.Second))
+	}
+	return time.Second
 }
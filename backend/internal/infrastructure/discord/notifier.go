@@ -0,0 +1,97 @@
+package discord
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rikut0904/mailer-backend/internal/domain/entity"
+)
+
+const (
+	embedColor = 0x5865F2
+	// Field limits keep MaxEmbeds embeds under Discord's 6000 character total.
+	titleLength       = 100
+	authorLength      = 100
+	descriptionLength = 200
+)
+
+// Notify posts notification as embeds, MaxEmbeds mails per message.
+func (c *Client) Notify(webhookURL string, notification *entity.MailNotification) error {
+	mails := notification.Mails
+	for start := 0; start < len(mails); start += MaxEmbeds {
+		chunk := mails[start:min(start+MaxEmbeds, len(mails))]
+
+		content := fmt.Sprintf("新着メールが %d 件あります", notification.Total)
+		if notification.Digest {
+			content = fmt.Sprintf("通知を控えていた間に届いたメールが %d 件あります", notification.Total)
+		}
+		var mentions []string
+		for _, mail := range chunk {
+			for _, mention := range mail.Mentions {
+				if formatted := formatMention(mention); !contains(mentions, formatted) {
+					mentions = append(mentions, formatted)
+				}
+			}
+		}
+		if len(mentions) > 0 {
+			content = strings.Join(mentions, " ") + " " + content
+		}
+
+		payload := WebhookPayload{Content: content}
+		for _, mail := range chunk {
+			payload.Embeds = append(payload.Embeds, newEmbed(mail))
+		}
+		if err := c.Post(webhookURL, payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newEmbed(mail entity.NotifiedMail) Embed {
+	subject := mail.Subject
+	if subject == "" {
+		subject = "(件名なし)"
+	}
+	embed := Embed{
+		Title:       truncate(subject, titleLength),
+		Description: truncate(mail.Snippet, descriptionLength),
+		URL:         mail.URL,
+		Color:       embedColor,
+		Author:      &EmbedAuthor{Name: truncate(mail.From, authorLength)},
+	}
+	if !mail.Date.IsZero() {
+		embed.Timestamp = mail.Date.UTC().Format(time.RFC3339)
+	}
+	if mail.Recipient != "" {
+		embed.Footer = &EmbedFooter{Text: mail.Recipient}
+	}
+	return embed
+}
+
+func truncate(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit-1]) + "…"
+}
+
+// formatMention turns a bare ID into a role mention; user ("<@id>") and other
+// preformatted mentions, @here and @everyone are used as given.
+func formatMention(mention string) string {
+	if mention != "" && strings.Trim(mention, "0123456789") == "" {
+		return "<@&" + mention + ">"
+	}
+	return mention
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
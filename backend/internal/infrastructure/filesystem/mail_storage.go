@@ -0,0 +1,309 @@
+package filesystem
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rikut0904/mailer-backend/internal/domain/repository"
+)
+
+// mailStorage reads either a Maildir (keys are the unique file names found in
+// new/ and cur/, without the ":2,flags" suffix so they survive flag changes)
+// or a directory tree of .eml files (keys are slash-separated relative paths).
+type mailStorage struct {
+	dir     string
+	maildir bool
+}
+
+func NewMailStorage(baseDir, dir string) (repository.MailStorageRepository, error) {
+	if strings.TrimSpace(baseDir) == "" {
+		return nil, fmt.Errorf("local storage is disabled: LOCAL_STORAGE_ROOT is not set")
+	}
+
+	base, err := os.OpenRoot(baseDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open local storage root: %w", err)
+	}
+	defer base.Close()
+
+	dir = strings.TrimPrefix(filepath.ToSlash(filepath.Clean(dir)), "/")
+	if dir == "" {
+		dir = "."
+	}
+
+	root, err := base.OpenRoot(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open mail directory %s: %w", dir, err)
+	}
+	defer root.Close()
+
+	return &mailStorage{
+		dir:     filepath.Join(baseDir, filepath.FromSlash(dir)),
+		maildir: isDir(root, "cur") && isDir(root, "new"),
+	}, nil
+}
+
+func (s *mailStorage) ListKeys(prefix string, continuationToken *string, maxKeys int) ([]string, *string, error) {
+	startAfter := ""
+	if continuationToken != nil {
+		startAfter = *continuationToken
+	}
+
+	keys, err := s.ListKeysAfter(prefix, startAfter, maxKeys+1)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var nextToken *string
+	if maxKeys > 0 && len(keys) > maxKeys {
+		keys = keys[:maxKeys]
+		last := keys[len(keys)-1]
+		nextToken = &last
+	}
+	return keys, nextToken, nil
+}
+
+func (s *mailStorage) ListKeysAfter(prefix string, startAfter string, maxKeys int) ([]string, error) {
+	root, err := os.OpenRoot(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	defer root.Close()
+
+	if !s.maildir {
+		return listTreeKeys(root.FS(), prefix, startAfter, maxKeys)
+	}
+
+	all, err := s.maildirKeys(root)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0)
+	for _, key := range all[sort.SearchStrings(all, startAfter):] {
+		if key <= startAfter || !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		keys = append(keys, key)
+		if maxKeys > 0 && len(keys) >= maxKeys {
+			break
+		}
+	}
+	return keys, nil
+}
+
+func (s *mailStorage) GetObject(key string) ([]byte, error) {
+	root, err := os.OpenRoot(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	defer root.Close()
+
+	name, err := s.resolve(root, key)
+	if err != nil {
+		return nil, notFound(key, err)
+	}
+	data, err := root.ReadFile(name)
+	if err != nil {
+		return nil, notFound(key, err)
+	}
+	return data, nil
+}
+
+// OpenObject returns the file itself; it stays usable after the root is closed.
+func (s *mailStorage) OpenObject(key string) (io.ReadSeekCloser, error) {
+	root, err := os.OpenRoot(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	defer root.Close()
+
+	name, err := s.resolve(root, key)
+	if err != nil {
+		return nil, notFound(key, err)
+	}
+	file, err := root.Open(name)
+	if err != nil {
+		return nil, notFound(key, err)
+	}
+	return file, nil
+}
+
+func notFound(key string, err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w: %s", repository.ErrObjectNotFound, key)
+	}
+	return err
+}
+
+func (s *mailStorage) DeleteObject(key string) error {
+	root, err := os.OpenRoot(s.dir)
+	if err != nil {
+		return err
+	}
+	defer root.Close()
+
+	name, err := s.resolve(root, key)
+	if err != nil {
+		return err
+	}
+	return root.Remove(name)
+}
+
+// maildirCacheSettle keeps a listing out of the cache while the directories
+// were modified too recently for their mtimes to tell later changes apart.
+const maildirCacheSettle = 2 * time.Second
+
+type maildirListing struct {
+	newMod, curMod time.Time
+	keys           []string
+}
+
+// maildirCache holds the sorted keys of each Maildir until new/ or cur/
+// changes, so paging through a large spool does not re-read it every page.
+var maildirCache sync.Map // dir -> *maildirListing
+
+func (s *mailStorage) maildirKeys(root *os.Root) ([]string, error) {
+	newInfo, err := root.Stat("new")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read new: %w", err)
+	}
+	curInfo, err := root.Stat("cur")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cur: %w", err)
+	}
+	if cached, ok := maildirCache.Load(s.dir); ok {
+		listing := cached.(*maildirListing)
+		if listing.newMod.Equal(newInfo.ModTime()) && listing.curMod.Equal(curInfo.ModTime()) {
+			return listing.keys, nil
+		}
+	}
+
+	var keys []string
+	seen := map[string]struct{}{}
+	for _, sub := range []string{"new", "cur"} {
+		entries, err := fs.ReadDir(root.FS(), sub)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", sub, err)
+		}
+		for _, entry := range entries {
+			if !entry.Type().IsRegular() || strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+			key := maildirUniqueName(entry.Name())
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	settled := time.Now().Add(-maildirCacheSettle)
+	if newInfo.ModTime().Before(settled) && curInfo.ModTime().Before(settled) {
+		maildirCache.Store(s.dir, &maildirListing{newMod: newInfo.ModTime(), curMod: curInfo.ModTime(), keys: keys})
+	}
+	return keys, nil
+}
+
+// listTreeKeys walks the .eml tree in key order, skipping directories whose
+// keys all sort before startAfter or lack prefix, and stops once maxKeys are
+// found.
+func listTreeKeys(fsys fs.FS, prefix, startAfter string, maxKeys int) ([]string, error) {
+	keys := make([]string, 0)
+	var walk func(dir, keyPrefix string) error
+	walk = func(dir, keyPrefix string) error {
+		entries, err := fs.ReadDir(fsys, dir)
+		if err != nil {
+			return err
+		}
+		// A directory's keys all start with "name/", so ordering entries by
+		// that (and files by their name) yields the keys in sorted order.
+		sortName := func(entry fs.DirEntry) string {
+			if entry.IsDir() {
+				return entry.Name() + "/"
+			}
+			return entry.Name()
+		}
+		sort.Slice(entries, func(i, j int) bool { return sortName(entries[i]) < sortName(entries[j]) })
+
+		for _, entry := range entries {
+			if maxKeys > 0 && len(keys) >= maxKeys {
+				return nil
+			}
+			key := keyPrefix + sortName(entry)
+			if entry.IsDir() {
+				if strings.HasPrefix(entry.Name(), ".") ||
+					(key < startAfter && !strings.HasPrefix(startAfter, key)) ||
+					!(strings.HasPrefix(key, prefix) || strings.HasPrefix(prefix, key)) {
+					continue
+				}
+				if err := walk(path.Join(dir, entry.Name()), key); err != nil {
+					return err
+				}
+				continue
+			}
+			if entry.Type().IsRegular() && strings.EqualFold(path.Ext(key), ".eml") &&
+				key > startAfter && strings.HasPrefix(key, prefix) {
+				keys = append(keys, key)
+			}
+		}
+		return nil
+	}
+
+	if err := walk(".", ""); err != nil {
+		return nil, fmt.Errorf("failed to walk mail directory: %w", err)
+	}
+	return keys, nil
+}
+
+func (s *mailStorage) resolve(root *os.Root, key string) (string, error) {
+	if key == "" {
+		return "", fs.ErrNotExist
+	}
+	if !s.maildir {
+		return filepath.FromSlash(key), nil
+	}
+	if strings.ContainsAny(key, `/\`) {
+		return "", fs.ErrNotExist
+	}
+
+	if _, err := root.Stat(filepath.Join("new", key)); err == nil {
+		return filepath.Join("new", key), nil
+	}
+
+	entries, err := fs.ReadDir(root.FS(), "cur")
+	if err != nil {
+		return "", err
+	}
+	for _, entry := range entries {
+		if maildirUniqueName(entry.Name()) == key {
+			return filepath.Join("cur", entry.Name()), nil
+		}
+	}
+	return "", fs.ErrNotExist
+}
+
+func maildirUniqueName(name string) string {
+	if idx := strings.Index(name, ":2,"); idx != -1 {
+		return name[:idx]
+	}
+	return name
+}
+
+func isDir(root *os.Root, name string) bool {
+	info, err := root.Stat(name)
+	if err != nil {
+		return false
+	}
+	return info.IsDir()
+}
@@ -0,0 +1,52 @@
+package mailsender
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/rikut0904/mailer-backend/internal/domain/entity"
+	"github.com/rikut0904/mailer-backend/internal/domain/repository"
+)
+
+// selector dispatches to SES or SMTP according to SystemSetting.MailTransport,
+// read on every send so a change takes effect without a restart.
+type selector struct {
+	settingsRepo repository.SystemSettingRepository
+	ses          repository.MailSenderRepository
+	smtp         repository.MailSenderRepository
+}
+
+func NewSelector(
+	settingsRepo repository.SystemSettingRepository,
+	ses repository.MailSenderRepository,
+	smtp repository.MailSenderRepository,
+) repository.MailSenderRepository {
+	return &selector{
+		settingsRepo: settingsRepo,
+		ses:          ses,
+		smtp:         smtp,
+	}
+}
+
+func (s *selector) SendRawEmail(outgoing *entity.OutgoingMail) (string, error) {
+	settings, err := s.settingsRepo.Get()
+	if err != nil {
+		return "", fmt.Errorf("failed to load mail settings: %w", err)
+	}
+
+	if settings.MailTransport == entity.MailTransportSMTP {
+		return s.smtp.SendRawEmail(outgoing)
+	}
+
+	id, err := s.ses.SendRawEmail(outgoing)
+	if err == nil || !settings.SMTPFallback || settings.SMTPHost == "" {
+		return id, err
+	}
+	if !errors.Is(err, repository.ErrTemporarySendFailure) {
+		return "", err
+	}
+
+	log.Printf("mail sender: SES failed temporarily, relaying %s over SMTP: %v", outgoing.MessageID, err)
+	return s.smtp.SendRawEmail(outgoing)
+}
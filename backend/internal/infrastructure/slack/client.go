@@ -0,0 +1,196 @@
+package slack
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rikut0904/mailer-backend/internal/domain/entity"
+	"github.com/rikut0904/mailer-backend/internal/domain/repository"
+)
+
+const (
+	// mailsPerMessage keeps messages well under Slack's 50 block limit.
+	mailsPerMessage = 20
+	snippetLength   = 200
+	maxRetries      = 3
+)
+
+// Client posts notifications to Slack incoming webhooks using Block Kit.
+type Client struct {
+	httpClient *http.Client
+}
+
+func NewClient() repository.Notifier {
+	return &Client{httpClient: &http.Client{Timeout: 10 * time.Second}}
+}
+
+type message struct {
+	// Text is the fallback shown in push notifications
+	Text   string  `json:"text"`
+	Blocks []block `json:"blocks"`
+}
+
+type block struct {
+	Type     string  `json:"type"`
+	Text     *text   `json:"text,omitempty"`
+	Elements []text  `json:"elements,omitempty"`
+	Fields   []*text `json:"fields,omitempty"`
+}
+
+type text struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+func (c *Client) Notify(webhookURL string, notification *entity.MailNotification) error {
+	mails := notification.Mails
+	for start := 0; start < len(mails); start += mailsPerMessage {
+		chunk := mails[start:min(start+mailsPerMessage, len(mails))]
+		if err := c.post(webhookURL, newMessage(chunk, notification.Total, notification.Digest)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newMessage(mails []entity.NotifiedMail, total int, digest bool) message {
+	summary := fmt.Sprintf("新着メールが %d 件あります", total)
+	if digest {
+		summary = fmt.Sprintf("通知を控えていた間に届いたメールが %d 件あります", total)
+	}
+	msg := message{
+		Text: summary,
+		Blocks: []block{{
+			Type: "header",
+			Text: &text{Type: "plain_text", Text: summary},
+		}},
+	}
+
+	var mentions []string
+	for _, mail := range mails {
+		for _, mention := range mail.Mentions {
+			if formatted := formatMention(mention); !contains(mentions, formatted) {
+				mentions = append(mentions, formatted)
+			}
+		}
+	}
+	if len(mentions) > 0 {
+		// Mentions only notify from mrkdwn text, not from the header block.
+		msg.Text = strings.Join(mentions, " ") + " " + summary
+		msg.Blocks = append(msg.Blocks, block{
+			Type: "section",
+			Text: &text{Type: "mrkdwn", Text: strings.Join(mentions, " ")},
+		})
+	}
+
+	for _, mail := range mails {
+		subject := escape(mail.Subject)
+		if subject == "" {
+			subject = "(件名なし)"
+		}
+		if mail.URL != "" {
+			subject = fmt.Sprintf("<%s|%s>", mail.URL, subject)
+		}
+
+		body := fmt.Sprintf("*%s*\n%s", subject, escape(mail.From))
+		if snippet := truncate(mail.Snippet, snippetLength); snippet != "" {
+			body += "\n>" + strings.ReplaceAll(escape(snippet), "\n", "\n>")
+		}
+		msg.Blocks = append(msg.Blocks, block{
+			Type: "section",
+			Text: &text{Type: "mrkdwn", Text: body},
+		})
+
+		var context []string
+		if mail.Recipient != "" {
+			context = append(context, "宛先: "+escape(mail.Recipient))
+		}
+		if !mail.Date.IsZero() {
+			context = append(context, fmt.Sprintf("<!date^%d^{date_short_pretty} {time}|%s>",
+				mail.Date.Unix(), mail.Date.Format(time.RFC3339)))
+		}
+		if len(context) > 0 {
+			msg.Blocks = append(msg.Blocks, block{
+				Type:     "context",
+				Elements: []text{{Type: "mrkdwn", Text: strings.Join(context, " ・ ")}},
+			})
+		}
+	}
+	return msg
+}
+
+// post retries after the Retry-After Slack returns with 429.
+func (c *Client) post(webhookURL string, msg message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.httpClient.Post(webhookURL, "application/json", bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("failed to send webhook: %w", err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode == http.StatusTooManyRequests && attempt < maxRetries {
+			time.Sleep(retryAfter(resp.Header))
+			continue
+		}
+		if resp.StatusCode >= 400 {
+			return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+		}
+		return nil
+	}
+}
+
+func retryAfter(header http.Header) time.Duration {
+	if seconds, err := strconv.Atoi(header.Get("Retry-After")); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	return time.Second
+}
+
+// escape escapes the characters Slack's mrkdwn treats as control characters.
+func escape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+func truncate(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit-1]) + "…"
+}
+
+// formatMention accepts user (U…/W…) and user group (S…) IDs and
+// here/channel/everyone; preformatted mentions are used as given.
+func formatMention(mention string) string {
+	switch {
+	case strings.HasPrefix(mention, "<"):
+		return mention
+	case mention == "here" || mention == "channel" || mention == "everyone":
+		return "<!" + mention + ">"
+	case strings.HasPrefix(mention, "S"):
+		return "<!subteam^" + mention + ">"
+	default:
+		return "<@" + mention + ">"
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
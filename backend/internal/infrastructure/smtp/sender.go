@@ -0,0 +1,256 @@
+package smtp
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rikut0904/mailer-backend/internal/domain/entity"
+	"github.com/rikut0904/mailer-backend/internal/domain/repository"
+	mimeparser "github.com/rikut0904/mailer-backend/pkg/mime"
+)
+
+const (
+	dialTimeout = 30 * time.Second
+	// sendTimeout bounds the session setup and each message, so a stalled
+	// relay cannot hold the connection lock forever.
+	sendTimeout = 5 * time.Minute
+	// Connections idle for longer are closed instead of reused; most relays
+	// drop idle sessions after a few minutes.
+	maxIdle = time.Minute
+)
+
+// serverConfig identifies the connection; a settings change forces a redial.
+type serverConfig struct {
+	host     string
+	port     int
+	security string
+	auth     string
+	username string
+	password string
+}
+
+// sender relays mail over SMTP, keeping one authenticated connection open
+// between messages. Messages are sent one at a time over that connection.
+type sender struct {
+	settingsRepo repository.SystemSettingRepository
+	mu           sync.Mutex
+	client       *smtp.Client
+	conn         net.Conn
+	config       serverConfig
+	lastUsed     time.Time
+}
+
+func NewSender(settingsRepo repository.SystemSettingRepository) repository.MailSenderRepository {
+	return &sender{settingsRepo: settingsRepo}
+}
+
+// SendRawEmail returns no provider message ID; relays do not report one.
+func (s *sender) SendRawEmail(outgoing *entity.OutgoingMail) (string, error) {
+	if strings.TrimSpace(outgoing.From) == "" {
+		return "", fmt.Errorf("from is required")
+	}
+	if strings.TrimSpace(outgoing.EnvelopeTo) == "" {
+		return "", fmt.Errorf("recipient is required")
+	}
+
+	settings, err := s.settingsRepo.Get()
+	if err != nil {
+		return "", fmt.Errorf("failed to load SMTP settings: %w", err)
+	}
+	config, err := configFromSettings(settings)
+	if err != nil {
+		return "", err
+	}
+
+	raw, err := mimeparser.BuildRawMessage(outgoing)
+	if err != nil {
+		return "", err
+	}
+
+	envelopeFrom := outgoing.EnvelopeFrom
+	if envelopeFrom == "" {
+		envelopeFrom = outgoing.From
+	}
+	if addr, err := mail.ParseAddress(envelopeFrom); err == nil {
+		envelopeFrom = addr.Address
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	client, err := s.connection(config)
+	if err != nil {
+		return "", classifySendError(err)
+	}
+	if err := deliver(client, envelopeFrom, outgoing.EnvelopeTo, raw); err != nil {
+		// The session state is unknown after a failure; start over next time.
+		s.closeLocked()
+		return "", classifySendError(err)
+	}
+	s.lastUsed = time.Now()
+	return "", nil
+}
+
+// connection returns the open client when it is still usable for config,
+// otherwise dials a new one.
+func (s *sender) connection(config serverConfig) (*smtp.Client, error) {
+	if s.client != nil {
+		if s.config == config && time.Since(s.lastUsed) < maxIdle &&
+			s.conn.SetDeadline(time.Now().Add(sendTimeout)) == nil && s.client.Reset() == nil {
+			return s.client, nil
+		}
+		s.closeLocked()
+	}
+
+	client, conn, err := dial(config)
+	if err != nil {
+		return nil, err
+	}
+	s.client = client
+	s.conn = conn
+	s.config = config
+	s.lastUsed = time.Now()
+	return client, nil
+}
+
+func (s *sender) closeLocked() {
+	if s.client == nil {
+		return
+	}
+	if err := s.client.Quit(); err != nil {
+		s.client.Close()
+	}
+	s.client = nil
+	s.conn = nil
+}
+
+// dial opens an authenticated session. The returned conn carries the deadline
+// of the session; TLS set up later wraps it, so its deadline still applies.
+func dial(config serverConfig) (*smtp.Client, net.Conn, error) {
+	addr := net.JoinHostPort(config.host, strconv.Itoa(config.port))
+	tlsConfig := &tls.Config{ServerName: config.host}
+	dialer := &net.Dialer{Timeout: dialTimeout}
+
+	var conn net.Conn
+	var err error
+	if config.security == entity.SMTPSecurityTLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	if err := conn.SetDeadline(time.Now().Add(sendTimeout)); err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("failed to set SMTP deadline: %w", err)
+	}
+
+	client, err := smtp.NewClient(conn, config.host)
+	if err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+
+	if config.security == entity.SMTPSecurityStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			client.Close()
+			return nil, nil, fmt.Errorf("%s does not support STARTTLS", addr)
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			return nil, nil, fmt.Errorf("STARTTLS failed: %w", err)
+		}
+	}
+
+	if auth := config.smtpAuth(); auth != nil {
+		if err := client.Auth(auth); err != nil {
+			client.Close()
+			return nil, nil, fmt.Errorf("SMTP authentication failed: %w", err)
+		}
+	}
+	return client, conn, nil
+}
+
+func deliver(client *smtp.Client, from, to string, raw []byte) error {
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(raw); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+func configFromSettings(settings *entity.SystemSetting) (serverConfig, error) {
+	config := serverConfig{
+		host:     strings.TrimSpace(settings.SMTPHost),
+		port:     settings.SMTPPort,
+		security: settings.SMTPSecurity,
+		auth:     settings.SMTPAuth,
+		username: settings.SMTPUsername,
+		password: settings.SMTPPassword,
+	}
+	if config.host == "" {
+		return config, fmt.Errorf("SMTP settings are not configured")
+	}
+	if config.security == "" {
+		config.security = entity.SMTPSecurityStartTLS
+	}
+	if config.port == 0 {
+		switch config.security {
+		case entity.SMTPSecurityTLS:
+			config.port = 465
+		case entity.SMTPSecurityNone:
+			config.port = 25
+		default:
+			config.port = 587
+		}
+	}
+	return config, nil
+}
+
+func (c serverConfig) smtpAuth() smtp.Auth {
+	switch c.auth {
+	case entity.SMTPAuthPlain:
+		return smtp.PlainAuth("", c.username, c.password, c.host)
+	case entity.SMTPAuthLogin:
+		return &loginAuth{username: c.username, password: c.password}
+	}
+	return nil
+}
+
+// classifySendError marks 4xx replies and network errors as temporary so the
+// outbox retries them; 5xx replies are final.
+func classifySendError(err error) error {
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) {
+		if protoErr.Code >= 400 && protoErr.Code < 500 {
+			return fmt.Errorf("%w: %v", repository.ErrTemporarySendFailure, err)
+		}
+		return err
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return fmt.Errorf("%w: %v", repository.ErrTemporarySendFailure, err)
+	}
+	return err
+}
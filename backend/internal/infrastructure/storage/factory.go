@@ -0,0 +1,23 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/rikut0904/mailer-backend/internal/domain/entity"
+	"github.com/rikut0904/mailer-backend/internal/domain/repository"
+	awsinfra "github.com/rikut0904/mailer-backend/internal/infrastructure/aws"
+	"github.com/rikut0904/mailer-backend/internal/infrastructure/filesystem"
+)
+
+func NewFactory(localStorageRoot string) repository.MailStorageFactory {
+	return func(domain *entity.S3Domain) (repository.MailStorageRepository, error) {
+		switch domain.StorageType {
+		case "", entity.StorageTypeS3:
+			return awsinfra.NewS3ClientFromDomain(domain)
+		case entity.StorageTypeFilesystem:
+			return filesystem.NewMailStorage(localStorageRoot, domain.Bucket)
+		default:
+			return nil, fmt.Errorf("unsupported storage type: %s", domain.StorageType)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package teams
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rikut0904/mailer-backend/internal/domain/entity"
+	"github.com/rikut0904/mailer-backend/internal/domain/repository"
+)
+
+const (
+	// mailsPerCard keeps cards under the 28 KB Teams message limit.
+	mailsPerCard  = 10
+	snippetLength = 200
+	maxRetries    = 3
+)
+
+// Client posts notifications to Teams incoming webhooks and Workflows as
+// Adaptive Cards.
+type Client struct {
+	httpClient *http.Client
+}
+
+func NewClient() repository.Notifier {
+	return &Client{httpClient: &http.Client{Timeout: 10 * time.Second}}
+}
+
+type message struct {
+	Type        string       `json:"type"`
+	Attachments []attachment `json:"attachments"`
+}
+
+type attachment struct {
+	ContentType string       `json:"contentType"`
+	Content     adaptiveCard `json:"content"`
+}
+
+type adaptiveCard struct {
+	Schema  string           `json:"$schema"`
+	Type    string           `json:"type"`
+	Version string           `json:"version"`
+	Body    []map[string]any `json:"body"`
+	MSTeams *msTeams         `json:"msteams,omitempty"`
+}
+
+type msTeams struct {
+	Entities []mentionEntity `json:"entities"`
+}
+
+type mentionEntity struct {
+	Type      string `json:"type"`
+	Text      string `json:"text"`
+	Mentioned struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"mentioned"`
+}
+
+func (c *Client) Notify(webhookURL string, notification *entity.MailNotification) error {
+	mails := notification.Mails
+	for start := 0; start < len(mails); start += mailsPerCard {
+		chunk := mails[start:min(start+mailsPerCard, len(mails))]
+		if err := c.post(webhookURL, newMessage(chunk, notification.Total, notification.Digest)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newMessage(mails []entity.NotifiedMail, total int, digest bool) message {
+	summary := fmt.Sprintf("新着メールが %d 件あります", total)
+	if digest {
+		summary = fmt.Sprintf("通知を控えていた間に届いたメールが %d 件あります", total)
+	}
+	body := []map[string]any{{
+		"type":   "TextBlock",
+		"text":   summary,
+		"size":   "Medium",
+		"weight": "Bolder",
+		"wrap":   true,
+	}}
+
+	// Mentions are user principal names or Entra ID object IDs.
+	var entities []mentionEntity
+	var mentionTexts []string
+	seen := make(map[string]bool)
+	for _, mail := range mails {
+		for _, mention := range mail.Mentions {
+			if seen[mention] {
+				continue
+			}
+			seen[mention] = true
+			entity := mentionEntity{Type: "mention", Text: "<at>" + mention + "</at>"}
+			entity.Mentioned.ID = mention
+			entity.Mentioned.Name = mention
+			entities = append(entities, entity)
+			mentionTexts = append(mentionTexts, entity.Text)
+		}
+	}
+	if len(entities) > 0 {
+		body = append(body, map[string]any{"type": "TextBlock", "text": strings.Join(mentionTexts, " "), "wrap": true})
+	}
+
+	for _, mail := range mails {
+		subject := mail.Subject
+		if subject == "" {
+			subject = "(件名なし)"
+		}
+
+		facts := []map[string]string{{"title": "From", "value": mail.From}}
+		if mail.Recipient != "" {
+			facts = append(facts, map[string]string{"title": "To", "value": mail.Recipient})
+		}
+		if !mail.Date.IsZero() {
+			facts = append(facts, map[string]string{"title": "Date", "value": mail.Date.Format("2006-01-02 15:04")})
+		}
+
+		items := []map[string]any{
+			{"type": "TextBlock", "text": subject, "weight": "Bolder", "wrap": true},
+			{"type": "FactSet", "facts": facts},
+		}
+		if snippet := truncate(mail.Snippet, snippetLength); snippet != "" {
+			items = append(items, map[string]any{"type": "TextBlock", "text": snippet, "wrap": true, "isSubtle": true})
+		}
+
+		container := map[string]any{
+			"type":      "Container",
+			"separator": true,
+			"items":     items,
+		}
+		if mail.URL != "" {
+			container["selectAction"] = map[string]any{"type": "Action.OpenUrl", "url": mail.URL}
+		}
+		body = append(body, container)
+	}
+
+	return message{
+		Type: "message",
+		Attachments: []attachment{{
+			ContentType: "application/vnd.microsoft.card.adaptive",
+			Content: adaptiveCard{
+				Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+				Type:    "AdaptiveCard",
+				Version: "1.4",
+				Body:    body,
+				MSTeams: teamsEntities(entities),
+			},
+		}},
+	}
+}
+
+// post retries after the Retry-After Teams returns with 429.
+func (c *Client) post(webhookURL string, msg message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.httpClient.Post(webhookURL, "application/json", bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("failed to send webhook: %w", err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode == http.StatusTooManyRequests && attempt < maxRetries {
+			time.Sleep(retryAfter(resp.Header))
+			continue
+		}
+		if resp.StatusCode >= 400 {
+			return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+		}
+		return nil
+	}
+}
+
+func retryAfter(header http.Header) time.Duration {
+	if seconds, err := strconv.Atoi(header.Get("Retry-After")); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	return time.Second
+}
+
+func truncate(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit-1]) + "…"
+}
+
+func teamsEntities(entities []mentionEntity) *msTeams {
+	if len(entities) == 0 {
+		return nil
+	}
+	return &msTeams{Entities: entities}
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	awsinfra "github.com/rikut0904/mailer-backend/internal/infrastructure/aws"
+	senduc "github.com/rikut0904/mailer-backend/internal/usecase/send"
+)
+
+type DeliveryFeedbackHandler struct {
+	feedbackUC      *senduc.DeliveryFeedbackUseCase
+	snsVerifier     *awsinfra.SNSVerifier
+	verifySignature bool
+}
+
+func NewDeliveryFeedbackHandler(
+	feedbackUC *senduc.DeliveryFeedbackUseCase,
+	snsVerifier *awsinfra.SNSVerifier,
+	verifySignature bool,
+) *DeliveryFeedbackHandler {
+	return &DeliveryFeedbackHandler{
+		feedbackUC:      feedbackUC,
+		snsVerifier:     snsVerifier,
+		verifySignature: verifySignature,
+	}
+}
+
+// ReceiveSESEvent consumes SES bounce, complaint and delivery notifications
+// delivered through SNS.
+func (h *DeliveryFeedbackHandler) ReceiveSESEvent(c echo.Context) error {
+	body, err := io.ReadAll(io.LimitReader(c.Request().Body, maxInboundBodySize))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "failed to read request body"})
+	}
+
+	payload, status, err := unwrapSNS(body, h.snsVerifier, h.verifySignature)
+	if err != nil {
+		return c.JSON(status, map[string]string{"error": err.Error()})
+	}
+	if payload == nil {
+		return c.JSON(http.StatusOK, map[string]string{"status": "ok"})
+	}
+
+	updated, err := h.feedbackUC.Handle(payload)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":  "ok",
+		"updated": updated,
+	})
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/google/uuid"
@@ -19,11 +20,13 @@ func NewDomainHandler(domainRepo repository.S3DomainRepository) *DomainHandler {
 
 type DomainRequest struct {
 	Name        string `json:"name"`
+	StorageType string `json:"storage_type"`
 	Bucket      string `json:"bucket"`
 	Region      string `json:"region"`
 	AccessKeyID string `json:"access_key_id"`
 	SecretKey   string `json:"secret_key"`
 	Endpoint    string `json:"endpoint"`
+	InsecureTLS bool   `json:"insecure_tls"`
 }
 
 func (h *DomainHandler) ListDomains(c echo.Context) error {
@@ -39,15 +42,21 @@ func (h *DomainHandler) CreateDomain(c echo.Context) error {
 	if err := c.Bind(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
 	}
+	storageType, err := normalizeStorageType(req.StorageType)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
 
 	domain := &entity.S3Domain{
 		ID:          uuid.NewString(),
 		Name:        req.Name,
+		StorageType: storageType,
 		Bucket:      req.Bucket,
 		Region:      req.Region,
 		AccessKeyID: req.AccessKeyID,
 		SecretKey:   req.SecretKey,
 		Endpoint:    req.Endpoint,
+		InsecureTLS: req.InsecureTLS,
 	}
 
 	if err := h.domainRepo.Create(domain); err != nil {
@@ -67,6 +76,10 @@ func (h *DomainHandler) UpdateDomain(c echo.Context) error {
 	if err := c.Bind(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
 	}
+	storageType, err := normalizeStorageType(req.StorageType)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
 
 	domain, err := h.domainRepo.GetByID(id)
 	if err != nil {
@@ -74,11 +87,13 @@ func (h *DomainHandler) UpdateDomain(c echo.Context) error {
 	}
 
 	domain.Name = req.Name
+	domain.StorageType = storageType
 	domain.Bucket = req.Bucket
 	domain.Region = req.Region
 	domain.AccessKeyID = req.AccessKeyID
 	domain.SecretKey = req.SecretKey
 	domain.Endpoint = req.Endpoint
+	domain.InsecureTLS = req.InsecureTLS
 
 	if err := h.domainRepo.Update(domain); err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
@@ -99,3 +114,13 @@ func (h *DomainHandler) DeleteDomain(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, map[string]string{"status": "deleted"})
 }
+
+func normalizeStorageType(storageType string) (string, error) {
+	switch storageType {
+	case "":
+		return entity.StorageTypeS3, nil
+	case entity.StorageTypeS3, entity.StorageTypeFilesystem:
+		return storageType, nil
+	}
+	return "", fmt.Errorf("unsupported storage_type: %s", storageType)
+}
@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rikut0904/mailer-backend/internal/domain/entity"
+	"github.com/rikut0904/mailer-backend/internal/domain/repository"
+)
+
+func resolveUserDomain(c echo.Context, userSettingRepo repository.UserSettingRepository, domainRepo repository.S3DomainRepository) (*entity.S3Domain, error) {
+	uid, ok := c.Get("uid").(string)
+	if !ok || uid == "" {
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, "unauthorized")
+	}
+
+	setting, err := userSettingRepo.GetByUID(uid)
+	if err == nil && setting.SelectedDomainID != "" {
+		if domain, err := domainRepo.GetByID(setting.SelectedDomainID); err == nil {
+			return domain, nil
+		}
+	}
+
+	domains, err := domainRepo.List()
+	if err != nil {
+		return nil, err
+	}
+	if len(domains) == 0 {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "no s3 domain configured")
+	}
+
+	return &domains[0], nil
+}
@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rikut0904/mailer-backend/internal/domain/repository"
+	draftuc "github.com/rikut0904/mailer-backend/internal/usecase/draft"
+	mailuc "github.com/rikut0904/mailer-backend/internal/usecase/mail"
+)
+
+type DraftHandler struct {
+	draftUC         *draftuc.DraftUseCase
+	getMailsUC      *mailuc.GetMailsUseCase
+	userSettingRepo repository.UserSettingRepository
+	domainRepo      repository.S3DomainRepository
+	storageFactory  repository.MailStorageFactory
+}
+
+func NewDraftHandler(
+	draftUC *draftuc.DraftUseCase,
+	getMailsUC *mailuc.GetMailsUseCase,
+	userSettingRepo repository.UserSettingRepository,
+	domainRepo repository.S3DomainRepository,
+	storageFactory repository.MailStorageFactory,
+) *DraftHandler {
+	return &DraftHandler{
+		draftUC:         draftUC,
+		getMailsUC:      getMailsUC,
+		userSettingRepo: userSettingRepo,
+		domainRepo:      domainRepo,
+		storageFactory:  storageFactory,
+	}
+}
+
+func (h *DraftHandler) List(c echo.Context) error {
+	uid, _ := c.Get("uid").(string)
+
+	drafts, err := h.draftUC.List(uid)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, drafts)
+}
+
+func (h *DraftHandler) Get(c echo.Context) error {
+	uid, _ := c.Get("uid").(string)
+
+	draft, err := h.draftUC.Get(uid, c.Param("id"))
+	if err != nil {
+		return c.JSON(draftErrorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, draft)
+}
+
+func (h *DraftHandler) Create(c echo.Context) error {
+	uid, _ := c.Get("uid").(string)
+
+	var input draftuc.DraftInput
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+
+	draft, err := h.draftUC.Create(uid, &input)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, draft)
+}
+
+// Update replaces the draft content; the compose form calls it on autosave.
+func (h *DraftHandler) Update(c echo.Context) error {
+	uid, _ := c.Get("uid").(string)
+
+	var input draftuc.DraftInput
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+
+	draft, err := h.draftUC.Update(uid, c.Param("id"), &input)
+	if err != nil {
+		return c.JSON(draftErrorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, draft)
+}
+
+func (h *DraftHandler) Delete(c echo.Context) error {
+	uid, _ := c.Get("uid").(string)
+
+	if err := h.draftUC.Delete(uid, c.Param("id")); err != nil {
+		return c.JSON(draftErrorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"status": "deleted"})
+}
+
+// UploadAttachments stores the multipart "attachments" files with the draft.
+func (h *DraftHandler) UploadAttachments(c echo.Context) error {
+	uid, _ := c.Get("uid").(string)
+
+	form, err := parseMultipartUpload(c)
+	if err != nil {
+		return c.JSON(sendErrorStatus(err, http.StatusBadRequest), map[string]string{"error": err.Error()})
+	}
+	files, err := readUploadedAttachments(form)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if len(files) == 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "no attachments uploaded"})
+	}
+
+	draft, err := h.draftUC.AddAttachments(uid, c.Param("id"), files)
+	if err != nil {
+		return c.JSON(draftErrorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, draft)
+}
+
+func (h *DraftHandler) DeleteAttachment(c echo.Context) error {
+	uid, _ := c.Get("uid").(string)
+
+	draft, err := h.draftUC.RemoveAttachment(uid, c.Param("id"), c.Param("attachmentId"))
+	if err != nil {
+		return c.JSON(draftErrorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, draft)
+}
+
+// Send queues the draft like POST /api/send and deletes it. An optional
+// {"send_at"} body schedules it.
+func (h *DraftHandler) Send(c echo.Context) error {
+	uid, _ := c.Get("uid").(string)
+	id := c.Param("id")
+
+	var schedule RescheduleRequest
+	if err := c.Bind(&schedule); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+
+	req, err := h.draftUC.SendRequest(uid, id)
+	if err != nil {
+		return c.JSON(draftErrorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+	req.SendAt = schedule.SendAt
+	if err := validateSendRequest(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if err := resolveAttachmentRefs(c, req, h.getMailsUC, h.userSettingRepo, h.domainRepo, h.storageFactory); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	result, err := h.draftUC.Send(uid, id, req)
+	if err != nil {
+		return c.JSON(sendErrorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusAccepted, result)
+}
+
+func draftErrorStatus(err error, fallback int) int {
+	switch {
+	case errors.Is(err, draftuc.ErrDraftNotFound), errors.Is(err, draftuc.ErrDraftAttachmentNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, draftuc.ErrDraftSending):
+		return http.StatusConflict
+	}
+	return sendErrorStatus(err, fallback)
+}
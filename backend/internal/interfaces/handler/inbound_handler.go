@@ -0,0 +1,196 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rikut0904/mailer-backend/internal/domain/repository"
+	awsinfra "github.com/rikut0904/mailer-backend/internal/infrastructure/aws"
+	mailuc "github.com/rikut0904/mailer-backend/internal/usecase/mail"
+)
+
+const maxInboundBodySize = 1 << 20
+
+type InboundHandler struct {
+	syncMailsUC     *mailuc.SyncMailsUseCase
+	domainRepo      repository.S3DomainRepository
+	storageFactory  repository.MailStorageFactory
+	snsVerifier     *awsinfra.SNSVerifier
+	verifySignature bool
+}
+
+func NewInboundHandler(
+	syncMailsUC *mailuc.SyncMailsUseCase,
+	domainRepo repository.S3DomainRepository,
+	storageFactory repository.MailStorageFactory,
+	snsVerifier *awsinfra.SNSVerifier,
+	verifySignature bool,
+) *InboundHandler {
+	return &InboundHandler{
+		syncMailsUC:     syncMailsUC,
+		domainRepo:      domainRepo,
+		storageFactory:  storageFactory,
+		snsVerifier:     snsVerifier,
+		verifySignature: verifySignature,
+	}
+}
+
+type inboundObjectRef struct {
+	Bucket string
+	Key    string
+}
+
+// inboundEvent accepts a plain {"bucket","key"} body, an S3 event
+// notification, or an SES receipt notification using the S3 action.
+type inboundEvent struct {
+	Bucket  string `json:"bucket"`
+	Key     string `json:"key"`
+	Records []struct {
+		EventName string `json:"eventName"`
+		S3        struct {
+			Bucket struct {
+				Name string `json:"name"`
+			} `json:"bucket"`
+			Object struct {
+				Key string `json:"key"`
+			} `json:"object"`
+		} `json:"s3"`
+	} `json:"Records"`
+	Receipt *struct {
+		Action struct {
+			Type       string `json:"type"`
+			BucketName string `json:"bucketName"`
+			ObjectKey  string `json:"objectKey"`
+		} `json:"action"`
+	} `json:"receipt"`
+}
+
+func (h *InboundHandler) ReceiveMailEvent(c echo.Context) error {
+	body, err := io.ReadAll(io.LimitReader(c.Request().Body, maxInboundBodySize))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "failed to read request body"})
+	}
+
+	payload, status, err := unwrapSNS(body, h.snsVerifier, h.verifySignature)
+	if err != nil {
+		return c.JSON(status, map[string]string{"error": err.Error()})
+	}
+	if payload == nil {
+		return c.JSON(http.StatusOK, map[string]string{"status": "ok"})
+	}
+
+	refs, err := parseInboundObjectRefs(payload)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	var ingested, skipped int
+	for _, ref := range refs {
+		domains, err := h.domainRepo.FindByBucket(ref.Bucket)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
+		if len(domains) == 0 {
+			log.Printf("inbound: no domain configured for bucket %s", ref.Bucket)
+			skipped++
+			continue
+		}
+
+		for i := range domains {
+			storageRepo, err := h.storageFactory(&domains[i])
+			if err != nil {
+				return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			}
+
+			created, err := h.syncMailsUC.Ingest(storageRepo, domains[i].ID, ref.Key)
+			if err != nil {
+				return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			}
+			if created {
+				ingested++
+			} else {
+				skipped++
+			}
+		}
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":   "ok",
+		"ingested": ingested,
+		"skipped":  skipped,
+	})
+}
+
+// unwrapSNS returns the inner payload of an SNS notification, the body itself
+// when it is not an SNS envelope, or nil when the message needs no processing.
+func unwrapSNS(body []byte, verifier *awsinfra.SNSVerifier, verifySignature bool) ([]byte, int, error) {
+	var msg awsinfra.SNSMessage
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return nil, http.StatusBadRequest, fmt.Errorf("invalid request body")
+	}
+	if msg.Type == "" {
+		return body, http.StatusOK, nil
+	}
+
+	if verifySignature {
+		if err := verifier.Verify(&msg); err != nil {
+			return nil, http.StatusForbidden, err
+		}
+	}
+
+	switch msg.Type {
+	case "SubscriptionConfirmation":
+		if err := verifier.ConfirmSubscription(&msg); err != nil {
+			return nil, http.StatusBadGateway, err
+		}
+		log.Printf("sns: confirmed subscription for %s", msg.TopicArn)
+		return nil, http.StatusOK, nil
+	case "UnsubscribeConfirmation":
+		return nil, http.StatusOK, nil
+	case "Notification":
+		return []byte(msg.Message), http.StatusOK, nil
+	default:
+		return nil, http.StatusBadRequest, fmt.Errorf("unsupported SNS message type: %s", msg.Type)
+	}
+}
+
+func parseInboundObjectRefs(payload []byte) ([]inboundObjectRef, error) {
+	var event inboundEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("invalid notification payload")
+	}
+
+	var refs []inboundObjectRef
+	if event.Bucket != "" && event.Key != "" {
+		refs = append(refs, inboundObjectRef{Bucket: event.Bucket, Key: event.Key})
+	}
+
+	for _, record := range event.Records {
+		if !strings.HasPrefix(record.EventName, "ObjectCreated:") {
+			continue
+		}
+		key, err := url.QueryUnescape(record.S3.Object.Key)
+		if err != nil {
+			key = record.S3.Object.Key
+		}
+		refs = append(refs, inboundObjectRef{Bucket: record.S3.Bucket.Name, Key: key})
+	}
+
+	if event.Receipt != nil && event.Receipt.Action.Type == "S3" {
+		refs = append(refs, inboundObjectRef{
+			Bucket: event.Receipt.Action.BucketName,
+			Key:    event.Receipt.Action.ObjectKey,
+		})
+	}
+
+	if len(refs) == 0 {
+		return nil, fmt.Errorf("notification does not reference any object")
+	}
+	return refs, nil
+}
@@ -1,16 +1,21 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
 	"net/http"
+	"os"
+	"path"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/rikut0904/mailer-backend/internal/domain/entity"
 	"github.com/rikut0904/mailer-backend/internal/domain/repository"
-	awsinfra "github.com/rikut0904/mailer-backend/internal/infrastructure/aws"
 	mailuc "github.com/rikut0904/mailer-backend/internal/usecase/mail"
-	mimeparser "github.com/rikut0904/mailer-backend/pkg/mime"
 )
 
 type MailHandler struct {
@@ -20,6 +25,7 @@ type MailHandler struct {
 	syncMailsUC     *mailuc.SyncMailsUseCase
 	userSettingRepo repository.UserSettingRepository
 	domainRepo      repository.S3DomainRepository
+	storageFactory  repository.MailStorageFactory
 }
 
 func NewMailHandler(
@@ -29,6 +35,7 @@ func NewMailHandler(
 	syncMailsUC *mailuc.SyncMailsUseCase,
 	userSettingRepo repository.UserSettingRepository,
 	domainRepo repository.S3DomainRepository,
+	storageFactory repository.MailStorageFactory,
 ) *MailHandler {
 	return &MailHandler{
 		getMailsUC:      getMailsUC,
@@ -37,6 +44,7 @@ func NewMailHandler(
 		syncMailsUC:     syncMailsUC,
 		userSettingRepo: userSettingRepo,
 		domainRepo:      domainRepo,
+		storageFactory:  storageFactory,
 	}
 }
 
@@ -45,12 +53,12 @@ func (h *MailHandler) GetMails(c echo.Context) error {
 	page, _ := strconv.Atoi(c.QueryParam("page"))
 	perPage, _ := strconv.Atoi(c.QueryParam("per_page"))
 
-	domain, storageRepo, err := h.storageForUser(c)
+	domain, err := h.domainForUser(c)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
-	result, err := h.getMailsUC.Execute(storageRepo, domain.ID, recipient, page, perPage)
+	result, err := h.getMailsUC.Execute(domain.ID, recipient, page, perPage)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
@@ -77,6 +85,88 @@ func (h *MailHandler) GetMail(c echo.Context) error {
 	return c.JSON(http.StatusOK, mail)
 }
 
+func (h *MailHandler) GetRawMail(c echo.Context) error {
+	s3Key := c.Param("s3Key")
+	if s3Key == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "s3_key is required"})
+	}
+
+	domain, storageRepo, err := h.storageForUser(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	raw, err := h.getMailsUC.OpenRaw(storageRepo, domain.ID, s3Key)
+	if err != nil {
+		return c.JSON(downloadErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+	defer raw.Close()
+
+	filename := path.Base(s3Key)
+	if !strings.HasSuffix(strings.ToLower(filename), ".eml") {
+		filename += ".eml"
+	}
+	return serveDownload(c, filename, "message/rfc822", raw)
+}
+
+func (h *MailHandler) GetAttachment(c echo.Context) error {
+	s3Key := c.Param("s3Key")
+	index, err := strconv.Atoi(c.Param("index"))
+	if s3Key == "" || err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "s3_key and a numeric index are required"})
+	}
+
+	domain, storageRepo, err := h.storageForUser(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	// The decoded attachment is spooled to a temporary file so Range
+	// requests can be served without holding it in memory.
+	spool, err := os.CreateTemp("", "attachment-*")
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	defer os.Remove(spool.Name())
+	defer spool.Close()
+
+	attachment, err := h.getMailsUC.WriteAttachment(storageRepo, domain.ID, s3Key, index, spool)
+	if err != nil {
+		return c.JSON(downloadErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+
+	filename := attachment.Filename
+	if filename == "" {
+		filename = fmt.Sprintf("attachment-%d", index+1)
+	}
+	contentType := attachment.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return serveDownload(c, filename, contentType, spool)
+}
+
+// downloadErrorStatus answers 404 only for a missing mail, object or
+// attachment; storage and credential failures are server errors.
+func downloadErrorStatus(err error) int {
+	if errors.Is(err, mailuc.ErrMailNotFound) || errors.Is(err, mailuc.ErrAttachmentNotFound) || errors.Is(err, repository.ErrObjectNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+// serveDownload uses http.ServeContent so Range and If-Range requests work;
+// only the requested range of content is read.
+func serveDownload(c echo.Context, filename, contentType string, content io.ReadSeeker) error {
+	header := c.Response().Header()
+	header.Set(echo.HeaderContentType, contentType)
+	header.Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	header.Set("X-Content-Type-Options", "nosniff")
+
+	http.ServeContent(c.Response(), c.Request(), filename, time.Time{}, content)
+	return nil
+}
+
 type UpdateReadRequest struct {
 	IsRead bool `json:"is_read"`
 }
@@ -88,7 +178,7 @@ func (h *MailHandler) UpdateReadStatus(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
 	}
 
-	domain, _, err := h.storageForUser(c)
+	domain, err := h.domainForUser(c)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
@@ -111,7 +201,7 @@ func (h *MailHandler) UpdateStarStatus(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
 	}
 
-	domain, _, err := h.storageForUser(c)
+	domain, err := h.domainForUser(c)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
@@ -156,33 +246,19 @@ func (h *MailHandler) SyncMails(c echo.Context) error {
 }
 
 func (h *MailHandler) GetRecipients(c echo.Context) error {
-	_, storageRepo, err := h.storageForUser(c)
+	domain, err := h.domainForUser(c)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
-	keys, _, err := storageRepo.ListKeys("", nil, 200)
+	addresses, err := h.getMailsUC.ListRecipients(domain.ID)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
 	unique := map[string]struct{}{}
-	for _, key := range keys {
-		if strings.HasSuffix(key, "/") {
-			continue
-		}
-
-		raw, err := storageRepo.GetObject(key)
-		if err != nil {
-			continue
-		}
-
-		parsed, err := mimeparser.Parse(raw, key)
-		if err != nil {
-			continue
-		}
-
-		for _, addr := range splitRecipients(parsed.To) {
+	for _, to := range addresses {
+		for _, addr := range splitRecipients(to) {
 			if addr != "" {
 				unique[addr] = struct{}{}
 			}
@@ -212,39 +288,18 @@ func splitRecipients(to string) []string {
 }
 
 func (h *MailHandler) storageForUser(c echo.Context) (*entity.S3Domain, repository.MailStorageRepository, error) {
-	uid, ok := c.Get("uid").(string)
-	if !ok || uid == "" {
-		return nil, nil, echo.NewHTTPError(http.StatusUnauthorized, "unauthorized")
-	}
-	role, _ := c.Get("role").(string)
-
-	domain, err := h.resolveDomain(uid, role)
+	domain, err := h.domainForUser(c)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	storageRepo, err := awsinfra.NewS3ClientFromDomain(domain)
+	storageRepo, err := h.storageFactory(domain)
 	if err != nil {
 		return nil, nil, err
 	}
 	return domain, storageRepo, nil
 }
 
-func (h *MailHandler) resolveDomain(uid string, role string) (*entity.S3Domain, error) {
-	setting, err := h.userSettingRepo.GetByUID(uid)
-	if err == nil && setting.SelectedDomainID != "" {
-		if domain, err := h.domainRepo.GetByID(setting.SelectedDomainID); err == nil {
-			return domain, nil
-		}
-	}
-
-	domains, err := h.domainRepo.List()
-	if err != nil {
-		return nil, err
-	}
-	if len(domains) == 0 {
-		return nil, echo.NewHTTPError(http.StatusBadRequest, "no s3 domain configured")
-	}
-
-	return &domains[0], nil
+func (h *MailHandler) domainForUser(c echo.Context) (*entity.S3Domain, error) {
+	return resolveUserDomain(c, h.userSettingRepo, h.domainRepo)
 }
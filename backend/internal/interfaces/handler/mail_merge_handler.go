@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rikut0904/mailer-backend/internal/domain/entity"
+	"github.com/rikut0904/mailer-backend/internal/domain/repository"
+	mailuc "github.com/rikut0904/mailer-backend/internal/usecase/mail"
+	mergeuc "github.com/rikut0904/mailer-backend/internal/usecase/mailmerge"
+	senduc "github.com/rikut0904/mailer-backend/internal/usecase/send"
+)
+
+type MailMergeHandler struct {
+	mergeUC         *mergeuc.MailMergeUseCase
+	sendMailUC      *senduc.SendMailUseCase
+	getMailsUC      *mailuc.GetMailsUseCase
+	userSettingRepo repository.UserSettingRepository
+	domainRepo      repository.S3DomainRepository
+	storageFactory  repository.MailStorageFactory
+}
+
+func NewMailMergeHandler(
+	mergeUC *mergeuc.MailMergeUseCase,
+	sendMailUC *senduc.SendMailUseCase,
+	getMailsUC *mailuc.GetMailsUseCase,
+	userSettingRepo repository.UserSettingRepository,
+	domainRepo repository.S3DomainRepository,
+	storageFactory repository.MailStorageFactory,
+) *MailMergeHandler {
+	return &MailMergeHandler{
+		mergeUC:         mergeUC,
+		sendMailUC:      sendMailUC,
+		getMailsUC:      getMailsUC,
+		userSettingRepo: userSettingRepo,
+		domainRepo:      domainRepo,
+		storageFactory:  storageFactory,
+	}
+}
+
+// Create accepts multipart/form-data with the request JSON in "payload", the
+// recipient list in "csv" and shared files in "attachments". Progress is
+// reported by GET /api/send/:batchId like any other send.
+func (h *MailMergeHandler) Create(c echo.Context) error {
+	form, err := parseMultipartUpload(c)
+	if err != nil {
+		return c.JSON(sendErrorStatus(err, http.StatusBadRequest), map[string]string{"error": err.Error()})
+	}
+
+	var req senduc.SendRequest
+	if err := json.Unmarshal([]byte(c.FormValue("payload")), &req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid payload field"})
+	}
+	req.UID, _ = c.Get("uid").(string)
+
+	csvFiles := form.File["csv"]
+	if len(csvFiles) != 1 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "exactly one csv file is required"})
+	}
+	csvFile, err := csvFiles[0].Open()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "failed to read csv file"})
+	}
+	defer csvFile.Close()
+
+	if req.Attachments, err = readUploadedAttachments(form); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if err := resolveAttachmentRefs(c, &req, h.getMailsUC, h.userSettingRepo, h.domainRepo, h.storageFactory); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	result, err := h.mergeUC.Execute(&req, csvFile)
+	if err != nil {
+		return c.JSON(mergeErrorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusAccepted, result)
+}
+
+// Report downloads the row-by-row result of a merge as CSV.
+func (h *MailMergeHandler) Report(c echo.Context) error {
+	status, err := h.sendMailUC.Status(c.Param("batchId"))
+	if err != nil {
+		return c.JSON(sendErrorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+	uid, _ := c.Get("uid").(string)
+	if (status.UID != uid && !isAdmin(c)) || status.Kind != entity.OutboxBatchKindMerge {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": senduc.ErrSendNotFound.Error()})
+	}
+
+	var buf bytes.Buffer
+	if err := h.mergeUC.WriteReport(&buf, status); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return serveDownload(c, fmt.Sprintf("merge-%s.csv", status.ID), "text/csv; charset=utf-8", bytes.NewReader(buf.Bytes()))
+}
+
+func mergeErrorStatus(err error, fallback int) int {
+	if errors.Is(err, mergeuc.ErrInvalidCSV) {
+		return http.StatusBadRequest
+	}
+	return sendErrorStatus(err, fallback)
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	templateuc "github.com/rikut0904/mailer-backend/internal/usecase/mailtemplate"
+)
+
+type MailTemplateHandler struct {
+	templateUC *templateuc.MailTemplateUseCase
+}
+
+func NewMailTemplateHandler(templateUC *templateuc.MailTemplateUseCase) *MailTemplateHandler {
+	return &MailTemplateHandler{templateUC: templateUC}
+}
+
+func (h *MailTemplateHandler) List(c echo.Context) error {
+	uid, _ := c.Get("uid").(string)
+
+	templates, err := h.templateUC.List(uid)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, templates)
+}
+
+func (h *MailTemplateHandler) Get(c echo.Context) error {
+	uid, _ := c.Get("uid").(string)
+
+	template, err := h.templateUC.Get(uid, c.Param("id"))
+	if err != nil {
+		return c.JSON(sendErrorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, template)
+}
+
+func (h *MailTemplateHandler) Create(c echo.Context) error {
+	uid, _ := c.Get("uid").(string)
+
+	var input templateuc.TemplateInput
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+
+	template, err := h.templateUC.Create(uid, &input)
+	if err != nil {
+		return c.JSON(sendErrorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, template)
+}
+
+func (h *MailTemplateHandler) Update(c echo.Context) error {
+	uid, _ := c.Get("uid").(string)
+
+	var input templateuc.TemplateInput
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+
+	template, err := h.templateUC.Update(uid, c.Param("id"), &input)
+	if err != nil {
+		return c.JSON(sendErrorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, template)
+}
+
+func (h *MailTemplateHandler) Delete(c echo.Context) error {
+	uid, _ := c.Get("uid").(string)
+
+	if err := h.templateUC.Delete(uid, c.Param("id")); err != nil {
+		return c.JSON(sendErrorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"status": "deleted"})
+}
@@ -1,43 +1,357 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
+	"github.com/rikut0904/mailer-backend/internal/domain/entity"
+	"github.com/rikut0904/mailer-backend/internal/domain/repository"
+	mailuc "github.com/rikut0904/mailer-backend/internal/usecase/mail"
+	templateuc "github.com/rikut0904/mailer-backend/internal/usecase/mailtemplate"
 	senduc "github.com/rikut0904/mailer-backend/internal/usecase/send"
 )
 
+const maxMultipartMemory = 32 << 20
+
 type SendHandler struct {
-	sendMailUC *senduc.SendMailUseCase
+	sendMailUC      *senduc.SendMailUseCase
+	templateUC      *templateuc.MailTemplateUseCase
+	getMailsUC      *mailuc.GetMailsUseCase
+	userSettingRepo repository.UserSettingRepository
+	domainRepo      repository.S3DomainRepository
+	storageFactory  repository.MailStorageFactory
 }
 
-func NewSendHandler(sendMailUC *senduc.SendMailUseCase) *SendHandler {
-	return &SendHandler{sendMailUC: sendMailUC}
+func NewSendHandler(
+	sendMailUC *senduc.SendMailUseCase,
+	templateUC *templateuc.MailTemplateUseCase,
+	getMailsUC *mailuc.GetMailsUseCase,
+	userSettingRepo repository.UserSettingRepository,
+	domainRepo repository.S3DomainRepository,
+	storageFactory repository.MailStorageFactory,
+) *SendHandler {
+	return &SendHandler{
+		sendMailUC:      sendMailUC,
+		templateUC:      templateUC,
+		getMailsUC:      getMailsUC,
+		userSettingRepo: userSettingRepo,
+		domainRepo:      domainRepo,
+		storageFactory:  storageFactory,
+	}
 }
 
+// SendMail accepts either a JSON SendRequest or multipart/form-data with the
+// request JSON in the "payload" field and files in "attachments".
 func (h *SendHandler) SendMail(c echo.Context) error {
-	var req senduc.SendRequest
+	req, err := h.bindSendRequest(c)
+	if err != nil {
+		return c.JSON(sendErrorStatus(err, http.StatusBadRequest), map[string]string{"error": err.Error()})
+	}
+
+	if err := h.templateUC.Apply(req); err != nil {
+		return c.JSON(sendErrorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+	if err := validateSendRequest(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if req.SendType == "" {
+		req.SendType = "new"
+	}
+
+	if err := h.resolveAttachmentRefs(c, req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	result, err := h.sendMailUC.Execute(req)
+	if err != nil {
+		return c.JSON(sendErrorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusAccepted, result)
+}
+
+// PreviewMail renders a send request, including its template and the
+// management code footer, without sending it.
+func (h *SendHandler) PreviewMail(c echo.Context) error {
+	req, err := h.bindSendRequest(c)
+	if err != nil {
+		return c.JSON(sendErrorStatus(err, http.StatusBadRequest), map[string]string{"error": err.Error()})
+	}
+
+	if err := h.templateUC.Apply(req); err != nil {
+		return c.JSON(sendErrorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+	if err := h.resolveAttachmentRefs(c, req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	preview, err := h.sendMailUC.Preview(req)
+	if err != nil {
+		return c.JSON(sendErrorStatus(err, http.StatusBadRequest), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, preview)
+}
+
+type RescheduleRequest struct {
+	SendAt *time.Time `json:"send_at"`
+}
+
+// GetSendStatus returns the per-recipient delivery state of a queued send.
+func (h *SendHandler) GetSendStatus(c echo.Context) error {
+	status, err := h.authorizeBatch(c)
+	if err != nil {
+		return c.JSON(sendErrorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, status)
+}
+
+// ListScheduled returns the caller's sends that have not been released yet.
+func (h *SendHandler) ListScheduled(c echo.Context) error {
+	uid, _ := c.Get("uid").(string)
+
+	scheduled, err := h.sendMailUC.ListScheduled(uid)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, scheduled)
+}
+
+func (h *SendHandler) UpdateScheduled(c echo.Context) error {
+	current, err := h.authorizeBatch(c)
+	if err != nil {
+		return c.JSON(sendErrorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+
+	req, err := h.bindSendRequest(c)
+	if err != nil {
+		return c.JSON(sendErrorStatus(err, http.StatusBadRequest), map[string]string{"error": err.Error()})
+	}
+	if err := h.templateUC.Apply(req); err != nil {
+		return c.JSON(sendErrorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+	if err := validateSendRequest(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if err := h.resolveAttachmentRefs(c, req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	status, err := h.sendMailUC.Update(current.ID, req)
+	if err != nil {
+		return c.JSON(sendErrorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, status)
+}
+
+func (h *SendHandler) Reschedule(c echo.Context) error {
+	current, err := h.authorizeBatch(c)
+	if err != nil {
+		return c.JSON(sendErrorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+
+	var req RescheduleRequest
 	if err := c.Bind(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
 	}
 
-	if len(req.To) == 0 {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "at least one recipient is required"})
+	status, err := h.sendMailUC.Reschedule(current.ID, req.SendAt)
+	if err != nil {
+		return c.JSON(sendErrorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, status)
+}
+
+// CancelSend cancels a send that is still within its undo window or schedule.
+func (h *SendHandler) CancelSend(c echo.Context) error {
+	current, err := h.authorizeBatch(c)
+	if err != nil {
+		return c.JSON(sendErrorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+
+	if err := h.sendMailUC.Cancel(current.ID); err != nil {
+		return c.JSON(sendErrorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"status": "canceled"})
+}
+
+// authorizeBatch loads the batch in the path; other users' batches are
+// reported as missing unless the caller is an admin.
+func (h *SendHandler) authorizeBatch(c echo.Context) (*senduc.SendStatus, error) {
+	status, err := h.sendMailUC.Status(c.Param("batchId"))
+	if err != nil {
+		return nil, err
+	}
+
+	uid, _ := c.Get("uid").(string)
+	if status.UID != uid && !isAdmin(c) {
+		return nil, senduc.ErrSendNotFound
+	}
+	return status, nil
+}
+
+func (h *SendHandler) bindSendRequest(c echo.Context) (*senduc.SendRequest, error) {
+	var req senduc.SendRequest
+	req.UID, _ = c.Get("uid").(string)
+
+	contentType := c.Request().Header.Get(echo.HeaderContentType)
+	if !strings.HasPrefix(contentType, echo.MIMEMultipartForm) {
+		if err := c.Bind(&req); err != nil {
+			return nil, fmt.Errorf("invalid request body")
+		}
+		return &req, nil
+	}
+
+	form, err := parseMultipartUpload(c)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(c.FormValue("payload")), &req); err != nil {
+		return nil, fmt.Errorf("invalid payload field")
+	}
+
+	attachments, err := readUploadedAttachments(form)
+	if err != nil {
+		return nil, err
+	}
+	req.Attachments = attachments
+
+	return &req, nil
+}
+
+func (h *SendHandler) resolveAttachmentRefs(c echo.Context, req *senduc.SendRequest) error {
+	return resolveAttachmentRefs(c, req, h.getMailsUC, h.userSettingRepo, h.domainRepo, h.storageFactory)
+}
+
+func validateSendRequest(req *senduc.SendRequest) error {
+	if len(req.To)+len(req.Cc)+len(req.Bcc) == 0 {
+		return fmt.Errorf("at least one recipient is required")
 	}
 	if req.Subject == "" {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "subject is required"})
+		return fmt.Errorf("subject is required")
 	}
 	if req.Body == "" {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "body is required"})
+		return fmt.Errorf("body is required")
 	}
-	if req.SendType == "" {
-		req.SendType = "new"
+	return nil
+}
+
+// parseMultipartUpload parses a multipart body capped at the SES message limit.
+func parseMultipartUpload(c echo.Context) (*multipart.Form, error) {
+	c.Request().Body = http.MaxBytesReader(c.Response(), c.Request().Body, repository.MaxRawMessageSize)
+	if err := c.Request().ParseMultipartForm(maxMultipartMemory); err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return nil, fmt.Errorf("%w: upload is larger than %d MB", repository.ErrMessageTooLarge, repository.MaxRawMessageSize/(1024*1024))
+		}
+		return nil, fmt.Errorf("invalid multipart body")
+	}
+	return c.Request().MultipartForm, nil
+}
+
+func readUploadedAttachments(form *multipart.Form) ([]entity.Attachment, error) {
+	var attachments []entity.Attachment
+	for _, fh := range form.File["attachments"] {
+		file, err := fh.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read attachment %s", fh.Filename)
+		}
+		content, err := io.ReadAll(file)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read attachment %s", fh.Filename)
+		}
+
+		fileType := fh.Header.Get(echo.HeaderContentType)
+		if fileType == "" {
+			fileType = http.DetectContentType(content)
+		}
+		attachments = append(attachments, entity.Attachment{
+			Filename:    fh.Filename,
+			ContentType: fileType,
+			Size:        len(content),
+			Content:     content,
+		})
 	}
+	return attachments, nil
+}
 
-	result, err := h.sendMailUC.Execute(&req)
+func resolveAttachmentRefs(
+	c echo.Context,
+	req *senduc.SendRequest,
+	getMailsUC *mailuc.GetMailsUseCase,
+	userSettingRepo repository.UserSettingRepository,
+	domainRepo repository.S3DomainRepository,
+	storageFactory repository.MailStorageFactory,
+) error {
+	if len(req.AttachmentRefs) == 0 {
+		return nil
+	}
+
+	domain, err := resolveUserDomain(c, userSettingRepo, domainRepo)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return err
+	}
+	storageRepo, err := storageFactory(domain)
+	if err != nil {
+		return err
 	}
 
-	return c.JSON(http.StatusOK, result)
+	for _, ref := range req.AttachmentRefs {
+		attachments, err := getMailsUC.GetAttachments(storageRepo, domain.ID, ref.S3Key)
+		if err != nil {
+			return fmt.Errorf("failed to load attachments of %s: %w", ref.S3Key, err)
+		}
+
+		if len(ref.Indexes) == 0 {
+			req.Attachments = append(req.Attachments, attachments...)
+			continue
+		}
+		for _, idx := range ref.Indexes {
+			if idx < 0 || idx >= len(attachments) {
+				return fmt.Errorf("attachment %d of %s: %w", idx, ref.S3Key, mailuc.ErrAttachmentNotFound)
+			}
+			req.Attachments = append(req.Attachments, attachments[idx])
+		}
+	}
+	return nil
+}
+
+func sendErrorStatus(err error, fallback int) int {
+	switch {
+	case errors.Is(err, repository.ErrMessageTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, senduc.ErrSendNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, senduc.ErrSendNotPending), errors.Is(err, senduc.ErrSendNotEditable):
+		return http.StatusConflict
+	case errors.Is(err, senduc.ErrRecipientSuppressed):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, senduc.ErrSenderNotAllowed):
+		return http.StatusForbidden
+	case errors.Is(err, senduc.ErrSenderNotVerified):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, senduc.ErrSenderCheckFailed):
+		return http.StatusBadGateway
+	case errors.Is(err, templateuc.ErrTemplateNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, templateuc.ErrTemplateInvalid):
+		return http.StatusBadRequest
+	case errors.Is(err, templateuc.ErrTemplateRender):
+		return http.StatusUnprocessableEntity
+	}
+	return fallback
 }
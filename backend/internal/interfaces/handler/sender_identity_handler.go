@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/mail"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/rikut0904/mailer-backend/internal/domain/entity"
+	"github.com/rikut0904/mailer-backend/internal/domain/repository"
+)
+
+type SenderIdentityHandler struct {
+	identityRepo repository.SenderIdentityRepository
+}
+
+func NewSenderIdentityHandler(identityRepo repository.SenderIdentityRepository) *SenderIdentityHandler {
+	return &SenderIdentityHandler{identityRepo: identityRepo}
+}
+
+type SenderIdentityRequest struct {
+	Address          string   `json:"address"`
+	DisplayName      string   `json:"display_name"`
+	SignatureText    string   `json:"signature_text"`
+	SignatureHTML    string   `json:"signature_html"`
+	AllowedUIDs      []string `json:"allowed_uids"`
+	OmitCodeFromBody bool     `json:"omit_code_from_body"`
+	ReplyDomain      string   `json:"reply_domain"`
+	ReturnPath       string   `json:"return_path"`
+}
+
+// List returns every identity to admins and the usable ones to other users.
+func (h *SenderIdentityHandler) List(c echo.Context) error {
+	identities, err := h.identityRepo.List()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	if isAdmin(c) {
+		return c.JSON(http.StatusOK, identities)
+	}
+
+	uid, _ := c.Get("uid").(string)
+	usable := make([]entity.SenderIdentity, 0, len(identities))
+	for _, identity := range identities {
+		if identity.AllowsUser(uid) {
+			identity.AllowedUIDs = nil
+			usable = append(usable, identity)
+		}
+	}
+	return c.JSON(http.StatusOK, usable)
+}
+
+func (h *SenderIdentityHandler) Create(c echo.Context) error {
+	if !isAdmin(c) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "forbidden"})
+	}
+
+	var req SenderIdentityRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	if err := req.validate(); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	identity := &entity.SenderIdentity{ID: uuid.NewString()}
+	req.apply(identity)
+
+	if err := h.identityRepo.Create(identity); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, identity)
+}
+
+func (h *SenderIdentityHandler) Update(c echo.Context) error {
+	if !isAdmin(c) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "forbidden"})
+	}
+
+	id := c.Param("id")
+	if id == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "id is required"})
+	}
+
+	var req SenderIdentityRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	if err := req.validate(); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	identity, err := h.identityRepo.GetByID(id)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "sender identity not found"})
+	}
+
+	req.apply(identity)
+
+	if err := h.identityRepo.Update(identity); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, identity)
+}
+
+func (h *SenderIdentityHandler) Delete(c echo.Context) error {
+	if !isAdmin(c) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "forbidden"})
+	}
+
+	id := c.Param("id")
+	if id == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "id is required"})
+	}
+
+	if err := h.identityRepo.Delete(id); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"status": "deleted"})
+}
+
+func (req *SenderIdentityRequest) validate() error {
+	if strings.TrimSpace(req.Address) == "" {
+		return fmt.Errorf("address is required")
+	}
+	if addr, err := mail.ParseAddress(strings.TrimSpace(req.Address)); err != nil || addr.Name != "" {
+		return fmt.Errorf("address must be a plain email address")
+	}
+	if returnPath := strings.TrimSpace(req.ReturnPath); returnPath != "" {
+		if addr, err := mail.ParseAddress(returnPath); err != nil || addr.Name != "" {
+			return fmt.Errorf("return_path must be a plain email address")
+		}
+	}
+	if strings.ContainsAny(req.DisplayName, "\r\n") {
+		return fmt.Errorf("display_name must be a single line")
+	}
+	return nil
+}
+
+func (req *SenderIdentityRequest) apply(identity *entity.SenderIdentity) {
+	identity.Address = strings.TrimSpace(req.Address)
+	identity.DisplayName = strings.TrimSpace(req.DisplayName)
+	identity.SignatureText = req.SignatureText
+	identity.SignatureHTML = req.SignatureHTML
+	identity.OmitCodeFromBody = req.OmitCodeFromBody
+	identity.ReplyDomain = normalizeReplyDomain(req.ReplyDomain)
+	identity.ReturnPath = strings.TrimSpace(req.ReturnPath)
+
+	identity.AllowedUIDs = nil
+	for _, uid := range req.AllowedUIDs {
+		if uid = strings.TrimSpace(uid); uid != "" {
+			identity.AllowedUIDs = append(identity.AllowedUIDs, uid)
+		}
+	}
+}
+
+func normalizeReplyDomain(domain string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(domain), "@"))
+}
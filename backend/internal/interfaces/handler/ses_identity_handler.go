@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rikut0904/mailer-backend/internal/domain/repository"
+	senduc "github.com/rikut0904/mailer-backend/internal/usecase/send"
+)
+
+type SESIdentityHandler struct {
+	verification *senduc.SenderVerificationUseCase
+}
+
+func NewSESIdentityHandler(verification *senduc.SenderVerificationUseCase) *SESIdentityHandler {
+	return &SESIdentityHandler{verification: verification}
+}
+
+// List returns the SES identities with verification, DKIM and MAIL FROM
+// status. ?refresh=true re-reads every identity from SES.
+func (h *SESIdentityHandler) List(c echo.Context) error {
+	if !isAdmin(c) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "forbidden"})
+	}
+
+	identities, err := h.verification.List(c.QueryParam("refresh") == "true")
+	if err != nil {
+		if errors.Is(err, repository.ErrSESNotConfigured) {
+			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusBadGateway, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, identities)
+}
@@ -2,8 +2,10 @@ package handler
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/labstack/echo/v4"
+	"github.com/rikut0904/mailer-backend/internal/domain/entity"
 	settingsuc "github.com/rikut0904/mailer-backend/internal/usecase/settings"
 )
 
@@ -22,14 +24,31 @@ func NewSettingsHandler(
 	}
 }
 
-type UpdateSettingsRequest struct {
-	DiscordWebhookURL string `json:"discord_webhook_url"`
-	SelectedDomainID  string `json:"selected_domain_id"`
+type SettingsResponse struct {
+	NotificationChannels []entity.NotificationChannel `json:"notification_channels"`
+	NotificationRules    []entity.NotificationRule    `json:"notification_rules"`
+	QuietHours           entity.QuietHours            `json:"quiet_hours"`
+	UnreadDigest         entity.UnreadDigest          `json:"unread_digest"`
+	UnreadDigestSentAt   *time.Time                   `json:"unread_digest_sent_at"`
+	SelectedDomainID     string                       `json:"selected_domain_id"`
 }
 
-type SettingsResponse struct {
-	DiscordWebhookURL string `json:"discord_webhook_url"`
-	SelectedDomainID  string `json:"selected_domain_id"`
+func newSettingsResponse(setting *entity.UserSetting) SettingsResponse {
+	response := SettingsResponse{
+		NotificationChannels: setting.NotificationChannels,
+		NotificationRules:    setting.NotificationRules,
+		QuietHours:           setting.QuietHours,
+		UnreadDigest:         setting.UnreadDigest,
+		UnreadDigestSentAt:   setting.UnreadDigestSentAt,
+		SelectedDomainID:     setting.SelectedDomainID,
+	}
+	if response.NotificationChannels == nil {
+		response.NotificationChannels = []entity.NotificationChannel{}
+	}
+	if response.NotificationRules == nil {
+		response.NotificationRules = []entity.NotificationRule{}
+	}
+	return response
 }
 
 func (h *SettingsHandler) GetSettings(c echo.Context) error {
@@ -43,10 +62,7 @@ func (h *SettingsHandler) GetSettings(c echo.Context) error {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
-	return c.JSON(http.StatusOK, SettingsResponse{
-		DiscordWebhookURL: setting.DiscordWebhookURL,
-		SelectedDomainID:  setting.SelectedDomainID,
-	})
+	return c.JSON(http.StatusOK, newSettingsResponse(setting))
 }
 
 func (h *SettingsHandler) UpdateSettings(c echo.Context) error {
@@ -55,17 +71,18 @@ func (h *SettingsHandler) UpdateSettings(c echo.Context) error {
 		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
 	}
 
-	var req UpdateSettingsRequest
+	var req settingsuc.UserSettingsInput
 	if err := c.Bind(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
 	}
 
-	if err := h.updateUC.Execute(uid, req.DiscordWebhookURL, req.SelectedDomainID); err != nil {
+	if err := h.updateUC.Execute(uid, &req); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
-	return c.JSON(http.StatusOK, SettingsResponse{
-		DiscordWebhookURL: req.DiscordWebhookURL,
-		SelectedDomainID:  req.SelectedDomainID,
-	})
+	setting, err := h.getUC.Execute(uid)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, newSettingsResponse(setting))
 }
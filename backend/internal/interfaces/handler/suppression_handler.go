@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rikut0904/mailer-backend/internal/domain/entity"
+	"github.com/rikut0904/mailer-backend/internal/domain/repository"
+)
+
+// SuppressionHandler manages the suppression list; all operations are admin only.
+type SuppressionHandler struct {
+	suppressionRepo repository.SuppressionRepository
+}
+
+func NewSuppressionHandler(suppressionRepo repository.SuppressionRepository) *SuppressionHandler {
+	return &SuppressionHandler{suppressionRepo: suppressionRepo}
+}
+
+type SuppressionRequest struct {
+	Email  string `json:"email"`
+	Detail string `json:"detail"`
+}
+
+func (h *SuppressionHandler) List(c echo.Context) error {
+	if !isAdmin(c) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "forbidden"})
+	}
+
+	addresses, err := h.suppressionRepo.List()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, addresses)
+}
+
+// Create suppresses an address by hand, recorded as a complaint.
+func (h *SuppressionHandler) Create(c echo.Context) error {
+	if !isAdmin(c) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "forbidden"})
+	}
+
+	var req SuppressionRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	if strings.TrimSpace(req.Email) == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "email is required"})
+	}
+
+	address := &entity.SuppressedAddress{
+		Email:  req.Email,
+		Reason: entity.SuppressionReasonComplaint,
+		Detail: req.Detail,
+	}
+	if err := h.suppressionRepo.Upsert(address); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, address)
+}
+
+func (h *SuppressionHandler) Delete(c echo.Context) error {
+	if !isAdmin(c) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "forbidden"})
+	}
+
+	if err := h.suppressionRepo.Delete(c.Param("email")); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, map[string]string{"status": "deleted"})
+}
@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	mailuc "github.com/rikut0904/mailer-backend/internal/usecase/mail"
+)
+
+type SyncStatusHandler struct {
+	scheduler *mailuc.SyncScheduler
+}
+
+func NewSyncStatusHandler(scheduler *mailuc.SyncScheduler) *SyncStatusHandler {
+	return &SyncStatusHandler{scheduler: scheduler}
+}
+
+func (h *SyncStatusHandler) List(c echo.Context) error {
+	if !isAdmin(c) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "forbidden"})
+	}
+
+	statuses, err := h.scheduler.Statuses()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, statuses)
+}
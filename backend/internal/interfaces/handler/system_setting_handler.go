@@ -1,7 +1,9 @@
 package handler
 
 import (
+	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/labstack/echo/v4"
 	"github.com/rikut0904/mailer-backend/internal/domain/entity"
@@ -20,6 +22,14 @@ type SystemSettingRequest struct {
 	SESRegion      string `json:"ses_region"`
 	SESAccessKeyID string `json:"ses_access_key_id"`
 	SESSecretKey   string `json:"ses_secret_key"`
+	MailTransport  string `json:"mail_transport"`
+	SMTPFallback   bool   `json:"smtp_fallback"`
+	SMTPHost       string `json:"smtp_host"`
+	SMTPPort       int    `json:"smtp_port"`
+	SMTPSecurity   string `json:"smtp_security"`
+	SMTPAuth       string `json:"smtp_auth"`
+	SMTPUsername   string `json:"smtp_username"`
+	SMTPPassword   string `json:"smtp_password"`
 }
 
 func (h *SystemSettingHandler) Get(c echo.Context) error {
@@ -45,10 +55,28 @@ func (h *SystemSettingHandler) Update(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
 	}
 
+	if req.MailTransport == "" {
+		req.MailTransport = entity.MailTransportSES
+	}
+	if req.SMTPSecurity == "" {
+		req.SMTPSecurity = entity.SMTPSecurityStartTLS
+	}
+	if err := validateMailTransport(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
 	setting := &entity.SystemSetting{
 		SESRegion:      req.SESRegion,
 		SESAccessKeyID: req.SESAccessKeyID,
 		SESSecretKey:   req.SESSecretKey,
+		MailTransport:  req.MailTransport,
+		SMTPFallback:   req.SMTPFallback,
+		SMTPHost:       strings.TrimSpace(req.SMTPHost),
+		SMTPPort:       req.SMTPPort,
+		SMTPSecurity:   req.SMTPSecurity,
+		SMTPAuth:       req.SMTPAuth,
+		SMTPUsername:   req.SMTPUsername,
+		SMTPPassword:   req.SMTPPassword,
 	}
 
 	if err := h.repo.Upsert(setting); err != nil {
@@ -57,3 +85,28 @@ func (h *SystemSettingHandler) Update(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, setting)
 }
+
+func validateMailTransport(req *SystemSettingRequest) error {
+	switch req.MailTransport {
+	case entity.MailTransportSES, entity.MailTransportSMTP:
+	default:
+		return fmt.Errorf("unsupported mail_transport: %s", req.MailTransport)
+	}
+	switch req.SMTPSecurity {
+	case entity.SMTPSecurityStartTLS, entity.SMTPSecurityTLS, entity.SMTPSecurityNone:
+	default:
+		return fmt.Errorf("unsupported smtp_security: %s", req.SMTPSecurity)
+	}
+	switch req.SMTPAuth {
+	case entity.SMTPAuthNone, entity.SMTPAuthPlain, entity.SMTPAuthLogin:
+	default:
+		return fmt.Errorf("unsupported smtp_auth: %s", req.SMTPAuth)
+	}
+	if req.SMTPPort < 0 || req.SMTPPort > 65535 {
+		return fmt.Errorf("invalid smtp_port: %d", req.SMTPPort)
+	}
+	if (req.MailTransport == entity.MailTransportSMTP || req.SMTPFallback) && strings.TrimSpace(req.SMTPHost) == "" {
+		return fmt.Errorf("smtp_host is required when SMTP is used")
+	}
+	return nil
+}
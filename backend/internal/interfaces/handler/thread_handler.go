@@ -6,7 +6,6 @@ import (
 	"github.com/labstack/echo/v4"
 	"github.com/rikut0904/mailer-backend/internal/domain/entity"
 	"github.com/rikut0904/mailer-backend/internal/domain/repository"
-	awsinfra "github.com/rikut0904/mailer-backend/internal/infrastructure/aws"
 	threaduc "github.com/rikut0904/mailer-backend/internal/usecase/thread"
 )
 
@@ -14,17 +13,20 @@ type ThreadHandler struct {
 	getThreadUC     *threaduc.GetThreadUseCase
 	userSettingRepo repository.UserSettingRepository
 	domainRepo      repository.S3DomainRepository
+	storageFactory  repository.MailStorageFactory
 }
 
 func NewThreadHandler(
 	getThreadUC *threaduc.GetThreadUseCase,
 	userSettingRepo repository.UserSettingRepository,
 	domainRepo repository.S3DomainRepository,
+	storageFactory repository.MailStorageFactory,
 ) *ThreadHandler {
 	return &ThreadHandler{
 		getThreadUC:     getThreadUC,
 		userSettingRepo: userSettingRepo,
 		domainRepo:      domainRepo,
+		storageFactory:  storageFactory,
 	}
 }
 
@@ -57,39 +59,14 @@ func (h *ThreadHandler) ListThreads(c echo.Context) error {
 }
 
 func (h *ThreadHandler) storageForUser(c echo.Context) (*entity.S3Domain, repository.MailStorageRepository, error) {
-	uid, ok := c.Get("uid").(string)
-	if !ok || uid == "" {
-		return nil, nil, echo.NewHTTPError(http.StatusUnauthorized, "unauthorized")
-	}
-	role, _ := c.Get("role").(string)
-
-	domain, err := h.resolveDomain(uid, role)
+	domain, err := resolveUserDomain(c, h.userSettingRepo, h.domainRepo)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	storageRepo, err := awsinfra.NewS3ClientFromDomain(domain)
+	storageRepo, err := h.storageFactory(domain)
 	if err != nil {
 		return nil, nil, err
 	}
 	return domain, storageRepo, nil
 }
-
-func (h *ThreadHandler) resolveDomain(uid string, role string) (*entity.S3Domain, error) {
-	setting, err := h.userSettingRepo.GetByUID(uid)
-	if err == nil && setting.SelectedDomainID != "" {
-		if domain, err := h.domainRepo.GetByID(setting.SelectedDomainID); err == nil {
-			return domain, nil
-		}
-	}
-
-	domains, err := h.domainRepo.List()
-	if err != nil {
-		return nil, err
-	}
-	if len(domains) == 0 {
-		return nil, echo.NewHTTPError(http.StatusBadRequest, "no s3 domain configured")
-	}
-
-	return &domains[0], nil
-}
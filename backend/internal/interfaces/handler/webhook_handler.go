@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	webhookuc "github.com/rikut0904/mailer-backend/internal/usecase/webhook"
+)
+
+// WebhookHandler manages outbound webhook subscriptions; all operations are admin only.
+type WebhookHandler struct {
+	webhookUC *webhookuc.WebhookUseCase
+}
+
+func NewWebhookHandler(webhookUC *webhookuc.WebhookUseCase) *WebhookHandler {
+	return &WebhookHandler{webhookUC: webhookUC}
+}
+
+func (h *WebhookHandler) List(c echo.Context) error {
+	if !isAdmin(c) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "forbidden"})
+	}
+
+	subscriptions, err := h.webhookUC.List()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, subscriptions)
+}
+
+func (h *WebhookHandler) Create(c echo.Context) error {
+	if !isAdmin(c) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "forbidden"})
+	}
+
+	var input webhookuc.SubscriptionInput
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+
+	subscription, err := h.webhookUC.Create(&input)
+	if err != nil {
+		return c.JSON(webhookErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusCreated, subscription)
+}
+
+func (h *WebhookHandler) Update(c echo.Context) error {
+	if !isAdmin(c) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "forbidden"})
+	}
+
+	var input webhookuc.SubscriptionInput
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+
+	subscription, err := h.webhookUC.Update(c.Param("id"), &input)
+	if err != nil {
+		return c.JSON(webhookErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, subscription)
+}
+
+func (h *WebhookHandler) Delete(c echo.Context) error {
+	if !isAdmin(c) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "forbidden"})
+	}
+
+	if err := h.webhookUC.Delete(c.Param("id")); err != nil {
+		return c.JSON(webhookErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, map[string]string{"status": "deleted"})
+}
+
+// Deliveries returns the delivery log of a subscription, newest first.
+func (h *WebhookHandler) Deliveries(c echo.Context) error {
+	if !isAdmin(c) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "forbidden"})
+	}
+
+	deliveries, err := h.webhookUC.Deliveries(c.Param("id"))
+	if err != nil {
+		return c.JSON(webhookErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, deliveries)
+}
+
+func webhookErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, webhookuc.ErrSubscriptionNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, webhookuc.ErrSubscriptionInvalid):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -9,7 +9,8 @@ func CORS(allowedOrigins []string) echo.MiddlewareFunc {
 	return echomw.CORSWithConfig(echomw.CORSConfig{
 		AllowOrigins:     allowedOrigins,
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Accept", "Authorization", "Content-Type"},
+		AllowHeaders:     []string{"Accept", "Authorization", "Content-Type", "Range"},
+		ExposeHeaders:    []string{"Content-Disposition", "Content-Range", "Accept-Ranges"},
 		AllowCredentials: true,
 	})
 }
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+func InboundToken(token string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if token == "" {
+				return c.JSON(http.StatusServiceUnavailable, map[string]string{"error": "inbound endpoint is not configured"})
+			}
+
+			provided := c.Request().Header.Get("X-Inbound-Token")
+			if provided == "" {
+				provided = c.QueryParam("token")
+			}
+
+			if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid inbound token"})
+			}
+
+			return next(c)
+		}
+	}
+}
@@ -1,17 +1,32 @@
 package router
 
 import (
+	"context"
+	// Quiet hours and digest schedules are evaluated in the user's time zone
+	// even when the host has no zoneinfo. The server's main (cmd/server, not
+	// tracked here) only wires NewRouter, so the zone data is linked from here.
+	_ "time/tzdata"
+
 	"github.com/labstack/echo/v4"
 	echomw "github.com/labstack/echo/v4/middleware"
+	"github.com/rikut0904/mailer-backend/internal/domain/entity"
 	"github.com/rikut0904/mailer-backend/internal/domain/repository"
+	awsinfra "github.com/rikut0904/mailer-backend/internal/infrastructure/aws"
 	"github.com/rikut0904/mailer-backend/internal/infrastructure/discord"
 	fbinfra "github.com/rikut0904/mailer-backend/internal/infrastructure/firebase"
+	"github.com/rikut0904/mailer-backend/internal/infrastructure/slack"
+	"github.com/rikut0904/mailer-backend/internal/infrastructure/storage"
+	"github.com/rikut0904/mailer-backend/internal/infrastructure/teams"
 	"github.com/rikut0904/mailer-backend/internal/interfaces/handler"
 	"github.com/rikut0904/mailer-backend/internal/interfaces/middleware"
+	draftuc "github.com/rikut0904/mailer-backend/internal/usecase/draft"
 	mailuc "github.com/rikut0904/mailer-backend/internal/usecase/mail"
+	mergeuc "github.com/rikut0904/mailer-backend/internal/usecase/mailmerge"
+	templateuc "github.com/rikut0904/mailer-backend/internal/usecase/mailtemplate"
 	senduc "github.com/rikut0904/mailer-backend/internal/usecase/send"
 	settingsuc "github.com/rikut0904/mailer-backend/internal/usecase/settings"
 	threaduc "github.com/rikut0904/mailer-backend/internal/usecase/thread"
+	webhookuc "github.com/rikut0904/mailer-backend/internal/usecase/webhook"
 	"github.com/rikut0904/mailer-backend/pkg/config"
 )
 
@@ -25,9 +40,18 @@ func NewRouter(
 	userSettingRepo repository.UserSettingRepository,
 	domainRepo repository.S3DomainRepository,
 	systemSettingRepo repository.SystemSettingRepository,
+	domainSyncStateRepo repository.DomainSyncStateRepository,
+	senderIdentityRepo repository.SenderIdentityRepository,
+	outboxRepo repository.OutboxRepository,
+	draftRepo repository.DraftRepository,
+	suppressionRepo repository.SuppressionRepository,
+	templateRepo repository.MailTemplateRepository,
+	webhookRepo repository.WebhookRepository,
+	heldNotificationRepo repository.HeldNotificationRepository,
 	senderRepo repository.MailSenderRepository,
+	emailIdentityRepo repository.EmailIdentityRepository,
 	discordClient *discord.Client,
-) *echo.Echo {
+) (*echo.Echo, *Workers) {
 	e := echo.New()
 	e.HideBanner = true
 
@@ -44,24 +68,67 @@ func NewRouter(
 	configHandler := handler.NewConfigHandler(cfg)
 	e.GET("/api/config", configHandler.GetClientConfig)
 
+	storageFactory := storage.NewFactory(cfg.LocalStorageRoot)
+
 	// Usecases
+	webhookUC := webhookuc.NewWebhookUseCase(webhookRepo)
+	webhookWorker := webhookuc.NewWebhookWorker(webhookRepo, webhookUC, cfg.WebhookPollInterval)
 	linkThreadUC := mailuc.NewLinkThreadUseCase(sentMailRepo, mailStateRepo)
 	getMailsUC := mailuc.NewGetMailsUseCase(mailStateRepo, linkThreadUC)
 	updateStateUC := mailuc.NewUpdateStateUseCase(mailStateRepo)
-	deleteMailUC := mailuc.NewDeleteMailUseCase(mailStateRepo)
-	syncMailsUC := mailuc.NewSyncMailsUseCase(mailStateRepo, linkThreadUC)
+	deleteMailUC := mailuc.NewDeleteMailUseCase(mailStateRepo, webhookUC)
+	notifiers := map[string]repository.Notifier{
+		entity.NotificationChannelDiscord: discordClient,
+		entity.NotificationChannelSlack:   slack.NewClient(),
+		entity.NotificationChannelTeams:   teams.NewClient(),
+	}
+	newMailNotifier := mailuc.NewNewMailNotifier(userSettingRepo, domainRepo, heldNotificationRepo, notifiers, cfg.AppURL)
+	syncMailsUC := mailuc.NewSyncMailsUseCase(mailStateRepo, linkThreadUC, newMailNotifier, webhookUC)
+	syncScheduler := mailuc.NewSyncScheduler(domainRepo, domainSyncStateRepo, syncMailsUC, storageFactory, cfg.SyncInterval, cfg.SyncFullScanInterval)
 	getThreadUC := threaduc.NewGetThreadUseCase(threadGroupRepo, mailStateRepo, sentMailRepo)
-	sendMailUC := senduc.NewSendMailUseCase(sentMailRepo, threadGroupRepo, senderRepo, discordClient)
+	senderVerificationUC := senduc.NewSenderVerificationUseCase(emailIdentityRepo, systemSettingRepo)
+	sendMailUC := senduc.NewSendMailUseCase(sentMailRepo, outboxRepo, senderIdentityRepo, suppressionRepo, senderVerificationUC, discordClient, cfg.SendUndoWindow)
+	draftUC := draftuc.NewDraftUseCase(draftRepo, sendMailUC)
+	templateUC := templateuc.NewMailTemplateUseCase(templateRepo)
+	mergeUC := mergeuc.NewMailMergeUseCase(sendMailUC, templateUC)
+	unreadDigestWorker := mailuc.NewUnreadDigestWorker(userSettingRepo, domainRepo, mailStateRepo, sendMailUC, cfg.UnreadDigestFrom, cfg.AppURL)
+	outboxWorker := senduc.NewOutboxWorker(outboxRepo, threadGroupRepo, suppressionRepo, senderRepo, systemSettingRepo, webhookUC, cfg.OutboxPollInterval, cfg.SendRateLimit)
+	deliveryFeedbackUC := senduc.NewDeliveryFeedbackUseCase(sentMailRepo, suppressionRepo, webhookUC)
 	getSettingsUC := settingsuc.NewGetUserSettingsUseCase(userSettingRepo)
 	updateSettingsUC := settingsuc.NewUpdateUserSettingsUseCase(userSettingRepo)
 
 	// Handlers
-	mailHandler := handler.NewMailHandler(getMailsUC, updateStateUC, deleteMailUC, syncMailsUC, userSettingRepo, domainRepo)
-	threadHandler := handler.NewThreadHandler(getThreadUC, userSettingRepo, domainRepo)
-	sendHandler := handler.NewSendHandler(sendMailUC)
+	mailHandler := handler.NewMailHandler(getMailsUC, updateStateUC, deleteMailUC, syncMailsUC, userSettingRepo, domainRepo, storageFactory)
+	threadHandler := handler.NewThreadHandler(getThreadUC, userSettingRepo, domainRepo, storageFactory)
+	sendHandler := handler.NewSendHandler(sendMailUC, templateUC, getMailsUC, userSettingRepo, domainRepo, storageFactory)
+	draftHandler := handler.NewDraftHandler(draftUC, getMailsUC, userSettingRepo, domainRepo, storageFactory)
+	templateHandler := handler.NewMailTemplateHandler(templateUC)
+	mergeHandler := handler.NewMailMergeHandler(mergeUC, sendMailUC, getMailsUC, userSettingRepo, domainRepo, storageFactory)
 	settingsHandler := handler.NewSettingsHandler(getSettingsUC, updateSettingsUC)
 	domainHandler := handler.NewDomainHandler(domainRepo)
 	systemSettingHandler := handler.NewSystemSettingHandler(systemSettingRepo)
+	snsVerifier := awsinfra.NewSNSVerifier()
+	inboundHandler := handler.NewInboundHandler(syncMailsUC, domainRepo, storageFactory, snsVerifier, cfg.VerifySNSSignature)
+	deliveryFeedbackHandler := handler.NewDeliveryFeedbackHandler(deliveryFeedbackUC, snsVerifier, cfg.VerifySNSSignature)
+	syncStatusHandler := handler.NewSyncStatusHandler(syncScheduler)
+	senderIdentityHandler := handler.NewSenderIdentityHandler(senderIdentityRepo)
+	suppressionHandler := handler.NewSuppressionHandler(suppressionRepo)
+	sesIdentityHandler := handler.NewSESIdentityHandler(senderVerificationUC)
+	webhookHandler := handler.NewWebhookHandler(webhookUC)
+
+	// Background workers, started by main
+	workers := &Workers{runners: []func(context.Context){
+		syncScheduler.Run,
+		outboxWorker.Run,
+		newMailNotifier.Run,
+		webhookWorker.Run,
+		unreadDigestWorker.Run,
+	}}
+
+	// Inbound notifications (SNS / S3 events), authenticated by shared token
+	inbound := e.Group("/inbound", middleware.InboundToken(cfg.InboundToken))
+	inbound.POST("/mails", inboundHandler.ReceiveMailEvent)
+	inbound.POST("/ses-events", deliveryFeedbackHandler.ReceiveSESEvent)
 
 	// Authenticated routes
 	api := e.Group("/api", middleware.FirebaseAuth(fbAuth, userRepo))
@@ -69,6 +136,8 @@ func NewRouter(
 	// Mail routes
 	api.GET("/mails", mailHandler.GetMails)
 	api.GET("/mails/:s3Key", mailHandler.GetMail)
+	api.GET("/mails/:s3Key/raw", mailHandler.GetRawMail)
+	api.GET("/mails/:s3Key/attachments/:index", mailHandler.GetAttachment)
 	api.PATCH("/mails/:s3Key/read", mailHandler.UpdateReadStatus)
 	api.PATCH("/mails/:s3Key/star", mailHandler.UpdateStarStatus)
 	api.DELETE("/mails/:s3Key", mailHandler.DeleteMail)
@@ -81,6 +150,33 @@ func NewRouter(
 
 	// Send routes
 	api.POST("/send", sendHandler.SendMail)
+	api.POST("/send/preview", sendHandler.PreviewMail)
+	api.GET("/send/scheduled", sendHandler.ListScheduled)
+	api.GET("/send/:batchId", sendHandler.GetSendStatus)
+	api.PUT("/send/:batchId", sendHandler.UpdateScheduled)
+	api.PATCH("/send/:batchId/schedule", sendHandler.Reschedule)
+	api.DELETE("/send/:batchId", sendHandler.CancelSend)
+
+	// Mail merge routes (progress via GET /send/:batchId)
+	api.POST("/merges", mergeHandler.Create)
+	api.GET("/merges/:batchId/report", mergeHandler.Report)
+
+	// Draft routes
+	api.GET("/drafts", draftHandler.List)
+	api.POST("/drafts", draftHandler.Create)
+	api.GET("/drafts/:id", draftHandler.Get)
+	api.PUT("/drafts/:id", draftHandler.Update)
+	api.DELETE("/drafts/:id", draftHandler.Delete)
+	api.POST("/drafts/:id/attachments", draftHandler.UploadAttachments)
+	api.DELETE("/drafts/:id/attachments/:attachmentId", draftHandler.DeleteAttachment)
+	api.POST("/drafts/:id/send", draftHandler.Send)
+
+	// Template routes
+	api.GET("/templates", templateHandler.List)
+	api.POST("/templates", templateHandler.Create)
+	api.GET("/templates/:id", templateHandler.Get)
+	api.PUT("/templates/:id", templateHandler.Update)
+	api.DELETE("/templates/:id", templateHandler.Delete)
 
 	// Settings routes
 	api.GET("/settings", settingsHandler.GetSettings)
@@ -92,9 +188,29 @@ func NewRouter(
 	api.PUT("/domains/:id", domainHandler.UpdateDomain)
 	api.DELETE("/domains/:id", domainHandler.DeleteDomain)
 
+	// Sender identities (changes are admin only)
+	api.GET("/sender-identities", senderIdentityHandler.List)
+	api.POST("/sender-identities", senderIdentityHandler.Create)
+	api.PUT("/sender-identities/:id", senderIdentityHandler.Update)
+	api.DELETE("/sender-identities/:id", senderIdentityHandler.Delete)
+
+	// Suppression list (admin only)
+	api.GET("/suppressions", suppressionHandler.List)
+	api.POST("/suppressions", suppressionHandler.Create)
+	api.DELETE("/suppressions/:email", suppressionHandler.Delete)
+
+	// Outbound webhooks (admin only)
+	api.GET("/webhooks", webhookHandler.List)
+	api.POST("/webhooks", webhookHandler.Create)
+	api.PUT("/webhooks/:id", webhookHandler.Update)
+	api.DELETE("/webhooks/:id", webhookHandler.Delete)
+	api.GET("/webhooks/:id/deliveries", webhookHandler.Deliveries)
+
 	// System settings (admin only)
 	api.GET("/system/settings", systemSettingHandler.Get)
 	api.PUT("/system/settings", systemSettingHandler.Update)
+	api.GET("/system/sync-status", syncStatusHandler.List)
+	api.GET("/system/ses-identities", sesIdentityHandler.List)
 
-	return e
+	return e, workers
 }
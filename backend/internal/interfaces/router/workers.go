@@ -0,0 +1,26 @@
+package router
+
+import (
+	"context"
+	"sync"
+)
+
+// Workers are the background loops behind the API. main runs them with a
+// context it cancels on shutdown.
+type Workers struct {
+	runners []func(context.Context)
+}
+
+// Run starts every worker and returns once all of them have stopped, which
+// happens after ctx is cancelled and each has finished its current pass.
+func (w *Workers) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, run := range w.runners {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			run(ctx)
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,262 @@
+package draft
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rikut0904/mailer-backend/internal/domain/entity"
+	"github.com/rikut0904/mailer-backend/internal/domain/repository"
+	senduc "github.com/rikut0904/mailer-backend/internal/usecase/send"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrDraftNotFound           = errors.New("draft not found")
+	ErrDraftAttachmentNotFound = errors.New("draft attachment not found")
+	ErrDraftSending            = errors.New("draft is already being sent")
+)
+
+// draftSendingTimeout frees a draft whose send was interrupted before it
+// could be queued or restored.
+const draftSendingTimeout = 5 * time.Minute
+
+// DraftUseCase manages the drafts of a single Firebase user; every method
+// takes the uid and treats other users' drafts as missing.
+type DraftUseCase struct {
+	draftRepo  repository.DraftRepository
+	sendMailUC *senduc.SendMailUseCase
+}
+
+func NewDraftUseCase(draftRepo repository.DraftRepository, sendMailUC *senduc.SendMailUseCase) *DraftUseCase {
+	return &DraftUseCase{
+		draftRepo:  draftRepo,
+		sendMailUC: sendMailUC,
+	}
+}
+
+// DraftInput is the editable part of a draft. Autosave sends the whole
+// compose state each time, so Update replaces every field.
+type DraftInput struct {
+	To             []string               `json:"to"`
+	Cc             []string               `json:"cc"`
+	Bcc            []string               `json:"bcc"`
+	ReplyTo        string                 `json:"reply_to"`
+	FromAddress    string                 `json:"from_address"`
+	Subject        string                 `json:"subject"`
+	Body           string                 `json:"body"`
+	HTMLBody       string                 `json:"html_body"`
+	SendType       string                 `json:"send_type"`
+	ThreadID       string                 `json:"thread_id"`
+	ReplyCode      string                 `json:"reply_code"`
+	InReplyTo      string                 `json:"in_reply_to"`
+	References     []string               `json:"references"`
+	AttachmentRefs []entity.AttachmentRef `json:"attachment_refs"`
+}
+
+func (uc *DraftUseCase) List(uid string) ([]entity.Draft, error) {
+	drafts, err := uc.draftRepo.ListByUID(uid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list drafts: %w", err)
+	}
+	return drafts, nil
+}
+
+func (uc *DraftUseCase) Get(uid, id string) (*entity.Draft, error) {
+	draft, err := uc.draftRepo.GetByID(uid, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDraftNotFound
+		}
+		return nil, fmt.Errorf("failed to load draft: %w", err)
+	}
+	return draft, nil
+}
+
+func (uc *DraftUseCase) Create(uid string, input *DraftInput) (*entity.Draft, error) {
+	draft := &entity.Draft{
+		ID:  uuid.New().String(),
+		UID: uid,
+	}
+	input.apply(draft)
+
+	if err := uc.draftRepo.Create(draft); err != nil {
+		return nil, fmt.Errorf("failed to create draft: %w", err)
+	}
+	return uc.Get(uid, draft.ID)
+}
+
+func (uc *DraftUseCase) Update(uid, id string, input *DraftInput) (*entity.Draft, error) {
+	draft, err := uc.Get(uid, id)
+	if err != nil {
+		return nil, err
+	}
+	if draft.Status == entity.DraftStatusSending {
+		return nil, ErrDraftSending
+	}
+	input.apply(draft)
+
+	if err := uc.draftRepo.Update(draft); err != nil {
+		return nil, fmt.Errorf("failed to update draft: %w", err)
+	}
+	return uc.Get(uid, id)
+}
+
+func (uc *DraftUseCase) Delete(uid, id string) error {
+	if err := uc.draftRepo.Delete(uid, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrDraftNotFound
+		}
+		return fmt.Errorf("failed to delete draft: %w", err)
+	}
+	return nil
+}
+
+// AddAttachments stores uploaded files with the draft. The combined size is
+// checked against the SES limit so the draft stays sendable.
+func (uc *DraftUseCase) AddAttachments(uid, id string, files []entity.Attachment) (*entity.Draft, error) {
+	draft, err := uc.Get(uid, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var total int64
+	for _, att := range draft.Attachments {
+		total += int64(att.Size)
+	}
+	attachments := make([]entity.DraftAttachment, 0, len(files))
+	for _, file := range files {
+		total += int64(len(file.Content))
+		attachments = append(attachments, entity.DraftAttachment{
+			ID:          uuid.New().String(),
+			DraftID:     draft.ID,
+			Filename:    file.Filename,
+			ContentType: file.ContentType,
+			Size:        len(file.Content),
+			Content:     file.Content,
+		})
+	}
+	// Base64 grows content by 4/3.
+	if total*4/3 > repository.MaxRawMessageSize {
+		return nil, fmt.Errorf("%w: attachments exceed %d MB after encoding",
+			repository.ErrMessageTooLarge, repository.MaxRawMessageSize/(1024*1024))
+	}
+
+	if err := uc.draftRepo.AddAttachments(attachments); err != nil {
+		return nil, fmt.Errorf("failed to store draft attachments: %w", err)
+	}
+	return uc.Get(uid, id)
+}
+
+func (uc *DraftUseCase) RemoveAttachment(uid, id, attachmentID string) (*entity.Draft, error) {
+	if _, err := uc.Get(uid, id); err != nil {
+		return nil, err
+	}
+
+	deleted, err := uc.draftRepo.DeleteAttachment(id, attachmentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete draft attachment: %w", err)
+	}
+	if !deleted {
+		return nil, ErrDraftAttachmentNotFound
+	}
+	return uc.Get(uid, id)
+}
+
+// SendRequest builds the request the draft would be sent with, including the
+// stored files. AttachmentRefs are left for the caller to resolve.
+func (uc *DraftUseCase) SendRequest(uid, id string) (*senduc.SendRequest, error) {
+	draft, err := uc.Get(uid, id)
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := uc.draftRepo.ListAttachmentContents(draft.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load draft attachments: %w", err)
+	}
+	attachments := make([]entity.Attachment, 0, len(stored))
+	for _, att := range stored {
+		attachments = append(attachments, entity.Attachment{
+			Filename:    att.Filename,
+			ContentType: att.ContentType,
+			Size:        att.Size,
+			Content:     att.Content,
+		})
+	}
+
+	sendType := draft.SendType
+	if sendType == "" {
+		sendType = "new"
+	}
+
+	return &senduc.SendRequest{
+		To:             draft.To,
+		Cc:             draft.Cc,
+		Bcc:            draft.Bcc,
+		ReplyTo:        draft.ReplyTo,
+		Subject:        draft.Subject,
+		Body:           draft.Body,
+		HTMLBody:       draft.HTMLBody,
+		ThreadID:       draft.ThreadID,
+		ReplyCode:      draft.ReplyCode,
+		SendType:       sendType,
+		FromAddress:    draft.FromAddress,
+		UID:            uid,
+		InReplyTo:      draft.InReplyTo,
+		References:     draft.References,
+		Attachments:    attachments,
+		AttachmentRefs: draft.AttachmentRefs,
+	}, nil
+}
+
+// Send queues req through SendMailUseCase and removes the draft once the mail
+// is in the outbox. The draft is marked sending first, so a repeated click
+// gets ErrDraftSending instead of a second copy.
+func (uc *DraftUseCase) Send(uid, id string, req *senduc.SendRequest) (*senduc.SendResponse, error) {
+	marked, err := uc.draftRepo.MarkSending(uid, id, time.Now().Add(-draftSendingTimeout))
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock draft: %w", err)
+	}
+	if !marked {
+		if _, err := uc.Get(uid, id); err != nil {
+			return nil, err
+		}
+		return nil, ErrDraftSending
+	}
+
+	result, err := uc.sendMailUC.Execute(req)
+	if err != nil {
+		if restoreErr := uc.draftRepo.MarkEditing(uid, id); restoreErr != nil {
+			log.Printf("draft: failed to restore draft %s after send error: %v", id, restoreErr)
+		}
+		return nil, err
+	}
+
+	if err := uc.Delete(uid, id); err != nil {
+		log.Printf("draft: failed to delete sent draft %s: %v", id, err)
+	}
+	return result, nil
+}
+
+func (input *DraftInput) apply(draft *entity.Draft) {
+	draft.To = input.To
+	draft.Cc = input.Cc
+	draft.Bcc = input.Bcc
+	draft.ReplyTo = input.ReplyTo
+	draft.FromAddress = input.FromAddress
+	draft.Subject = input.Subject
+	draft.Body = input.Body
+	draft.HTMLBody = input.HTMLBody
+	draft.SendType = input.SendType
+	if draft.SendType == "" {
+		draft.SendType = "new"
+	}
+	draft.ThreadID = input.ThreadID
+	draft.ReplyCode = input.ReplyCode
+	draft.InReplyTo = input.InReplyTo
+	draft.References = input.References
+	draft.AttachmentRefs = input.AttachmentRefs
+}
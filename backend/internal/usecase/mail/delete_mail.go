@@ -3,18 +3,23 @@ package mail
 import (
 	"fmt"
 
+	"github.com/rikut0904/mailer-backend/internal/domain/entity"
 	"github.com/rikut0904/mailer-backend/internal/domain/repository"
+	webhookuc "github.com/rikut0904/mailer-backend/internal/usecase/webhook"
 )
 
 type DeleteMailUseCase struct {
 	mailStateRepo repository.MailStateRepository
+	webhookUC     *webhookuc.WebhookUseCase
 }
 
 func NewDeleteMailUseCase(
 	mailStateRepo repository.MailStateRepository,
+	webhookUC *webhookuc.WebhookUseCase,
 ) *DeleteMailUseCase {
 	return &DeleteMailUseCase{
 		mailStateRepo: mailStateRepo,
+		webhookUC:     webhookUC,
 	}
 }
 
@@ -27,5 +32,6 @@ func (uc *DeleteMailUseCase) Execute(storageRepo repository.MailStorageRepositor
 		return fmt.Errorf("failed to delete mail state: %w", err)
 	}
 
+	uc.webhookUC.Publish(entity.WebhookEventMailDeleted, webhookuc.MailData{DomainID: domainID, S3Key: s3Key})
 	return nil
 }
@@ -1,12 +1,19 @@
 package mail
 
 import (
+	"errors"
 	"fmt"
-	"log"
+	"io"
 
 	"github.com/rikut0904/mailer-backend/internal/domain/entity"
 	"github.com/rikut0904/mailer-backend/internal/domain/repository"
 	mimeparser "github.com/rikut0904/mailer-backend/pkg/mime"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrMailNotFound       = errors.New("mail not found")
+	ErrAttachmentNotFound = mimeparser.ErrAttachmentNotFound
 )
 
 type GetMailsUseCase struct {
@@ -32,7 +39,7 @@ type MailListResponse struct {
 	TotalPages int                 `json:"total_pages"`
 }
 
-func (uc *GetMailsUseCase) Execute(storageRepo repository.MailStorageRepository, domainID, recipientAddress string, page, perPage int) (*MailListResponse, error) {
+func (uc *GetMailsUseCase) Execute(domainID, recipientAddress string, page, perPage int) (*MailListResponse, error) {
 	if perPage <= 0 {
 		perPage = 20
 	}
@@ -48,22 +55,7 @@ func (uc *GetMailsUseCase) Execute(storageRepo repository.MailStorageRepository,
 
 	mails := make([]entity.ParsedMail, 0, len(states))
 	for _, state := range states {
-		raw, err := storageRepo.GetObject(state.S3Key)
-		if err != nil {
-			log.Printf("failed to get S3 object %s: %v", state.S3Key, err)
-			continue
-		}
-
-		parsed, err := mimeparser.Parse(raw, state.S3Key)
-		if err != nil {
-			log.Printf("failed to parse mail %s: %v", state.S3Key, err)
-			continue
-		}
-
-		parsed.IsRead = state.IsRead
-		parsed.IsStarred = state.IsStarred
-		parsed.ThreadID = state.ThreadID
-		mails = append(mails, *parsed)
+		mails = append(mails, summaryFromState(state))
 	}
 
 	totalPages := int(total) / perPage
@@ -102,3 +94,76 @@ func (uc *GetMailsUseCase) GetByS3Key(storageRepo repository.MailStorageReposito
 
 	return parsed, nil
 }
+
+func (uc *GetMailsUseCase) GetRaw(storageRepo repository.MailStorageRepository, domainID, s3Key string) ([]byte, error) {
+	if err := uc.checkState(domainID, s3Key); err != nil {
+		return nil, err
+	}
+
+	raw, err := storageRepo.GetObject(s3Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get S3 object: %w", err)
+	}
+	return raw, nil
+}
+
+// checkState reports a missing mail state as ErrMailNotFound.
+func (uc *GetMailsUseCase) checkState(domainID, s3Key string) error {
+	_, err := uc.mailStateRepo.FindByS3Key(domainID, s3Key)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrMailNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load mail state: %w", err)
+	}
+	return nil
+}
+
+// OpenRaw streams the stored message; the caller closes it.
+func (uc *GetMailsUseCase) OpenRaw(storageRepo repository.MailStorageRepository, domainID, s3Key string) (io.ReadSeekCloser, error) {
+	if err := uc.checkState(domainID, s3Key); err != nil {
+		return nil, err
+	}
+
+	object, err := storageRepo.OpenObject(s3Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open S3 object: %w", err)
+	}
+	return object, nil
+}
+
+// WriteAttachment decodes the attachment at index into w while streaming the
+// stored message.
+func (uc *GetMailsUseCase) WriteAttachment(storageRepo repository.MailStorageRepository, domainID, s3Key string, index int, w io.Writer) (*entity.Attachment, error) {
+	if index < 0 {
+		return nil, ErrAttachmentNotFound
+	}
+	object, err := uc.OpenRaw(storageRepo, domainID, s3Key)
+	if err != nil {
+		return nil, err
+	}
+	defer object.Close()
+
+	return mimeparser.WriteAttachment(object, index, w)
+}
+
+func (uc *GetMailsUseCase) GetAttachments(storageRepo repository.MailStorageRepository, domainID, s3Key string) ([]entity.Attachment, error) {
+	raw, err := uc.GetRaw(storageRepo, domainID, s3Key)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := mimeparser.Parse(raw, s3Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse mail: %w", err)
+	}
+	return parsed.Attachments, nil
+}
+
+func (uc *GetMailsUseCase) ListRecipients(domainID string) ([]string, error) {
+	recipients, err := uc.mailStateRepo.ListRecipients(domainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch recipients: %w", err)
+	}
+	return recipients, nil
+}
@@ -1,12 +1,19 @@
 package mail
 
 import (
+	"net/mail"
 	"regexp"
+	"strings"
 
+	"github.com/rikut0904/mailer-backend/internal/domain/entity"
 	"github.com/rikut0904/mailer-backend/internal/domain/repository"
+	mimeparser "github.com/rikut0904/mailer-backend/pkg/mime"
 )
 
-var managementCodeRegex = regexp.MustCompile(`【管理コード: ([^】]+)】`)
+var (
+	managementCodeRegex = regexp.MustCompile(`【管理コード: ([^】]+)】`)
+	replyAddressRegex   = regexp.MustCompile(`(?i)\breply\+([0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12})@`)
+)
 
 type LinkThreadUseCase struct {
 	sentMailRepo  repository.SentMailRepository
@@ -23,23 +30,113 @@ func NewLinkThreadUseCase(
 	}
 }
 
-func (uc *LinkThreadUseCase) LinkFromBody(body string, domainID string, s3Key string) (string, error) {
-	matches := managementCodeRegex.FindStringSubmatch(body)
-	if len(matches) < 2 {
+// Link attaches a received mail to a thread. A management code wins, taken
+// from the body, the management code header or a reply+<code>@ recipient;
+// otherwise In-Reply-To/References are matched against sent and
+// received Message-IDs (ours or the one SES assigned), and finally a reply-prefixed subject is matched
+// against mails previously sent to the same sender.
+func (uc *LinkThreadUseCase) Link(parsed *entity.ParsedMail, domainID string, s3Key string) (string, error) {
+	threadID := uc.threadIDFromCode(ExtractManagementCode(parsed.Body))
+	if threadID == "" {
+		threadID = uc.threadIDFromCode(parsed.ManagementCode)
+	}
+	if threadID == "" {
+		threadID = uc.threadIDFromCode(extractReplyAddressCode(parsed))
+	}
+	if threadID == "" {
+		threadID = uc.threadIDFromHeaders(parsed, domainID)
+	}
+	if threadID == "" {
+		threadID = uc.threadIDFromSubject(parsed)
+	}
+	if threadID == "" {
 		return "", nil
 	}
 
-	code := matches[1]
+	if err := uc.mailStateRepo.UpdateThreadID(domainID, s3Key, threadID); err != nil {
+		return "", err
+	}
+	return threadID, nil
+}
+
+func (uc *LinkThreadUseCase) threadIDFromCode(code string) string {
+	if code == "" {
+		return ""
+	}
+
 	sentMail, err := uc.sentMailRepo.FindByManagementCode(code)
 	if err != nil {
-		return "", nil
+		return ""
 	}
+	return sentMail.ParentThreadID
+}
 
-	if err := uc.mailStateRepo.UpdateThreadID(domainID, s3Key, sentMail.ParentThreadID); err != nil {
-		return "", err
+// threadIDFromHeaders walks In-Reply-To and then References from the nearest
+// ancestor outwards, returning the thread of the first known message.
+func (uc *LinkThreadUseCase) threadIDFromHeaders(parsed *entity.ParsedMail, domainID string) string {
+	var ids []string
+	if parsed.InReplyTo != "" {
+		ids = append(ids, parsed.InReplyTo)
+	}
+	for i := len(parsed.References) - 1; i >= 0; i-- {
+		ids = append(ids, parsed.References[i])
+	}
+	if len(ids) == 0 {
+		return ""
 	}
 
-	return sentMail.ParentThreadID, nil
+	threads := map[string]string{}
+	if sentMails, err := uc.sentMailRepo.FindByMessageIDs(ids); err == nil {
+		for _, sent := range sentMails {
+			threads[sent.MessageID] = sent.ParentThreadID
+			if sent.SESMessageID != "" {
+				threads[sent.SESMessageID] = sent.ParentThreadID
+			}
+		}
+	}
+	if states, err := uc.mailStateRepo.FindByMessageIDs(domainID, ids); err == nil {
+		for _, state := range states {
+			if state.ThreadID == nil || *state.ThreadID == "" {
+				continue
+			}
+			if _, ok := threads[state.MessageID]; !ok {
+				threads[state.MessageID] = *state.ThreadID
+			}
+		}
+	}
+
+	for _, id := range ids {
+		if threadID, ok := threads[id]; ok {
+			return threadID
+		}
+	}
+	return ""
+}
+
+// threadIDFromSubject follows JWZ: only messages whose subject carries a
+// reply/forward prefix are grouped by their base subject. The match is limited
+// to mails sent to the message's sender so unrelated threads are not merged.
+func (uc *LinkThreadUseCase) threadIDFromSubject(parsed *entity.ParsedMail) string {
+	base, isReply := mimeparser.NormalizeSubject(parsed.Subject)
+	if !isReply || base == "" {
+		return ""
+	}
+
+	from, err := mail.ParseAddress(parsed.From)
+	if err != nil {
+		return ""
+	}
+
+	sentMails, err := uc.sentMailRepo.FindByRecipientEmail(from.Address)
+	if err != nil {
+		return ""
+	}
+	for _, sent := range sentMails {
+		if sentBase, _ := mimeparser.NormalizeSubject(sent.Subject); strings.EqualFold(sentBase, base) {
+			return sent.ParentThreadID
+		}
+	}
+	return ""
 }
 
 func ExtractManagementCode(body string) string {
@@ -49,3 +146,13 @@ func ExtractManagementCode(body string) string {
 	}
 	return matches[1]
 }
+
+func extractReplyAddressCode(parsed *entity.ParsedMail) string {
+	candidates := append([]string{parsed.To, parsed.Cc}, parsed.DeliveredTo...)
+	for _, value := range candidates {
+		if matches := replyAddressRegex.FindStringSubmatch(value); len(matches) == 2 {
+			return strings.ToLower(matches[1])
+		}
+	}
+	return ""
+}
@@ -0,0 +1,58 @@
+package mail
+
+import (
+	"strings"
+	"time"
+
+	"github.com/rikut0904/mailer-backend/internal/domain/entity"
+	mimeparser "github.com/rikut0904/mailer-backend/pkg/mime"
+)
+
+const snippetLength = 200
+
+func applyMetadata(state *entity.MailState, parsed *entity.ParsedMail) {
+	var attachmentSize int64
+	for _, att := range parsed.Attachments {
+		attachmentSize += int64(att.Size)
+	}
+
+	now := time.Now()
+	state.RecipientAddress = parsed.To
+	state.MessageID = parsed.MessageID
+	state.InReplyTo = parsed.InReplyTo
+	state.References = strings.Join(parsed.References, " ")
+	state.Subject = parsed.Subject
+	state.FromAddress = parsed.From
+	state.ToAddress = parsed.To
+	state.Cc = parsed.Cc
+	state.MailDate = parsed.Date
+	state.Snippet = mimeparser.Snippet(parsed.Body, parsed.HTMLBody, snippetLength)
+	state.AttachmentCount = len(parsed.Attachments)
+	state.AttachmentSize = attachmentSize
+	state.IndexedAt = &now
+}
+
+func summaryFromState(state entity.MailState) entity.ParsedMail {
+	date := state.MailDate
+	if date.IsZero() {
+		date = state.CreatedAt
+	}
+
+	return entity.ParsedMail{
+		S3Key:           state.S3Key,
+		MessageID:       state.MessageID,
+		InReplyTo:       state.InReplyTo,
+		References:      strings.Fields(state.References),
+		From:            state.FromAddress,
+		To:              state.ToAddress,
+		Cc:              state.Cc,
+		Subject:         state.Subject,
+		Date:            date,
+		Snippet:         state.Snippet,
+		AttachmentCount: state.AttachmentCount,
+		AttachmentSize:  state.AttachmentSize,
+		IsRead:          state.IsRead,
+		IsStarred:       state.IsStarred,
+		ThreadID:        state.ThreadID,
+	}
+}
@@ -0,0 +1,384 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rikut0904/mailer-backend/internal/domain/entity"
+	"github.com/rikut0904/mailer-backend/internal/domain/repository"
+	"gorm.io/gorm"
+)
+
+const (
+	// notifyBatchWindow is how long arrivals are collected into one message.
+	notifyBatchWindow = 5 * time.Second
+	notifyQueueSize   = 1000
+	// notifyMaxAge skips old mail found by full scans or a newly added domain;
+	// webhooks use the same cut-off.
+	notifyMaxAge = 24 * time.Hour
+	// digestCheckInterval is how often ended quiet hours are looked for.
+	digestCheckInterval = time.Minute
+	// digestMaxMails bounds the mails listed in one digest; the total is
+	// still reported.
+	digestMaxMails = 50
+)
+
+// NewMailNotifier posts newly stored mail to the notification channels of the
+// users whose (selected or default) domain received it. Each user's rules pick
+// the channels and mentions; during the user's quiet hours notifications are
+// held and sent as a digest once they end.
+type NewMailNotifier struct {
+	userSettingRepo repository.UserSettingRepository
+	domainRepo      repository.S3DomainRepository
+	heldRepo        repository.HeldNotificationRepository
+	// notifiers is keyed by entity.NotificationChannel type
+	notifiers map[string]repository.Notifier
+	appURL    string
+	queue     chan entity.MailState
+}
+
+func NewNewMailNotifier(
+	userSettingRepo repository.UserSettingRepository,
+	domainRepo repository.S3DomainRepository,
+	heldRepo repository.HeldNotificationRepository,
+	notifiers map[string]repository.Notifier,
+	appURL string,
+) *NewMailNotifier {
+	return &NewMailNotifier{
+		userSettingRepo: userSettingRepo,
+		domainRepo:      domainRepo,
+		heldRepo:        heldRepo,
+		notifiers:       notifiers,
+		appURL:          strings.TrimRight(appURL, "/"),
+		queue:           make(chan entity.MailState, notifyQueueSize),
+	}
+}
+
+// Notify queues state without blocking ingestion; when the queue is full the
+// notification is dropped.
+func (n *NewMailNotifier) Notify(state entity.MailState) {
+	if !recentlyReceived(&state) {
+		return
+	}
+	select {
+	case n.queue <- state:
+	default:
+		log.Printf("new mail notifier: queue full, dropped notification for %s", state.S3Key)
+	}
+}
+
+// recentlyReceived tells newly arrived mail from mail found by a backfill
+// scan; mail without a date counts as new.
+func recentlyReceived(state *entity.MailState) bool {
+	return state.MailDate.IsZero() || time.Since(state.MailDate) <= notifyMaxAge
+}
+
+// Run sends queued arrivals, batching those that arrive within
+// notifyBatchWindow of the first one, and releases digests of held
+// notifications.
+func (n *NewMailNotifier) Run(ctx context.Context) {
+	digestTicker := time.NewTicker(digestCheckInterval)
+	defer digestTicker.Stop()
+
+	var batch []entity.MailState
+	var flush <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case state := <-n.queue:
+			batch = append(batch, state)
+			if flush == nil {
+				flush = time.After(notifyBatchWindow)
+			}
+		case <-flush:
+			n.send(batch)
+			batch, flush = nil, nil
+		case <-digestTicker.C:
+			n.releaseDigests()
+		}
+	}
+}
+
+// channelKey identifies a webhook shared by several users' channels.
+type channelKey struct{ Type, WebhookURL string }
+
+// outgoing collects the mails to post to one webhook, each mail once, with
+// the mentions of every rule that routed it there.
+type outgoing struct {
+	order []string
+	mails map[string]*entity.NotifiedMail
+}
+
+func (n *NewMailNotifier) send(batch []entity.MailState) {
+	settings, err := n.userSettingRepo.ListWithNotificationChannels()
+	if err != nil {
+		log.Printf("new mail notifier: failed to load notification channels: %v", err)
+		return
+	}
+	if len(settings) == 0 {
+		return
+	}
+
+	// Users without a selected domain see the first domain, as in the mail list.
+	var defaultDomainID string
+	if domains, err := n.domainRepo.List(); err == nil && len(domains) > 0 {
+		defaultDomainID = domains[0].ID
+	}
+
+	byDomain := make(map[string][]entity.MailState)
+	for _, state := range batch {
+		byDomain[state.DomainID] = append(byDomain[state.DomainID], state)
+	}
+
+	now := time.Now()
+	var keys []channelKey
+	pending := make(map[channelKey]*outgoing)
+	var held []entity.HeldNotification
+	for i := range settings {
+		setting := &settings[i]
+		domainID := setting.SelectedDomainID
+		if domainID == "" {
+			domainID = defaultDomainID
+		}
+		states := byDomain[domainID]
+		if len(states) == 0 {
+			continue
+		}
+
+		quiet := setting.QuietHours.Active(now)
+		channels := channelsByID(setting)
+		for j := range states {
+			state := &states[j]
+			mailID := state.DomainID + "/" + state.S3Key
+			for _, route := range routeMail(setting, state) {
+				mail := n.notifiedMail(state, route.mentions)
+				if quiet {
+					held = append(held, entity.HeldNotification{
+						ID:        uuid.New().String(),
+						UID:       setting.UID,
+						ChannelID: route.channelID,
+						Mail:      *mail,
+					})
+					continue
+				}
+
+				channel := channels[route.channelID]
+				key := channelKey{channel.Type, channel.WebhookURL}
+				out, ok := pending[key]
+				if !ok {
+					out = &outgoing{mails: make(map[string]*entity.NotifiedMail)}
+					pending[key] = out
+					keys = append(keys, key)
+				}
+				if existing, ok := out.mails[mailID]; ok {
+					existing.Mentions = appendUnique(existing.Mentions, mail.Mentions...)
+					continue
+				}
+				out.order = append(out.order, mailID)
+				out.mails[mailID] = mail
+			}
+		}
+	}
+
+	if err := n.heldRepo.Create(held); err != nil {
+		log.Printf("new mail notifier: failed to hold %d notifications: %v", len(held), err)
+	}
+
+	for _, key := range keys {
+		out := pending[key]
+		notification := &entity.MailNotification{Total: len(out.order)}
+		for _, mailID := range out.order {
+			notification.Mails = append(notification.Mails, *out.mails[mailID])
+		}
+		n.post(key, notification)
+	}
+}
+
+// releaseDigests sends the notifications held for users whose quiet hours
+// have ended, one digest per channel.
+func (n *NewMailNotifier) releaseDigests() {
+	uids, err := n.heldRepo.ListUIDs()
+	if err != nil {
+		log.Printf("new mail notifier: failed to list held notifications: %v", err)
+		return
+	}
+
+	now := time.Now()
+	for _, uid := range uids {
+		held, err := n.heldRepo.ListByUID(uid)
+		if err != nil {
+			log.Printf("new mail notifier: failed to load held notifications of %s: %v", uid, err)
+			continue
+		}
+
+		// Without settings the user's channels are gone and their
+		// notifications are dropped; any other error keeps them for later.
+		var channels map[string]entity.NotificationChannel
+		setting, err := n.userSettingRepo.GetByUID(uid)
+		switch {
+		case err == nil:
+			if setting.QuietHours.Active(now) {
+				continue
+			}
+			channels = channelsByID(setting)
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			log.Printf("new mail notifier: failed to load settings of %s: %v", uid, err)
+			continue
+		}
+
+		var channelIDs []string
+		byChannel := make(map[string][]entity.HeldNotification)
+		for _, notification := range held {
+			if _, ok := byChannel[notification.ChannelID]; !ok {
+				channelIDs = append(channelIDs, notification.ChannelID)
+			}
+			byChannel[notification.ChannelID] = append(byChannel[notification.ChannelID], notification)
+		}
+
+		for _, channelID := range channelIDs {
+			notifications := byChannel[channelID]
+			ids := make([]string, 0, len(notifications))
+			digest := &entity.MailNotification{Total: len(notifications), Digest: true}
+			for _, notification := range notifications {
+				ids = append(ids, notification.ID)
+				if len(digest.Mails) < digestMaxMails {
+					digest.Mails = append(digest.Mails, notification.Mail)
+				}
+			}
+
+			// Notifications for a deleted channel are dropped; failed posts
+			// are retried on the next check.
+			if channel, ok := channels[channelID]; ok {
+				if !n.post(channelKey{channel.Type, channel.WebhookURL}, digest) {
+					continue
+				}
+			}
+			if err := n.heldRepo.DeleteByIDs(ids); err != nil {
+				log.Printf("new mail notifier: failed to delete released notifications of %s: %v", uid, err)
+			}
+		}
+	}
+}
+
+func (n *NewMailNotifier) post(key channelKey, notification *entity.MailNotification) bool {
+	notifier, ok := n.notifiers[key.Type]
+	if !ok {
+		log.Printf("new mail notifier: no notifier for channel type %q", key.Type)
+		return false
+	}
+	if err := notifier.Notify(key.WebhookURL, notification); err != nil {
+		log.Printf("new mail notifier: failed to notify %s channel: %v", key.Type, err)
+		return false
+	}
+	return true
+}
+
+type route struct {
+	channelID string
+	mentions  []string
+}
+
+// routeMail returns the channels state is notified to. Every matching rule
+// routes it to its channel with its mention; channels that no rule targets
+// receive the mail addressed to their RecipientAddress (or all mail).
+func routeMail(setting *entity.UserSetting, state *entity.MailState) []route {
+	channels := channelsByID(setting)
+	targeted := make(map[string]bool)
+	var routes []route
+	index := make(map[string]int)
+
+	add := func(channelID, mention string) {
+		i, ok := index[channelID]
+		if !ok {
+			i = len(routes)
+			index[channelID] = i
+			routes = append(routes, route{channelID: channelID})
+		}
+		if mention != "" {
+			routes[i].mentions = appendUnique(routes[i].mentions, mention)
+		}
+	}
+
+	for i := range setting.NotificationRules {
+		rule := &setting.NotificationRules[i]
+		if _, ok := channels[rule.ChannelID]; !ok {
+			continue
+		}
+		targeted[rule.ChannelID] = true
+		if rule.Matches(state) {
+			add(rule.ChannelID, rule.Mention)
+		}
+	}
+
+	for _, channel := range setting.NotificationChannels {
+		id := channelID(channel)
+		if targeted[id] {
+			continue
+		}
+		if channel.RecipientAddress == "" || state.AddressedTo(channel.RecipientAddress) {
+			add(id, "")
+		}
+	}
+	return routes
+}
+
+func channelsByID(setting *entity.UserSetting) map[string]entity.NotificationChannel {
+	channels := make(map[string]entity.NotificationChannel, len(setting.NotificationChannels))
+	for _, channel := range setting.NotificationChannels {
+		channels[channelID(channel)] = channel
+	}
+	return channels
+}
+
+// channelID falls back to the webhook URL for channels saved before
+// channels had IDs.
+func channelID(channel entity.NotificationChannel) string {
+	if channel.ID != "" {
+		return channel.ID
+	}
+	return channel.WebhookURL
+}
+
+func (n *NewMailNotifier) notifiedMail(state *entity.MailState, mentions []string) *entity.NotifiedMail {
+	return &entity.NotifiedMail{
+		From:      state.FromAddress,
+		Recipient: state.RecipientAddress,
+		Subject:   state.Subject,
+		Snippet:   state.Snippet,
+		Date:      state.MailDate,
+		URL:       mailLink(n.appURL, state),
+		Mentions:  mentions,
+	}
+}
+
+// mailLink returns the thread of state, or the mail list for unthreaded mail.
+func mailLink(appURL string, state *entity.MailState) string {
+	if appURL == "" {
+		return ""
+	}
+	if state.ThreadID != nil && *state.ThreadID != "" {
+		return appURL + "/thread/" + *state.ThreadID
+	}
+	return appURL + "/mail"
+}
+
+func appendUnique(values []string, added ...string) []string {
+	for _, value := range added {
+		found := false
+		for _, existing := range values {
+			if existing == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -7,21 +7,30 @@ import (
 
 	"github.com/rikut0904/mailer-backend/internal/domain/entity"
 	"github.com/rikut0904/mailer-backend/internal/domain/repository"
+	webhookuc "github.com/rikut0904/mailer-backend/internal/usecase/webhook"
 	mimeparser "github.com/rikut0904/mailer-backend/pkg/mime"
 )
 
+const syncPageSize = 100
+
 type SyncMailsUseCase struct {
 	mailStateRepo repository.MailStateRepository
 	threadLinkUC  *LinkThreadUseCase
+	notifier      *NewMailNotifier
+	webhookUC     *webhookuc.WebhookUseCase
 }
 
 func NewSyncMailsUseCase(
 	mailStateRepo repository.MailStateRepository,
 	threadLinkUC *LinkThreadUseCase,
+	notifier *NewMailNotifier,
+	webhookUC *webhookuc.WebhookUseCase,
 ) *SyncMailsUseCase {
 	return &SyncMailsUseCase{
 		mailStateRepo: mailStateRepo,
 		threadLinkUC:  threadLinkUC,
+		notifier:      notifier,
+		webhookUC:     webhookUC,
 	}
 }
 
@@ -30,61 +39,127 @@ func (uc *SyncMailsUseCase) Execute(storageRepo repository.MailStorageRepository
 	var continuationToken *string
 
 	for {
-		keys, nextToken, err := storageRepo.ListKeys("", continuationToken, 100)
+		keys, nextToken, err := storageRepo.ListKeys("", continuationToken, syncPageSize)
 		if err != nil {
 			return synced, fmt.Errorf("failed to list S3 keys: %w", err)
 		}
 
 		for _, key := range keys {
-			if strings.HasSuffix(key, "/") {
+			created, err := uc.Ingest(storageRepo, domainID, key)
+			if err != nil {
+				log.Printf("failed to ingest mail %s: %v", key, err)
 				continue
 			}
-
-			existing, _ := uc.mailStateRepo.FindByS3Key(domainID, key)
-			if existing != nil {
-				continue
+			if created {
+				synced++
 			}
+		}
 
-			raw, err := storageRepo.GetObject(key)
-			if err != nil {
-				log.Printf("failed to get S3 object %s: %v", key, err)
-				continue
-			}
+		if nextToken == nil {
+			break
+		}
+		continuationToken = nextToken
+	}
+
+	return synced, nil
+}
+
+// ExecuteAfter syncs only keys that sort after startAfter and returns the last
+// key it listed, which callers keep as a watermark for the next run.
+func (uc *SyncMailsUseCase) ExecuteAfter(storageRepo repository.MailStorageRepository, domainID, startAfter string) (int, string, error) {
+	var synced int
+	lastKey := startAfter
+
+	for {
+		keys, err := storageRepo.ListKeysAfter("", lastKey, syncPageSize)
+		if err != nil {
+			return synced, lastKey, fmt.Errorf("failed to list S3 keys: %w", err)
+		}
 
-			parsed, err := mimeparser.Parse(raw, key)
+		for _, key := range keys {
+			created, err := uc.Ingest(storageRepo, domainID, key)
 			if err != nil {
-				log.Printf("failed to parse mail %s: %v", key, err)
+				log.Printf("failed to ingest mail %s: %v", key, err)
 				continue
 			}
-
-			state := &entity.MailState{
-				S3Key:            key,
-				DomainID:         domainID,
-				RecipientAddress: parsed.To,
-				IsRead:           false,
-				IsStarred:        false,
-				CreatedAt:        parsed.Date,
+			if created {
+				synced++
 			}
+		}
 
-			if err := uc.mailStateRepo.Upsert(state); err != nil {
-				log.Printf("failed to upsert mail state %s: %v", key, err)
-				continue
-			}
+		if len(keys) == 0 {
+			break
+		}
+		lastKey = keys[len(keys)-1]
+		if len(keys) < syncPageSize {
+			break
+		}
+	}
 
-			if uc.threadLinkUC != nil {
-				if threadID, err := uc.threadLinkUC.LinkFromBody(parsed.Body, domainID, key); err == nil && threadID != "" {
-					log.Printf("linked mail %s to thread %s", key, threadID)
-				}
-			}
+	return synced, lastKey, nil
+}
+
+// Ingest is a no-op for keys that are already indexed, so repeated deliveries are safe.
+func (uc *SyncMailsUseCase) Ingest(storageRepo repository.MailStorageRepository, domainID, key string) (bool, error) {
+	if strings.HasSuffix(key, "/") {
+		return false, nil
+	}
+
+	existing, _ := uc.mailStateRepo.FindByS3Key(domainID, key)
+	if existing != nil && existing.IndexedAt != nil {
+		return false, nil
+	}
+
+	raw, err := storageRepo.GetObject(key)
+	if err != nil {
+		return false, fmt.Errorf("failed to get S3 object: %w", err)
+	}
+
+	parsed, err := mimeparser.Parse(raw, key)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse mail: %w", err)
+	}
 
-			synced++
+	if existing != nil {
+		applyMetadata(existing, parsed)
+		if err := uc.mailStateRepo.UpdateMetadata(existing); err != nil {
+			return false, fmt.Errorf("failed to update mail metadata: %w", err)
 		}
+		return false, nil
+	}
 
-		if nextToken == nil {
-			break
+	state := &entity.MailState{
+		S3Key:     key,
+		DomainID:  domainID,
+		IsRead:    false,
+		IsStarred: false,
+		CreatedAt: parsed.Date,
+	}
+	applyMetadata(state, parsed)
+
+	if err := uc.mailStateRepo.Upsert(state); err != nil {
+		return false, fmt.Errorf("failed to upsert mail state: %w", err)
+	}
+
+	if uc.threadLinkUC != nil {
+		if threadID, err := uc.threadLinkUC.Link(parsed, domainID, key); err == nil && threadID != "" {
+			log.Printf("linked mail %s to thread %s", key, threadID)
+			state.ThreadID = &threadID
 		}
-		continuationToken = nextToken
 	}
 
-	return synced, nil
+	// Backfilled history (a first full scan, a newly added domain) is not
+	// announced to webhook subscribers.
+	if recentlyReceived(state) {
+		uc.webhookUC.Publish(entity.WebhookEventMailReceived, webhookuc.NewMailData(state))
+		if state.ThreadID != nil {
+			uc.webhookUC.Publish(entity.WebhookEventThreadLinked, webhookuc.NewMailData(state))
+		}
+	}
+
+	if uc.notifier != nil {
+		uc.notifier.Notify(*state)
+	}
+
+	return true, nil
 }
@@ -0,0 +1,181 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/rikut0904/mailer-backend/internal/domain/entity"
+	"github.com/rikut0904/mailer-backend/internal/domain/repository"
+	"gorm.io/gorm"
+)
+
+const (
+	SyncStatusRunning = "running"
+	SyncStatusOK      = "ok"
+	SyncStatusFailed  = "failed"
+)
+
+// SyncScheduler periodically syncs every configured domain. Each run only
+// lists keys after the stored watermark; because SES object keys are not
+// ordered by arrival, a full scan is still made every fullScanInterval.
+type SyncScheduler struct {
+	domainRepo       repository.S3DomainRepository
+	syncStateRepo    repository.DomainSyncStateRepository
+	syncMailsUC      *SyncMailsUseCase
+	storageFactory   repository.MailStorageFactory
+	interval         time.Duration
+	fullScanInterval time.Duration
+	mu               sync.Mutex
+}
+
+func NewSyncScheduler(
+	domainRepo repository.S3DomainRepository,
+	syncStateRepo repository.DomainSyncStateRepository,
+	syncMailsUC *SyncMailsUseCase,
+	storageFactory repository.MailStorageFactory,
+	interval time.Duration,
+	fullScanInterval time.Duration,
+) *SyncScheduler {
+	return &SyncScheduler{
+		domainRepo:       domainRepo,
+		syncStateRepo:    syncStateRepo,
+		syncMailsUC:      syncMailsUC,
+		storageFactory:   storageFactory,
+		interval:         interval,
+		fullScanInterval: fullScanInterval,
+	}
+}
+
+type DomainSyncStatus struct {
+	DomainName string `json:"domain_name"`
+	entity.DomainSyncState
+}
+
+func (s *SyncScheduler) Run(ctx context.Context) {
+	if s.interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.RunOnce()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *SyncScheduler) RunOnce() {
+	if !s.mu.TryLock() {
+		return
+	}
+	defer s.mu.Unlock()
+
+	domains, err := s.domainRepo.List()
+	if err != nil {
+		log.Printf("sync scheduler: failed to list domains: %v", err)
+		return
+	}
+
+	for i := range domains {
+		if err := s.syncDomain(&domains[i]); err != nil {
+			log.Printf("sync scheduler: domain %s: %v", domains[i].ID, err)
+		}
+	}
+}
+
+func (s *SyncScheduler) Statuses() ([]DomainSyncStatus, error) {
+	domains, err := s.domainRepo.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list domains: %w", err)
+	}
+
+	states, err := s.syncStateRepo.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sync states: %w", err)
+	}
+	byDomain := make(map[string]entity.DomainSyncState, len(states))
+	for _, state := range states {
+		byDomain[state.DomainID] = state
+	}
+
+	statuses := make([]DomainSyncStatus, 0, len(domains))
+	for _, domain := range domains {
+		state, ok := byDomain[domain.ID]
+		if !ok {
+			state = entity.DomainSyncState{DomainID: domain.ID}
+		}
+		statuses = append(statuses, DomainSyncStatus{
+			DomainName:      domain.Name,
+			DomainSyncState: state,
+		})
+	}
+	return statuses, nil
+}
+
+func (s *SyncScheduler) syncDomain(domain *entity.S3Domain) error {
+	state, err := s.syncStateRepo.GetByDomainID(domain.ID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("failed to load sync state: %w", err)
+		}
+		state = &entity.DomainSyncState{DomainID: domain.ID}
+	}
+
+	startedAt := time.Now()
+	fullScan := state.LastFullScanAt == nil || startedAt.Sub(*state.LastFullScanAt) >= s.fullScanInterval
+	startAfter := state.LastKey
+	if fullScan {
+		startAfter = ""
+	}
+
+	state.Status = SyncStatusRunning
+	state.LastRunAt = &startedAt
+	if err := s.syncStateRepo.Save(state); err != nil {
+		return fmt.Errorf("failed to save sync state: %w", err)
+	}
+
+	synced, lastKey, syncErr := s.runSync(domain, startAfter)
+
+	finishedAt := time.Now()
+	state.LastFinishedAt = &finishedAt
+	state.LastSynced = synced
+	state.TotalSynced += int64(synced)
+	if lastKey > state.LastKey {
+		state.LastKey = lastKey
+	}
+
+	if syncErr != nil {
+		state.Status = SyncStatusFailed
+		state.LastError = syncErr.Error()
+	} else {
+		state.Status = SyncStatusOK
+		state.LastError = ""
+		state.LastSuccessAt = &finishedAt
+		if fullScan {
+			state.LastFullScanAt = &startedAt
+		}
+	}
+
+	if err := s.syncStateRepo.Save(state); err != nil {
+		return fmt.Errorf("failed to save sync state: %w", err)
+	}
+	return syncErr
+}
+
+func (s *SyncScheduler) runSync(domain *entity.S3Domain, startAfter string) (int, string, error) {
+	storageRepo, err := s.storageFactory(domain)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to create storage client: %w", err)
+	}
+	return s.syncMailsUC.ExecuteAfter(storageRepo, domain.ID, startAfter)
+}
@@ -0,0 +1,265 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	htmltemplate "html/template"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/rikut0904/mailer-backend/internal/domain/entity"
+	"github.com/rikut0904/mailer-backend/internal/domain/repository"
+	senduc "github.com/rikut0904/mailer-backend/internal/usecase/send"
+)
+
+const (
+	unreadDigestCheckInterval = time.Minute
+	// unreadDigestMailsPerRecipient bounds the mails listed per recipient
+	// address; the unread count is still reported.
+	unreadDigestMailsPerRecipient = 10
+)
+
+// UnreadDigestWorker mails each user a summary of the unread mail of their
+// (selected or default) domain, grouped by recipient address, on the schedule
+// in their settings. Nothing is sent when there is no unread mail. Digests are
+// queued through SendMailUseCase like any other mail, so the from address must
+// be a sender identity and suppressed addresses are skipped.
+type UnreadDigestWorker struct {
+	userSettingRepo repository.UserSettingRepository
+	domainRepo      repository.S3DomainRepository
+	mailStateRepo   repository.MailStateRepository
+	sendMailUC      *senduc.SendMailUseCase
+	fromAddress     string
+	appURL          string
+	mu              sync.Mutex
+}
+
+func NewUnreadDigestWorker(
+	userSettingRepo repository.UserSettingRepository,
+	domainRepo repository.S3DomainRepository,
+	mailStateRepo repository.MailStateRepository,
+	sendMailUC *senduc.SendMailUseCase,
+	fromAddress string,
+	appURL string,
+) *UnreadDigestWorker {
+	return &UnreadDigestWorker{
+		userSettingRepo: userSettingRepo,
+		domainRepo:      domainRepo,
+		mailStateRepo:   mailStateRepo,
+		sendMailUC:      sendMailUC,
+		fromAddress:     fromAddress,
+		appURL:          strings.TrimRight(appURL, "/"),
+	}
+}
+
+// Run does nothing without a from address.
+func (w *UnreadDigestWorker) Run(ctx context.Context) {
+	if w.fromAddress == "" {
+		return
+	}
+
+	ticker := time.NewTicker(unreadDigestCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		w.RunOnce()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *UnreadDigestWorker) RunOnce() {
+	if !w.mu.TryLock() {
+		return
+	}
+	defer w.mu.Unlock()
+
+	settings, err := w.userSettingRepo.ListWithUnreadDigest()
+	if err != nil {
+		log.Printf("unread digest: failed to load settings: %v", err)
+		return
+	}
+
+	now := time.Now().Truncate(time.Second)
+	for i := range settings {
+		setting := &settings[i]
+		// Saving the settings restarts the schedule, so enabling the digest
+		// (or changing it) never sends one for a time already past.
+		since := setting.UpdatedAt
+		if setting.UnreadDigestSentAt != nil && setting.UnreadDigestSentAt.After(since) {
+			since = *setting.UnreadDigestSentAt
+		}
+		next := setting.UnreadDigest.Next(since)
+		if next.IsZero() || next.After(now) {
+			continue
+		}
+
+		// Claiming first keeps another instance from sending the same digest;
+		// a failed send is not retried before the next scheduled time.
+		claimed, err := w.userSettingRepo.ClaimUnreadDigest(setting.UID, setting.UnreadDigestSentAt, now)
+		if err != nil {
+			log.Printf("unread digest: failed to claim digest of %s: %v", setting.UID, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+		if err := w.send(setting); err != nil {
+			log.Printf("unread digest: failed to send digest of %s: %v", setting.UID, err)
+		}
+	}
+}
+
+type digestRecipient struct {
+	Address string
+	Unread  int64
+	More    int64
+	Mails   []digestMail
+}
+
+type digestMail struct {
+	From    string
+	Subject string
+	Snippet string
+	Date    string
+	URL     string
+}
+
+type digestData struct {
+	Domain     string
+	Total      int64
+	Recipients []digestRecipient
+	AppURL     string
+}
+
+func (w *UnreadDigestWorker) send(setting *entity.UserSetting) error {
+	domains, err := w.domainRepo.List()
+	if err != nil {
+		return fmt.Errorf("failed to list domains: %w", err)
+	}
+	var domain *entity.S3Domain
+	for i := range domains {
+		if domains[i].ID == setting.SelectedDomainID || (setting.SelectedDomainID == "" && i == 0) {
+			domain = &domains[i]
+			break
+		}
+	}
+	if domain == nil {
+		return nil
+	}
+
+	recipients, err := w.mailStateRepo.ListRecipients(domain.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list recipients: %w", err)
+	}
+	sort.Strings(recipients)
+
+	location, err := time.LoadLocation(setting.UnreadDigest.TimeZone)
+	if err != nil {
+		location = time.UTC
+	}
+
+	data := digestData{Domain: domain.Name, AppURL: w.appURL}
+	for _, address := range recipients {
+		unread, err := w.mailStateRepo.CountUnread(domain.ID, address)
+		if err != nil {
+			return fmt.Errorf("failed to count unread mail: %w", err)
+		}
+		if unread == 0 {
+			continue
+		}
+		states, err := w.mailStateRepo.FindUnread(domain.ID, address, unreadDigestMailsPerRecipient)
+		if err != nil {
+			return fmt.Errorf("failed to load unread mail: %w", err)
+		}
+
+		recipient := digestRecipient{Address: address, Unread: unread, More: unread - int64(len(states))}
+		for j := range states {
+			state := &states[j]
+			date := state.MailDate
+			if date.IsZero() {
+				date = state.CreatedAt
+			}
+			subject := state.Subject
+			if subject == "" {
+				subject = "(件名なし)"
+			}
+			recipient.Mails = append(recipient.Mails, digestMail{
+				From:    state.FromAddress,
+				Subject: subject,
+				Snippet: state.Snippet,
+				Date:    date.In(location).Format("2006/01/02 15:04"),
+				URL:     mailLink(w.appURL, state),
+			})
+		}
+		data.Recipients = append(data.Recipients, recipient)
+		data.Total += unread
+	}
+	if data.Total == 0 {
+		return nil
+	}
+
+	var text, html bytes.Buffer
+	if err := digestTextTemplate.Execute(&text, data); err != nil {
+		return fmt.Errorf("failed to render digest: %w", err)
+	}
+	if err := digestHTMLTemplate.Execute(&html, data); err != nil {
+		return fmt.Errorf("failed to render digest: %w", err)
+	}
+
+	_, err = w.sendMailUC.Execute(&senduc.SendRequest{
+		To:            []string{setting.UnreadDigest.ToAddress},
+		Subject:       fmt.Sprintf("未読メール %d 件 (%s)", data.Total, domain.Name),
+		Body:          text.String(),
+		HTMLBody:      html.String(),
+		SendType:      "new",
+		FromAddress:   w.fromAddress,
+		OmitSignature: true,
+		UID:           setting.UID,
+		System:        true,
+	})
+	return err
+}
+
+var digestTextTemplate = texttemplate.Must(texttemplate.New("text").Parse(
+	`{{.Domain}} に未読メールが {{.Total}} 件あります。
+{{range .Recipients}}
+■ {{.Address}}（未読 {{.Unread}} 件）
+{{range .Mails}}
+- {{.Subject}}
+  {{.From}} / {{.Date}}
+{{- if .URL}}
+  {{.URL}}
+{{- end}}
+{{end}}
+{{- if gt .More 0}}
+ほか {{.More}} 件
+{{end}}{{end}}
+{{- if .AppURL}}
+{{.AppURL}}/mail
+{{end}}`))
+
+var digestHTMLTemplate = htmltemplate.Must(htmltemplate.New("html").Parse(
+	`<p>{{.Domain}} に未読メールが {{.Total}} 件あります。</p>
+{{range .Recipients}}
+<h3 style="margin:16px 0 4px">{{.Address}}（未読 {{.Unread}} 件）</h3>
+<ul style="margin:0;padding-left:20px">
+{{range .Mails}}<li style="margin-bottom:8px">
+{{if .URL}}<a href="{{.URL}}">{{.Subject}}</a>{{else}}{{.Subject}}{{end}}<br>
+<span style="color:#666">{{.From}} / {{.Date}}</span>
+{{if .Snippet}}<br><span style="color:#666">{{.Snippet}}</span>{{end}}
+</li>
+{{end}}</ul>
+{{if gt .More 0}}<p style="margin:4px 0">ほか {{.More}} 件</p>{{end}}
+{{end}}
+{{if .AppURL}}<p><a href="{{.AppURL}}/mail">メールを開く</a></p>{{end}}
+`))
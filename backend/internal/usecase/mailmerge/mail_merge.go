@@ -0,0 +1,174 @@
+package mailmerge
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rikut0904/mailer-backend/internal/domain/entity"
+	templateuc "github.com/rikut0904/mailer-backend/internal/usecase/mailtemplate"
+	senduc "github.com/rikut0904/mailer-backend/internal/usecase/send"
+)
+
+// MaxRows caps the rows of one mail merge.
+const MaxRows = 5000
+
+// ErrInvalidCSV is returned for CSV files that cannot be merged, e.g. without
+// an email column or with a malformed address.
+var ErrInvalidCSV = errors.New("invalid mail merge CSV")
+
+// MailMergeUseCase renders a template for every row of a CSV and queues the
+// results as one merge batch; OutboxWorker then delivers it like any other
+// batch, so a merge survives restarts and follows the sending rate limit.
+type MailMergeUseCase struct {
+	sendMailUC *senduc.SendMailUseCase
+	templateUC *templateuc.MailTemplateUseCase
+}
+
+func NewMailMergeUseCase(sendMailUC *senduc.SendMailUseCase, templateUC *templateuc.MailTemplateUseCase) *MailMergeUseCase {
+	return &MailMergeUseCase{
+		sendMailUC: sendMailUC,
+		templateUC: templateUC,
+	}
+}
+
+// Execute merges csvData with the template named by req.TemplateID, or with
+// req's subject and bodies used as template sources. The CSV needs a header
+// row with an "email" column; every column is available to the template
+// under its header name, and req.Variables supplies defaults.
+func (uc *MailMergeUseCase) Execute(req *senduc.SendRequest, csvData io.Reader) (*senduc.SendResponse, error) {
+	template := &entity.MailTemplate{
+		Subject:  req.Subject,
+		TextBody: req.Body,
+		HTMLBody: req.HTMLBody,
+	}
+	if req.TemplateID != "" {
+		stored, err := uc.templateUC.Get(req.UID, req.TemplateID)
+		if err != nil {
+			return nil, err
+		}
+		template = stored
+	}
+	if strings.TrimSpace(template.Subject) == "" || strings.TrimSpace(template.TextBody) == "" {
+		return nil, fmt.Errorf("%w: subject and body are required", templateuc.ErrTemplateInvalid)
+	}
+
+	records, err := readCSV(csvData)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]senduc.MergeRow, 0, len(records))
+	for i, record := range records {
+		vars := make(map[string]any, len(req.Variables)+len(record))
+		for key, value := range req.Variables {
+			vars[key] = value
+		}
+		for key, value := range record {
+			vars[key] = value
+		}
+
+		rendered, err := templateuc.Render(template, vars)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", i+1, err)
+		}
+		rows = append(rows, senduc.MergeRow{
+			Email:    record["email"],
+			Subject:  rendered.Subject,
+			Body:     rendered.TextBody,
+			HTMLBody: rendered.HTMLBody,
+		})
+	}
+
+	req.Subject = template.Subject
+	return uc.sendMailUC.ExecuteMerge(req, rows)
+}
+
+// WriteReport writes the state of every row of a merge batch as CSV.
+func (uc *MailMergeUseCase) WriteReport(w io.Writer, status *senduc.SendStatus) error {
+	out := csv.NewWriter(w)
+	if err := out.Write([]string{"row", "email", "management_code", "status", "attempts", "sent_at", "error"}); err != nil {
+		return err
+	}
+	for _, message := range status.Recipients {
+		sentAt := ""
+		if message.SentAt != nil {
+			sentAt = message.SentAt.Format(time.RFC3339)
+		}
+		if err := out.Write([]string{
+			strconv.Itoa(message.RowNumber),
+			message.RecipientEmail,
+			message.ManagementCode,
+			message.Status,
+			strconv.Itoa(message.Attempts),
+			sentAt,
+			message.LastError,
+		}); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// readCSV returns every data row keyed by header name. The email column is
+// matched case-insensitively and stored under "email".
+func readCSV(data io.Reader) ([]map[string]string, error) {
+	reader := csv.NewReader(data)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: missing header row", ErrInvalidCSV)
+	}
+	// Spreadsheet exports often start with a UTF-8 BOM.
+	header[0] = strings.TrimPrefix(header[0], "\ufeff")
+
+	emailColumn := -1
+	for i, name := range header {
+		header[i] = strings.TrimSpace(name)
+		if strings.EqualFold(header[i], "email") {
+			header[i] = "email"
+			emailColumn = i
+		}
+	}
+	if emailColumn == -1 {
+		return nil, fmt.Errorf("%w: an email column is required", ErrInvalidCSV)
+	}
+
+	var records []map[string]string
+	for {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidCSV, err)
+		}
+		if len(records) == MaxRows {
+			return nil, fmt.Errorf("%w: more than %d rows", ErrInvalidCSV, MaxRows)
+		}
+
+		row := len(records) + 1
+		email := strings.TrimSpace(fields[emailColumn])
+		if _, err := mail.ParseAddress(email); err != nil {
+			return nil, fmt.Errorf("%w: row %d: invalid email %q", ErrInvalidCSV, row, email)
+		}
+
+		record := make(map[string]string, len(header))
+		for i, name := range header {
+			record[name] = fields[i]
+		}
+		record["email"] = email
+		records = append(records, record)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: no rows", ErrInvalidCSV)
+	}
+	return records, nil
+}
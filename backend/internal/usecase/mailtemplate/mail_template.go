@@ -0,0 +1,219 @@
+package mailtemplate
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/google/uuid"
+	"github.com/rikut0904/mailer-backend/internal/domain/entity"
+	"github.com/rikut0904/mailer-backend/internal/domain/repository"
+	senduc "github.com/rikut0904/mailer-backend/internal/usecase/send"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTemplateNotFound = errors.New("template not found")
+	// ErrTemplateInvalid is returned when a template source does not parse.
+	ErrTemplateInvalid = errors.New("invalid template")
+	// ErrTemplateRender is returned when rendering fails, e.g. a variable
+	// used by the template was not given.
+	ErrTemplateRender = errors.New("failed to render template")
+)
+
+// MailTemplateUseCase manages the templates of a single Firebase user; every
+// method takes the uid and treats other users' templates as missing.
+type MailTemplateUseCase struct {
+	templateRepo repository.MailTemplateRepository
+}
+
+func NewMailTemplateUseCase(templateRepo repository.MailTemplateRepository) *MailTemplateUseCase {
+	return &MailTemplateUseCase{templateRepo: templateRepo}
+}
+
+type TemplateInput struct {
+	Name     string `json:"name"`
+	Subject  string `json:"subject"`
+	TextBody string `json:"text_body"`
+	HTMLBody string `json:"html_body"`
+}
+
+// Rendered is a template executed with a set of variables.
+type Rendered struct {
+	Subject  string `json:"subject"`
+	TextBody string `json:"text_body"`
+	HTMLBody string `json:"html_body,omitempty"`
+}
+
+func (uc *MailTemplateUseCase) List(uid string) ([]entity.MailTemplate, error) {
+	templates, err := uc.templateRepo.ListByUID(uid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list templates: %w", err)
+	}
+	return templates, nil
+}
+
+func (uc *MailTemplateUseCase) Get(uid, id string) (*entity.MailTemplate, error) {
+	template, err := uc.templateRepo.GetByID(uid, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTemplateNotFound
+		}
+		return nil, fmt.Errorf("failed to load template: %w", err)
+	}
+	return template, nil
+}
+
+func (uc *MailTemplateUseCase) Create(uid string, input *TemplateInput) (*entity.MailTemplate, error) {
+	if err := input.validate(); err != nil {
+		return nil, err
+	}
+
+	template := &entity.MailTemplate{
+		ID:       uuid.New().String(),
+		UID:      uid,
+		Name:     strings.TrimSpace(input.Name),
+		Subject:  input.Subject,
+		TextBody: input.TextBody,
+		HTMLBody: input.HTMLBody,
+	}
+	if err := uc.templateRepo.Create(template); err != nil {
+		return nil, fmt.Errorf("failed to create template: %w", err)
+	}
+	return template, nil
+}
+
+func (uc *MailTemplateUseCase) Update(uid, id string, input *TemplateInput) (*entity.MailTemplate, error) {
+	if err := input.validate(); err != nil {
+		return nil, err
+	}
+
+	template, err := uc.Get(uid, id)
+	if err != nil {
+		return nil, err
+	}
+	template.Name = strings.TrimSpace(input.Name)
+	template.Subject = input.Subject
+	template.TextBody = input.TextBody
+	template.HTMLBody = input.HTMLBody
+
+	if err := uc.templateRepo.Update(template); err != nil {
+		return nil, fmt.Errorf("failed to update template: %w", err)
+	}
+	return template, nil
+}
+
+func (uc *MailTemplateUseCase) Delete(uid, id string) error {
+	if err := uc.templateRepo.Delete(uid, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTemplateNotFound
+		}
+		return fmt.Errorf("failed to delete template: %w", err)
+	}
+	return nil
+}
+
+// Apply renders req.TemplateID with req.Variables and uses the result for the
+// subject and bodies the request leaves empty. Requests without a template are
+// left untouched.
+func (uc *MailTemplateUseCase) Apply(req *senduc.SendRequest) error {
+	if req.TemplateID == "" {
+		return nil
+	}
+
+	template, err := uc.Get(req.UID, req.TemplateID)
+	if err != nil {
+		return err
+	}
+	rendered, err := Render(template, req.Variables)
+	if err != nil {
+		return err
+	}
+
+	if req.Subject == "" {
+		req.Subject = rendered.Subject
+	}
+	if req.Body == "" {
+		req.Body = rendered.TextBody
+	}
+	if req.HTMLBody == "" {
+		req.HTMLBody = rendered.HTMLBody
+	}
+	return nil
+}
+
+// Render executes the subject and text body with text/template and the HTML
+// body with html/template, which escapes variables for their HTML context.
+// Variables the template refers to but vars lacks are reported as errors.
+func Render(template *entity.MailTemplate, vars map[string]any) (*Rendered, error) {
+	if vars == nil {
+		vars = map[string]any{}
+	}
+
+	subject, err := renderText("subject", template.Subject, vars)
+	if err != nil {
+		return nil, err
+	}
+	textBody, err := renderText("text_body", template.TextBody, vars)
+	if err != nil {
+		return nil, err
+	}
+
+	var htmlBody string
+	if template.HTMLBody != "" {
+		tmpl, err := htmltemplate.New("html_body").Option("missingkey=error").Parse(template.HTMLBody)
+		if err != nil {
+			return nil, fmt.Errorf("%w: html_body: %v", ErrTemplateInvalid, err)
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, vars); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrTemplateRender, err)
+		}
+		htmlBody = buf.String()
+	}
+
+	return &Rendered{
+		// Subjects are a single header line.
+		Subject:  strings.Join(strings.Fields(subject), " "),
+		TextBody: textBody,
+		HTMLBody: htmlBody,
+	}, nil
+}
+
+func renderText(name, source string, vars map[string]any) (string, error) {
+	tmpl, err := texttemplate.New(name).Option("missingkey=error").Parse(source)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s: %v", ErrTemplateInvalid, name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, vars); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrTemplateRender, err)
+	}
+	return buf.String(), nil
+}
+
+func (input *TemplateInput) validate() error {
+	if strings.TrimSpace(input.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrTemplateInvalid)
+	}
+	if strings.TrimSpace(input.Subject) == "" {
+		return fmt.Errorf("%w: subject is required", ErrTemplateInvalid)
+	}
+	if strings.TrimSpace(input.TextBody) == "" {
+		return fmt.Errorf("%w: text_body is required", ErrTemplateInvalid)
+	}
+
+	if _, err := texttemplate.New("subject").Parse(input.Subject); err != nil {
+		return fmt.Errorf("%w: subject: %v", ErrTemplateInvalid, err)
+	}
+	if _, err := texttemplate.New("text_body").Parse(input.TextBody); err != nil {
+		return fmt.Errorf("%w: text_body: %v", ErrTemplateInvalid, err)
+	}
+	if _, err := htmltemplate.New("html_body").Parse(input.HTMLBody); err != nil {
+		return fmt.Errorf("%w: html_body: %v", ErrTemplateInvalid, err)
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package send
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/rikut0904/mailer-backend/internal/domain/entity"
+	"github.com/rikut0904/mailer-backend/internal/domain/repository"
+	webhookuc "github.com/rikut0904/mailer-backend/internal/usecase/webhook"
+)
+
+// sesNotification covers both SES identity notifications (notificationType)
+// and configuration-set event publishing (eventType).
+type sesNotification struct {
+	NotificationType string `json:"notificationType"`
+	EventType        string `json:"eventType"`
+	Mail             struct {
+		MessageID     string `json:"messageId"`
+		CommonHeaders struct {
+			MessageID string `json:"messageId"`
+		} `json:"commonHeaders"`
+	} `json:"mail"`
+	Bounce *struct {
+		BounceType        string `json:"bounceType"`
+		BounceSubType     string `json:"bounceSubType"`
+		BouncedRecipients []struct {
+			EmailAddress   string `json:"emailAddress"`
+			DiagnosticCode string `json:"diagnosticCode"`
+		} `json:"bouncedRecipients"`
+		Timestamp time.Time `json:"timestamp"`
+	} `json:"bounce"`
+	Complaint *struct {
+		ComplaintFeedbackType string `json:"complaintFeedbackType"`
+		ComplainedRecipients  []struct {
+			EmailAddress string `json:"emailAddress"`
+		} `json:"complainedRecipients"`
+		Timestamp time.Time `json:"timestamp"`
+	} `json:"complaint"`
+	Delivery *struct {
+		Recipients   []string  `json:"recipients"`
+		SMTPResponse string    `json:"smtpResponse"`
+		Timestamp    time.Time `json:"timestamp"`
+	} `json:"delivery"`
+}
+
+// deliveryUpdate is the outcome reported for one recipient.
+type deliveryUpdate struct {
+	Email    string
+	Status   string
+	Detail   string
+	Suppress string // suppression reason, empty when the address stays usable
+}
+
+// deliveryRank keeps a late Delivery notification from hiding an earlier
+// complaint or hard bounce.
+var deliveryRank = map[string]int{
+	entity.DeliveryStatusSent:        0,
+	entity.DeliveryStatusSoftBounced: 1,
+	entity.DeliveryStatusDelivered:   2,
+	entity.DeliveryStatusBounced:     3,
+	entity.DeliveryStatusComplained:  4,
+}
+
+type DeliveryFeedbackUseCase struct {
+	sentMailRepo    repository.SentMailRepository
+	suppressionRepo repository.SuppressionRepository
+	webhookUC       *webhookuc.WebhookUseCase
+}
+
+func NewDeliveryFeedbackUseCase(
+	sentMailRepo repository.SentMailRepository,
+	suppressionRepo repository.SuppressionRepository,
+	webhookUC *webhookuc.WebhookUseCase,
+) *DeliveryFeedbackUseCase {
+	return &DeliveryFeedbackUseCase{
+		sentMailRepo:    sentMailRepo,
+		suppressionRepo: suppressionRepo,
+		webhookUC:       webhookUC,
+	}
+}
+
+// Handle applies an SES bounce, complaint or delivery notification to the
+// matching sent mails and returns how many were updated. Hard bounces and
+// complaints are added to the suppression list even when no sent mail matches.
+func (uc *DeliveryFeedbackUseCase) Handle(payload []byte) (int, error) {
+	var notification sesNotification
+	if err := json.Unmarshal(payload, &notification); err != nil {
+		return 0, fmt.Errorf("invalid SES notification")
+	}
+
+	updates, at := notification.updates()
+	if len(updates) == 0 {
+		return 0, nil
+	}
+
+	sentMails, err := uc.findSentMails(&notification)
+	if err != nil {
+		return 0, err
+	}
+	byRecipient := make(map[string][]entity.SentMail, len(sentMails))
+	for _, sent := range sentMails {
+		key := strings.ToLower(sent.RecipientEmail)
+		byRecipient[key] = append(byRecipient[key], sent)
+	}
+
+	updated := 0
+	for _, update := range updates {
+		managementCode := ""
+		for _, sent := range byRecipient[strings.ToLower(update.Email)] {
+			managementCode = sent.ManagementCode
+			if deliveryRank[update.Status] < deliveryRank[sent.DeliveryStatus] {
+				continue
+			}
+			if err := uc.sentMailRepo.UpdateDeliveryStatus(sent.ManagementCode, update.Status, update.Detail, at); err != nil {
+				return updated, fmt.Errorf("failed to update delivery status: %w", err)
+			}
+			updated++
+
+			if isBounce(update.Status) && sent.DeliveryStatus != update.Status {
+				data := webhookuc.NewSentMailData(&sent)
+				data.DeliveryStatus = update.Status
+				data.Detail = update.Detail
+				uc.webhookUC.Publish(entity.WebhookEventMailBounced, data)
+			}
+		}
+
+		if update.Suppress == "" {
+			continue
+		}
+		if err := uc.suppressionRepo.Upsert(&entity.SuppressedAddress{
+			Email:          update.Email,
+			Reason:         update.Suppress,
+			Detail:         update.Detail,
+			ManagementCode: managementCode,
+		}); err != nil {
+			return updated, fmt.Errorf("failed to suppress %s: %w", update.Email, err)
+		}
+		log.Printf("delivery feedback: suppressed %s (%s)", update.Email, update.Suppress)
+	}
+	return updated, nil
+}
+
+// findSentMails matches on the SES message ID and falls back to the
+// Message-ID header (ours or the one SES assigned) for mails sent before it
+// was recorded.
+func (uc *DeliveryFeedbackUseCase) findSentMails(notification *sesNotification) ([]entity.SentMail, error) {
+	if id := notification.Mail.MessageID; id != "" {
+		sentMails, err := uc.sentMailRepo.FindByProviderMessageID(id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find sent mails: %w", err)
+		}
+		if len(sentMails) > 0 {
+			return sentMails, nil
+		}
+	}
+
+	if id := notification.Mail.CommonHeaders.MessageID; id != "" {
+		sentMails, err := uc.sentMailRepo.FindByMessageIDs([]string{id})
+		if err != nil {
+			return nil, fmt.Errorf("failed to find sent mails: %w", err)
+		}
+		return sentMails, nil
+	}
+	return nil, nil
+}
+
+func (n *sesNotification) updates() ([]deliveryUpdate, time.Time) {
+	kind := n.NotificationType
+	if kind == "" {
+		kind = n.EventType
+	}
+
+	var updates []deliveryUpdate
+	switch {
+	case kind == "Bounce" && n.Bounce != nil:
+		status, suppress := entity.DeliveryStatusSoftBounced, ""
+		if n.Bounce.BounceType == "Permanent" {
+			status, suppress = entity.DeliveryStatusBounced, entity.SuppressionReasonBounce
+		}
+		for _, r := range n.Bounce.BouncedRecipients {
+			detail := r.DiagnosticCode
+			if detail == "" {
+				detail = strings.TrimSpace(n.Bounce.BounceType + " " + n.Bounce.BounceSubType)
+			}
+			updates = append(updates, deliveryUpdate{Email: r.EmailAddress, Status: status, Detail: detail, Suppress: suppress})
+		}
+		return updates, timestampOrNow(n.Bounce.Timestamp)
+	case kind == "Complaint" && n.Complaint != nil:
+		for _, r := range n.Complaint.ComplainedRecipients {
+			updates = append(updates, deliveryUpdate{
+				Email:    r.EmailAddress,
+				Status:   entity.DeliveryStatusComplained,
+				Detail:   n.Complaint.ComplaintFeedbackType,
+				Suppress: entity.SuppressionReasonComplaint,
+			})
+		}
+		return updates, timestampOrNow(n.Complaint.Timestamp)
+	case kind == "Delivery" && n.Delivery != nil:
+		for _, email := range n.Delivery.Recipients {
+			updates = append(updates, deliveryUpdate{Email: email, Status: entity.DeliveryStatusDelivered, Detail: n.Delivery.SMTPResponse})
+		}
+		return updates, timestampOrNow(n.Delivery.Timestamp)
+	}
+	return nil, time.Time{}
+}
+
+func timestampOrNow(t time.Time) time.Time {
+	if t.IsZero() {
+		return time.Now()
+	}
+	return t
+}
+
+func isBounce(status string) bool {
+	return status == entity.DeliveryStatusBounced || status == entity.DeliveryStatusSoftBounced
+}
@@ -0,0 +1,95 @@
+package send
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/rikut0904/mailer-backend/internal/domain/entity"
+)
+
+// MergeRow is one personalised copy of a mail merge, already rendered.
+type MergeRow struct {
+	Email    string
+	Subject  string
+	Body     string
+	HTMLBody string
+}
+
+// ExecuteMerge queues a merge batch sending every row as its own message, each
+// with a child management code under one new thread. req supplies the sender,
+// attachments, schedule and thread name (Subject); its recipients and bodies
+// are ignored. The rows are stored apart from the batch payload, one per
+// recipient. Suppressed addresses are rejected like in Execute; ones
+// suppressed while the batch waits fail at release and show up in the report.
+func (uc *SendMailUseCase) ExecuteMerge(req *SendRequest, rows []MergeRow) (*SendResponse, error) {
+	if strings.TrimSpace(req.FromAddress) == "" {
+		return nil, fmt.Errorf("from_address is required")
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("at least one row is required")
+	}
+
+	largest := *req
+	for _, row := range rows {
+		if len(row.Subject)+len(row.Body)+len(row.HTMLBody) > len(largest.Subject)+len(largest.Body)+len(largest.HTMLBody) {
+			largest.Subject, largest.Body, largest.HTMLBody = row.Subject, row.Body, row.HTMLBody
+		}
+	}
+	if err := validateMessageSize(&largest); err != nil {
+		return nil, err
+	}
+	recipients := make([]recipient, 0, len(rows))
+	for _, row := range rows {
+		recipients = append(recipients, recipient{Address: strings.TrimSpace(row.Email)})
+	}
+	if err := uc.checkSuppressed(recipients); err != nil {
+		return nil, err
+	}
+
+	identity, err := uc.findIdentity(req.FromAddress, req.UID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := newOutboxPayload(req, identity)
+	payload.To, payload.Cc, payload.Bcc = nil, nil, nil
+	payload.InReplyTo, payload.References, payload.ReplyCode = "", nil, ""
+	encoded, err := encodeOutboxPayload(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode outbox payload: %w", err)
+	}
+
+	batch := &entity.OutboxBatch{
+		ID:       uuid.New().String(),
+		UID:      req.UID,
+		ThreadID: uuid.New().String(),
+		Kind:     entity.OutboxBatchKindMerge,
+		SendType: "new",
+		Subject:  req.Subject,
+		Status:   entity.OutboxBatchStatusScheduled,
+		SendAt:   uc.sendAt(req.SendAt),
+		Payload:  encoded,
+	}
+	mergeRows := make([]entity.OutboxMergeRow, 0, len(rows))
+	for i, row := range rows {
+		mergeRows = append(mergeRows, entity.OutboxMergeRow{
+			BatchID:   batch.ID,
+			RowNumber: i + 1,
+			Email:     recipients[i].Address,
+			Subject:   row.Subject,
+			TextBody:  row.Body,
+			HTMLBody:  row.HTMLBody,
+		})
+	}
+	if err := uc.outboxRepo.CreateMergeBatch(batch, mergeRows); err != nil {
+		return nil, fmt.Errorf("failed to queue mail merge: %w", err)
+	}
+
+	return &SendResponse{
+		BatchID:  batch.ID,
+		ThreadID: batch.ThreadID,
+		Status:   batch.Status,
+		SendAt:   batch.SendAt,
+	}, nil
+}
@@ -0,0 +1,217 @@
+package send
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rikut0904/mailer-backend/internal/domain/entity"
+)
+
+// outboxPayload is the content of a queued send request, shared by all of its
+// recipients. Sender identity options are captured at queue time.
+type outboxPayload struct {
+	From             string             `json:"from"`
+	FromName         string             `json:"from_name,omitempty"`
+	To               []string           `json:"to"`
+	Cc               []string           `json:"cc,omitempty"`
+	Bcc              []string           `json:"bcc,omitempty"`
+	ReplyTo          string             `json:"reply_to,omitempty"`
+	Subject          string             `json:"subject"`
+	TextBody         string             `json:"text_body"`
+	HTMLBody         string             `json:"html_body,omitempty"`
+	InReplyTo        string             `json:"in_reply_to,omitempty"`
+	References       []string           `json:"references,omitempty"`
+	ReplyCode        string             `json:"reply_code,omitempty"`
+	Attachments      []outboxAttachment `json:"attachments,omitempty"`
+	OmitCodeFromBody bool               `json:"omit_code_from_body,omitempty"`
+	ReplyDomain      string             `json:"reply_domain,omitempty"`
+	ReturnPath       string             `json:"return_path,omitempty"`
+	SignatureText    string             `json:"signature_text,omitempty"`
+	SignatureHTML    string             `json:"signature_html,omitempty"`
+	// Codes are the management codes issued at queue time, keyed by the
+	// lower-cased recipient address.
+	Codes map[string]string `json:"codes,omitempty"`
+}
+
+type outboxAttachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Content     []byte `json:"content"`
+}
+
+func newOutboxPayload(req *SendRequest, identity *entity.SenderIdentity) *outboxPayload {
+	payload := &outboxPayload{
+		From:             identity.Address,
+		FromName:         identity.DisplayName,
+		To:               req.To,
+		Cc:               req.Cc,
+		Bcc:              req.Bcc,
+		ReplyTo:          req.ReplyTo,
+		Subject:          req.Subject,
+		TextBody:         req.Body,
+		HTMLBody:         req.HTMLBody,
+		InReplyTo:        req.InReplyTo,
+		References:       req.References,
+		ReplyCode:        req.ReplyCode,
+		OmitCodeFromBody: identity.OmitCodeFromBody,
+		ReplyDomain:      identity.ReplyDomain,
+		ReturnPath:       identity.ReturnPath,
+	}
+	if !req.OmitSignature {
+		payload.SignatureText = identity.SignatureText
+		payload.SignatureHTML = identity.SignatureHTML
+	}
+	for _, att := range req.Attachments {
+		payload.Attachments = append(payload.Attachments, outboxAttachment{
+			Filename:    att.Filename,
+			ContentType: att.ContentType,
+			Content:     att.Content,
+		})
+	}
+	return payload
+}
+
+func (p *outboxPayload) attachments() []entity.Attachment {
+	attachments := make([]entity.Attachment, 0, len(p.Attachments))
+	for _, att := range p.Attachments {
+		attachments = append(attachments, entity.Attachment{
+			Filename:    att.Filename,
+			ContentType: att.ContentType,
+			Size:        len(att.Content),
+			Content:     att.Content,
+		})
+	}
+	return attachments
+}
+
+// issueCodes assigns a management code to every recipient, reusing the ones in
+// previous, and returns them in recipient order.
+func (p *outboxPayload) issueCodes(previous map[string]string) []string {
+	recipients := collectRecipients(p.To, p.Cc, p.Bcc)
+	p.Codes = make(map[string]string, len(recipients))
+	codes := make([]string, 0, len(recipients))
+	for _, rcpt := range recipients {
+		key := strings.ToLower(rcpt.Address)
+		code, ok := previous[key]
+		if !ok {
+			code = uuid.New().String()
+		}
+		p.Codes[key] = code
+		codes = append(codes, code)
+	}
+	return codes
+}
+
+// messages creates a message with its own Message-ID for every recipient,
+// using the codes issued at queue time. Replies get new codes too; the
+// answered code is kept as their parent.
+func (p *outboxPayload) messages(batch *entity.OutboxBatch, now time.Time) []entity.OutboxMessage {
+	recipients := collectRecipients(p.To, p.Cc, p.Bcc)
+	messages := make([]entity.OutboxMessage, 0, len(recipients))
+	for _, rcpt := range recipients {
+		code, ok := p.Codes[strings.ToLower(rcpt.Address)]
+		if !ok {
+			code = uuid.New().String()
+		}
+		messages = append(messages, entity.OutboxMessage{
+			ID:             uuid.New().String(),
+			BatchID:        batch.ID,
+			ManagementCode: code,
+			MessageID:      newMessageID(p.From),
+			RecipientEmail: rcpt.Address,
+			RecipientType:  rcpt.Type,
+			Status:         entity.OutboxStatusQueued,
+			NextAttemptAt:  now,
+		})
+	}
+	return messages
+}
+
+// rowMessages issues one message per merge row, duplicates included.
+func (p *outboxPayload) rowMessages(batch *entity.OutboxBatch, rows []entity.OutboxMergeRow, now time.Time) []entity.OutboxMessage {
+	messages := make([]entity.OutboxMessage, 0, len(rows))
+	for _, row := range rows {
+		messages = append(messages, entity.OutboxMessage{
+			ID:             uuid.New().String(),
+			BatchID:        batch.ID,
+			RowNumber:      row.RowNumber,
+			ManagementCode: uuid.New().String(),
+			MessageID:      newMessageID(p.From),
+			RecipientEmail: row.Email,
+			RecipientType:  entity.RecipientTypeTo,
+			Status:         entity.OutboxStatusQueued,
+			NextAttemptAt:  now,
+		})
+	}
+	return messages
+}
+
+func encodeOutboxPayload(payload *outboxPayload) ([]byte, error) {
+	return json.Marshal(payload)
+}
+
+func decodeOutboxPayload(data []byte) (*outboxPayload, error) {
+	var payload outboxPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, err
+	}
+	return &payload, nil
+}
+
+// outgoingFor builds the copy addressed to a single outbox recipient, with the
+// content of row for a merge batch.
+func (p *outboxPayload) outgoingFor(message *entity.OutboxMessage, row *entity.OutboxMergeRow) *entity.OutgoingMail {
+	code := message.ManagementCode
+
+	to, cc := p.To, p.Cc
+	subject, textBody, htmlBody := p.Subject, p.TextBody, p.HTMLBody
+	if row != nil {
+		to, cc = []string{row.Email}, nil
+		subject, textBody, htmlBody = row.Subject, row.TextBody, row.HTMLBody
+	}
+
+	if p.SignatureText != "" {
+		textBody = appendSignature(textBody, p.SignatureText)
+	}
+	if htmlBody != "" && (p.SignatureHTML != "" || p.SignatureText != "") {
+		htmlBody = appendSignatureHTML(htmlBody, p.SignatureHTML, p.SignatureText)
+	}
+	if !p.OmitCodeFromBody {
+		textBody = appendManagementCode(textBody, code)
+		if htmlBody != "" {
+			htmlBody = appendManagementCodeHTML(htmlBody, code)
+		}
+	}
+
+	replyTo := p.ReplyTo
+	if replyTo == "" && p.ReplyDomain != "" {
+		replyTo = fmt.Sprintf("reply+%s@%s", code, p.ReplyDomain)
+	}
+
+	from := p.From
+	if p.FromName != "" {
+		from = (&mail.Address{Name: p.FromName, Address: p.From}).String()
+	}
+
+	outgoing := &entity.OutgoingMail{
+		From:           from,
+		To:             to,
+		Cc:             cc,
+		ReplyTo:        replyTo,
+		EnvelopeTo:     message.RecipientEmail,
+		EnvelopeFrom:   p.ReturnPath,
+		MessageID:      message.MessageID,
+		InReplyTo:      p.InReplyTo,
+		References:     p.References,
+		Subject:        subject,
+		TextBody:       textBody,
+		HTMLBody:       htmlBody,
+		ManagementCode: code,
+	}
+	outgoing.Attachments = p.attachments()
+	return outgoing
+}
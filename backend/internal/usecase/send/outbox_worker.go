@@ -0,0 +1,361 @@
+package send
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rikut0904/mailer-backend/internal/domain/entity"
+	"github.com/rikut0904/mailer-backend/internal/domain/repository"
+	webhookuc "github.com/rikut0904/mailer-backend/internal/usecase/webhook"
+)
+
+const (
+	outboxReleaseLimit = 20
+	outboxClaimLimit   = 50
+	outboxMaxAttempts  = 8
+	outboxBaseBackoff  = 30 * time.Second
+	outboxMaxBackoff   = 30 * time.Minute
+	// Messages left in sending this long (e.g. after a crash) are queued again.
+	outboxStaleAfter = 10 * time.Minute
+	// outboxMaxRecordBackoff caps the wait between attempts to record a sent
+	// message; it is retried until it succeeds so the mail is not sent twice.
+	outboxMaxRecordBackoff = time.Minute
+)
+
+// OutboxWorker releases scheduled batches whose send time has come and
+// delivers their messages. Temporary failures are retried with exponential
+// backoff up to outboxMaxAttempts; other failures are final.
+type OutboxWorker struct {
+	outboxRepo      repository.OutboxRepository
+	threadGroupRepo repository.ThreadGroupRepository
+	suppressionRepo repository.SuppressionRepository
+	senderRepo      repository.MailSenderRepository
+	settingsRepo    repository.SystemSettingRepository
+	webhookUC       *webhookuc.WebhookUseCase
+	interval        time.Duration
+	// sendGap spaces deliveries to stay under the provider's sending rate;
+	// nextSend is the earliest time of the next one, guarded by sendMu.
+	sendGap  time.Duration
+	sendMu   sync.Mutex
+	nextSend time.Time
+	mu       sync.Mutex
+}
+
+func NewOutboxWorker(
+	outboxRepo repository.OutboxRepository,
+	threadGroupRepo repository.ThreadGroupRepository,
+	suppressionRepo repository.SuppressionRepository,
+	senderRepo repository.MailSenderRepository,
+	settingsRepo repository.SystemSettingRepository,
+	webhookUC *webhookuc.WebhookUseCase,
+	interval time.Duration,
+	sendRate int,
+) *OutboxWorker {
+	var sendGap time.Duration
+	if sendRate > 0 {
+		sendGap = time.Second / time.Duration(sendRate)
+	}
+	return &OutboxWorker{
+		outboxRepo:      outboxRepo,
+		threadGroupRepo: threadGroupRepo,
+		suppressionRepo: suppressionRepo,
+		senderRepo:      senderRepo,
+		settingsRepo:    settingsRepo,
+		webhookUC:       webhookUC,
+		interval:        interval,
+		sendGap:         sendGap,
+	}
+}
+
+func (w *OutboxWorker) Run(ctx context.Context) {
+	if w.interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.RunOnce()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *OutboxWorker) RunOnce() {
+	if !w.mu.TryLock() {
+		return
+	}
+	defer w.mu.Unlock()
+
+	now := time.Now()
+	w.releaseDue(now)
+
+	if n, err := w.outboxRepo.RequeueStale(now.Add(-outboxStaleAfter)); err != nil {
+		log.Printf("outbox: failed to requeue stale messages: %v", err)
+	} else if n > 0 {
+		log.Printf("outbox: requeued %d stale messages", n)
+	}
+
+	messages, err := w.outboxRepo.ClaimDue(now, outboxClaimLimit)
+	if err != nil {
+		log.Printf("outbox: failed to claim messages: %v", err)
+	}
+	if len(messages) == 0 {
+		return
+	}
+
+	// SES replaces our Message-ID with one derived from its own ID and region.
+	var sesRegion string
+	if settings, err := w.settingsRepo.Get(); err == nil {
+		sesRegion = settings.SESRegion
+	}
+
+	batches := map[string]*entity.OutboxBatch{}
+	payloads := map[string]*outboxPayload{}
+	for i := range messages {
+		message := &messages[i]
+
+		batch, ok := batches[message.BatchID]
+		if !ok {
+			batch, err = w.outboxRepo.GetBatch(message.BatchID)
+			if err != nil {
+				w.finish(message, fmt.Errorf("failed to load batch: %w", err), nil)
+				continue
+			}
+			payload, err := decodeOutboxPayload(batch.Payload)
+			if err != nil {
+				w.finish(message, fmt.Errorf("failed to decode payload: %w", err), nil)
+				continue
+			}
+			batches[batch.ID] = batch
+			payloads[batch.ID] = payload
+		}
+
+		w.deliver(message, batch, payloads[batch.ID], sesRegion)
+	}
+}
+
+func (w *OutboxWorker) releaseDue(now time.Time) {
+	batches, err := w.outboxRepo.ListDueBatches(now, outboxReleaseLimit)
+	if err != nil {
+		log.Printf("outbox: failed to list due batches: %v", err)
+		return
+	}
+
+	for i := range batches {
+		if err := w.release(&batches[i], now); err != nil {
+			log.Printf("outbox: failed to release batch %s: %v", batches[i].ID, err)
+		}
+	}
+}
+
+func (w *OutboxWorker) release(batch *entity.OutboxBatch, now time.Time) error {
+	payload, err := decodeOutboxPayload(batch.Payload)
+	if err != nil {
+		return fmt.Errorf("failed to decode payload: %w", err)
+	}
+
+	var messages []entity.OutboxMessage
+	if batch.Kind == entity.OutboxBatchKindMerge {
+		rows, err := w.outboxRepo.ListMergeRecipients(batch.ID)
+		if err != nil {
+			return fmt.Errorf("failed to list merge rows: %w", err)
+		}
+		messages = payload.rowMessages(batch, rows, now)
+	} else {
+		messages = payload.messages(batch, now)
+	}
+	if err := w.skipSuppressed(messages); err != nil {
+		return err
+	}
+
+	released, err := w.outboxRepo.ReleaseBatch(batch.ID, messages)
+	if err != nil || !released {
+		return err
+	}
+
+	if batch.SendType == "new" {
+		if _, err := w.threadGroupRepo.FindByParentUUID(batch.ThreadID); err != nil {
+			if err := w.threadGroupRepo.Create(&entity.ThreadGroup{
+				ParentUUID: batch.ThreadID,
+				GroupName:  batch.Subject,
+			}); err != nil {
+				return fmt.Errorf("failed to create thread group: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// skipSuppressed fails the messages to addresses that were suppressed while
+// the batch was scheduled; they are stored but never claimed.
+func (w *OutboxWorker) skipSuppressed(messages []entity.OutboxMessage) error {
+	emails := make([]string, 0, len(messages))
+	for _, message := range messages {
+		emails = append(emails, message.RecipientEmail)
+	}
+	suppressed, err := w.suppressionRepo.FindByEmails(emails)
+	if err != nil {
+		return fmt.Errorf("failed to check suppression list: %w", err)
+	}
+	if len(suppressed) == 0 {
+		return nil
+	}
+
+	reasons := make(map[string]string, len(suppressed))
+	for _, address := range suppressed {
+		reasons[address.Email] = address.Reason
+	}
+	for i := range messages {
+		if reason, ok := reasons[strings.ToLower(messages[i].RecipientEmail)]; ok {
+			messages[i].Status = entity.OutboxStatusFailed
+			messages[i].LastError = fmt.Sprintf("recipient is suppressed (%s)", reason)
+		}
+	}
+	return nil
+}
+
+func (w *OutboxWorker) deliver(message *entity.OutboxMessage, batch *entity.OutboxBatch, payload *outboxPayload, sesRegion string) {
+	var row *entity.OutboxMergeRow
+	if batch.Kind == entity.OutboxBatchKindMerge {
+		var err error
+		if row, err = w.outboxRepo.GetMergeRow(batch.ID, message.RowNumber); err != nil {
+			w.finish(message, fmt.Errorf("failed to load merge row: %w", err), nil)
+			return
+		}
+	}
+	outgoing := payload.outgoingFor(message, row)
+	w.throttle()
+
+	// A message that waited too long in this pass may have been requeued and
+	// claimed by another worker; only the current claim sends it.
+	if renewed, err := w.outboxRepo.RenewClaim(message, time.Now()); err != nil || !renewed {
+		if err != nil {
+			log.Printf("outbox: failed to renew claim on message %s: %v", message.ID, err)
+		}
+		return
+	}
+
+	providerMessageID, err := w.senderRepo.SendRawEmail(outgoing)
+	if err != nil {
+		w.finish(message, err, nil)
+		return
+	}
+
+	// SES replaces our Message-ID; replies and feedback refer to its own.
+	if providerMessageID != "" {
+		message.SESMessageID = entity.SESMessageID(providerMessageID, sesRegion)
+	}
+
+	sent := &entity.SentMail{
+		ManagementCode: message.ManagementCode,
+		ParentThreadID: batch.ThreadID,
+		RecipientEmail: message.RecipientEmail,
+		MessageID:      message.MessageID,
+		SESMessageID:   message.SESMessageID,
+		RecipientType:  message.RecipientType,
+		ToAddresses:    strings.Join(outgoing.To, ", "),
+		CcAddresses:    strings.Join(outgoing.Cc, ", "),
+		ReplyTo:        outgoing.ReplyTo,
+		Subject:        outgoing.Subject,
+		Body:           outgoing.TextBody,
+
+		ProviderMessageID: providerMessageID,
+		DeliveryStatus:    entity.DeliveryStatusSent,
+	}
+	// Replies get new codes; the code they answer is kept as the parent.
+	if batch.SendType == "reply" {
+		sent.ParentManagementCode = payload.ReplyCode
+	}
+	if !w.finish(message, nil, sent) {
+		return
+	}
+	w.webhookUC.Publish(entity.WebhookEventMailSent, webhookuc.NewSentMailData(sent))
+}
+
+// throttle reserves the next free slot sendGap after the previous delivery
+// and waits for it, so concurrent callers are spaced out too.
+func (w *OutboxWorker) throttle() {
+	if w.sendGap <= 0 {
+		return
+	}
+	w.sendMu.Lock()
+	now := time.Now()
+	slot := w.nextSend
+	if slot.Before(now) {
+		slot = now
+	}
+	w.nextSend = slot.Add(w.sendGap)
+	w.sendMu.Unlock()
+
+	if wait := time.Until(slot); wait > 0 {
+		time.Sleep(wait)
+	}
+}
+
+// finish records the outcome of one delivery attempt, with the sent mail
+// record of a successful one, and reports whether it was recorded.
+func (w *OutboxWorker) finish(message *entity.OutboxMessage, sendErr error, sent *entity.SentMail) bool {
+	now := time.Now()
+	message.Attempts++
+
+	switch {
+	case sendErr == nil:
+		message.Status = entity.OutboxStatusSent
+		message.SentAt = &now
+		message.LastError = ""
+	case errors.Is(sendErr, repository.ErrTemporarySendFailure) && message.Attempts < outboxMaxAttempts:
+		message.Status = entity.OutboxStatusQueued
+		message.NextAttemptAt = now.Add(outboxBackoff(message.Attempts))
+		message.LastError = sendErr.Error()
+	default:
+		message.Status = entity.OutboxStatusFailed
+		message.LastError = sendErr.Error()
+	}
+
+	// A failed attempt that is not recorded is simply retried once the
+	// message goes stale; a sent one must be recorded or it would be resent.
+	for attempt := 1; ; attempt++ {
+		finished, err := w.outboxRepo.FinishMessage(message, sent)
+		if err == nil {
+			if !finished {
+				log.Printf("outbox: message %s was claimed again before its result was recorded", message.ID)
+			}
+			return finished
+		}
+		if sent == nil {
+			log.Printf("outbox: failed to update message %s: %v", message.ID, err)
+			return false
+		}
+		log.Printf("outbox: message %s was sent but recording it failed (attempt %d): %v", message.ID, attempt, err)
+		time.Sleep(outboxRecordBackoff(attempt))
+	}
+}
+
+func outboxRecordBackoff(attempt int) time.Duration {
+	if attempt > 6 {
+		return outboxMaxRecordBackoff
+	}
+	return min(time.Second<<attempt, outboxMaxRecordBackoff)
+}
+
+func outboxBackoff(attempts int) time.Duration {
+	backoff := outboxBaseBackoff
+	for i := 1; i < attempts && backoff < outboxMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > outboxMaxBackoff {
+		return outboxMaxBackoff
+	}
+	return backoff
+}
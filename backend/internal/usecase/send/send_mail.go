@@ -1,38 +1,70 @@
 package send
 
 import (
+	"encoding/base64"
+	"errors"
 	"fmt"
+	htmlpkg "html"
+	"net/mail"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/rikut0904/mailer-backend/internal/domain/entity"
 	"github.com/rikut0904/mailer-backend/internal/domain/repository"
 	"github.com/rikut0904/mailer-backend/internal/infrastructure/discord"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrSendNotFound   = errors.New("send request not found")
+	ErrSendNotPending = errors.New("send request is no longer pending")
+	// ErrSendNotEditable is returned when editing the content of a merge batch;
+	// it can only be rescheduled or canceled.
+	ErrSendNotEditable = errors.New("mail merge jobs cannot be edited")
+	// ErrRecipientSuppressed is returned when a recipient hard-bounced or
+	// complained before; see entity.SuppressedAddress.
+	ErrRecipientSuppressed = errors.New("recipient is on the suppression list")
+	// ErrSenderNotAllowed is returned for from addresses that are not a sender
+	// identity, or one the user may not send from.
+	ErrSenderNotAllowed = errors.New("from_address is not a sender identity you can use")
 )
 
 type SendMailUseCase struct {
 	sentMailRepo    repository.SentMailRepository
-	threadGroupRepo repository.ThreadGroupRepository
-	senderRepo      repository.MailSenderRepository
+	outboxRepo      repository.OutboxRepository
+	identityRepo    repository.SenderIdentityRepository
+	suppressionRepo repository.SuppressionRepository
+	verification    *SenderVerificationUseCase
 	discordClient   *discord.Client
+	undoWindow      time.Duration
 }
 
 func NewSendMailUseCase(
 	sentMailRepo repository.SentMailRepository,
-	threadGroupRepo repository.ThreadGroupRepository,
-	senderRepo repository.MailSenderRepository,
+	outboxRepo repository.OutboxRepository,
+	identityRepo repository.SenderIdentityRepository,
+	suppressionRepo repository.SuppressionRepository,
+	verification *SenderVerificationUseCase,
 	discordClient *discord.Client,
+	undoWindow time.Duration,
 ) *SendMailUseCase {
 	return &SendMailUseCase{
 		sentMailRepo:    sentMailRepo,
-		threadGroupRepo: threadGroupRepo,
-		senderRepo:      senderRepo,
+		outboxRepo:      outboxRepo,
+		identityRepo:    identityRepo,
+		suppressionRepo: suppressionRepo,
+		verification:    verification,
 		discordClient:   discordClient,
+		undoWindow:      undoWindow,
 	}
 }
 
 type SendRequest struct {
 	To          []string `json:"to"`
+	Cc          []string `json:"cc,omitempty"`
+	Bcc         []string `json:"bcc,omitempty"`
+	ReplyTo     string   `json:"reply_to,omitempty"`
 	Subject     string   `json:"subject"`
 	Body        string   `json:"body"`
 	HTMLBody    string   `json:"html_body,omitempty"`
@@ -40,157 +72,472 @@ type SendRequest struct {
 	ReplyCode   string   `json:"reply_code,omitempty"`
 	SendType    string   `json:"send_type"` // "new", "reply", "forward"
 	FromAddress string   `json:"from_address,omitempty"`
+	// OmitSignature sends without the sender identity's signature
+	OmitSignature bool   `json:"omit_signature,omitempty"`
+	UID           string `json:"-"`
+	// System marks mail the application sends on its own, such as the unread
+	// digest; the sender identity need not be one UID may use.
+	System bool `json:"-"`
+	// SendAt schedules the mail; without it the mail is held for the undo window
+	SendAt *time.Time `json:"send_at,omitempty"`
+	// Message-IDs of the mail being answered, written as In-Reply-To/References
+	InReplyTo  string   `json:"in_reply_to,omitempty"`
+	References []string `json:"references,omitempty"`
+	// Uploaded files and attachments copied from stored mails (see AttachmentRefs)
+	Attachments    []entity.Attachment    `json:"-"`
+	AttachmentRefs []entity.AttachmentRef `json:"attachment_refs,omitempty"`
+	// TemplateID renders a stored template with Variables into the subject and
+	// bodies the request leaves empty
+	TemplateID string         `json:"template_id,omitempty"`
+	Variables  map[string]any `json:"variables,omitempty"`
 }
 
 type SendResponse struct {
-	ThreadID        string   `json:"thread_id"`
+	BatchID  string    `json:"batch_id"`
+	ThreadID string    `json:"thread_id"`
+	Status   string    `json:"status"`
+	SendAt   time.Time `json:"send_at"`
+	// ManagementCodes are issued at queue time, one per recipient
 	ManagementCodes []string `json:"management_codes"`
 }
 
-func (uc *SendMailUseCase) Execute(req *SendRequest) (*SendResponse, error) {
-	var threadID string
-	var managementCodes []string
+// SendStatus reports the delivery state of every recipient of a batch.
+// Recipients is empty until the batch is released at SendAt.
+type SendStatus struct {
+	entity.OutboxBatch
+	Recipients []entity.OutboxMessage `json:"recipients"`
+}
 
-	from := req.FromAddress
-	if strings.TrimSpace(from) == "" {
-		return nil, fmt.Errorf("from_address is required")
+// ScheduledSend is a pending batch together with its editable content.
+type ScheduledSend struct {
+	entity.OutboxBatch
+	To       []string `json:"to"`
+	Cc       []string `json:"cc,omitempty"`
+	Bcc      []string `json:"bcc,omitempty"`
+	From     string   `json:"from_address"`
+	Body     string   `json:"body"`
+	HTMLBody string   `json:"html_body,omitempty"`
+	// Reply headers, sent back on update so they can be recomputed
+	InReplyTo  string   `json:"in_reply_to,omitempty"`
+	References []string `json:"references,omitempty"`
+	ReplyCode  string   `json:"reply_code,omitempty"`
+}
+
+// MessagePreview is the message as a recipient would receive it, including
+// the management code footer. The code is a sample; each recipient gets its own.
+type MessagePreview struct {
+	From           string   `json:"from"`
+	To             []string `json:"to"`
+	Cc             []string `json:"cc,omitempty"`
+	ReplyTo        string   `json:"reply_to,omitempty"`
+	Subject        string   `json:"subject"`
+	Body           string   `json:"body"`
+	HTMLBody       string   `json:"html_body,omitempty"`
+	ManagementCode string   `json:"management_code"`
+	Attachments    []string `json:"attachments,omitempty"`
+}
+
+type recipient struct {
+	Address string
+	Type    string
+}
+
+// Execute stores the request as a scheduled outbox batch. It is held until
+// SendAt (or for the undo window) and can be edited or canceled meanwhile;
+// OutboxWorker then queues one copy per To, Cc and Bcc recipient, each with its
+// own management code. Every copy shows the same To/Cc headers; Bcc addresses
+// are only ever used as the envelope recipient of their own copy.
+func (uc *SendMailUseCase) Execute(req *SendRequest) (*SendResponse, error) {
+	identity, err := uc.prepare(req)
+	if err != nil {
+		return nil, err
 	}
 
+	var threadID string
 	switch req.SendType {
 	case "new":
+		// The thread group is created when the batch is released.
 		threadID = uuid.New().String()
-		if err := uc.threadGroupRepo.Create(&entity.ThreadGroup{
-			ParentUUID: threadID,
-			GroupName:  req.Subject,
-		}); err != nil {
-			return nil, fmt.Errorf("failed to create thread group: %w", err)
+	case "reply", "forward":
+		if req.ThreadID == "" {
+			return nil, fmt.Errorf("thread_id is required for %s", req.SendType)
 		}
+		threadID = req.ThreadID
+	default:
+		return nil, fmt.Errorf("unsupported send type: %s", req.SendType)
+	}
 
-		for _, to := range req.To {
-			code := uuid.New().String()
-			managementCodes = append(managementCodes, code)
+	if req.SendType == "reply" {
+		uc.fillReplyHeaders(req, threadID)
+	}
 
-			bodyWithCode := appendManagementCode(req.Body, code)
-			htmlBodyWithCode := ""
-			if req.HTMLBody != "" {
-				htmlBodyWithCode = appendManagementCodeHTML(req.HTMLBody, code)
-			}
+	queued := newOutboxPayload(req, identity)
+	codes := queued.issueCodes(nil)
+	payload, err := encodeOutboxPayload(queued)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode outbox payload: %w", err)
+	}
 
-			if err := uc.senderRepo.SendRawEmail(from, to, req.Subject, bodyWithCode, htmlBodyWithCode); err != nil {
-				return nil, fmt.Errorf("failed to send email to %s: %w", to, err)
-			}
+	batch := &entity.OutboxBatch{
+		ID:       uuid.New().String(),
+		UID:      req.UID,
+		ThreadID: threadID,
+		Kind:     entity.OutboxBatchKindSend,
+		SendType: req.SendType,
+		Subject:  req.Subject,
+		Status:   entity.OutboxBatchStatusScheduled,
+		SendAt:   uc.sendAt(req.SendAt),
+		Payload:  payload,
+	}
+	if err := uc.outboxRepo.CreateBatch(batch); err != nil {
+		return nil, fmt.Errorf("failed to queue mail: %w", err)
+	}
 
-			if err := uc.sentMailRepo.Create(&entity.SentMail{
-				ManagementCode: code,
-				ParentThreadID: threadID,
-				RecipientEmail: to,
-				Subject:        req.Subject,
-				Body:           bodyWithCode,
-			}); err != nil {
-				return nil, fmt.Errorf("failed to save sent mail record: %w", err)
-			}
-		}
+	return &SendResponse{
+		BatchID:  batch.ID,
+		ThreadID: threadID,
+		Status:   batch.Status,
+		SendAt:   batch.SendAt,
 
-	case "reply":
-		if req.ThreadID == "" {
-			return nil, fmt.Errorf("thread_id is required for reply")
-		}
-		threadID = req.ThreadID
+		ManagementCodes: codes,
+	}, nil
+}
 
-		var code string
-		if req.ReplyCode != "" {
-			code = req.ReplyCode
-		} else {
-			code = uuid.New().String()
+// Preview renders the request the way OutboxWorker would for its first
+// recipient, without queueing anything.
+func (uc *SendMailUseCase) Preview(req *SendRequest) (*MessagePreview, error) {
+	if strings.TrimSpace(req.FromAddress) == "" {
+		return nil, fmt.Errorf("from_address is required")
+	}
+	identity, err := uc.findIdentity(req.FromAddress, req.UID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	message := &entity.OutboxMessage{
+		ManagementCode: uuid.New().String(),
+		MessageID:      newMessageID(req.FromAddress),
+	}
+	if recipients := collectRecipients(req.To, req.Cc, req.Bcc); len(recipients) > 0 {
+		message.RecipientEmail = recipients[0].Address
+	}
+	outgoing := newOutboxPayload(req, identity).outgoingFor(message, nil)
+
+	preview := &MessagePreview{
+		From:           outgoing.From,
+		To:             outgoing.To,
+		Cc:             outgoing.Cc,
+		ReplyTo:        outgoing.ReplyTo,
+		Subject:        outgoing.Subject,
+		Body:           outgoing.TextBody,
+		HTMLBody:       outgoing.HTMLBody,
+		ManagementCode: outgoing.ManagementCode,
+	}
+	for _, att := range outgoing.Attachments {
+		preview.Attachments = append(preview.Attachments, att.Filename)
+	}
+	return preview, nil
+}
+
+func (uc *SendMailUseCase) Status(batchID string) (*SendStatus, error) {
+	batch, err := uc.getBatch(batchID)
+	if err != nil {
+		return nil, err
+	}
+
+	messages, err := uc.outboxRepo.ListMessages(batchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list outbox messages: %w", err)
+	}
+
+	return &SendStatus{
+		OutboxBatch: *batch,
+		Recipients:  messages,
+	}, nil
+}
+
+func (uc *SendMailUseCase) ListScheduled(uid string) ([]ScheduledSend, error) {
+	batches, err := uc.outboxRepo.ListBatchesByUID(uid, entity.OutboxBatchStatusScheduled)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scheduled mails: %w", err)
+	}
+
+	scheduled := make([]ScheduledSend, 0, len(batches))
+	for _, batch := range batches {
+		payload, err := decodeOutboxPayload(batch.Payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode outbox payload: %w", err)
 		}
-		managementCodes = append(managementCodes, code)
+		scheduled = append(scheduled, ScheduledSend{
+			OutboxBatch: batch,
+			To:          payload.To,
+			Cc:          payload.Cc,
+			Bcc:         payload.Bcc,
+			From:        payload.From,
+			Body:        payload.TextBody,
+			HTMLBody:    payload.HTMLBody,
+			InReplyTo:   payload.InReplyTo,
+			References:  payload.References,
+			ReplyCode:   payload.ReplyCode,
+		})
+	}
+	return scheduled, nil
+}
 
-		for _, to := range req.To {
-			bodyWithCode := appendManagementCode(req.Body, code)
-			htmlBodyWithCode := ""
-			if req.HTMLBody != "" {
-				htmlBodyWithCode = appendManagementCodeHTML(req.HTMLBody, code)
-			}
+// Update replaces the content, recipients and schedule of a pending batch.
+// Attachments are kept unless the request carries new ones, and recipients
+// that stay keep their management codes. Reply headers are recomputed like in
+// Execute, since the thread may have moved on while the batch was pending.
+func (uc *SendMailUseCase) Update(batchID string, req *SendRequest) (*SendStatus, error) {
+	batch, err := uc.getBatch(batchID)
+	if err != nil {
+		return nil, err
+	}
+	if batch.Kind == entity.OutboxBatchKindMerge {
+		return nil, ErrSendNotEditable
+	}
+	current, err := decodeOutboxPayload(batch.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode outbox payload: %w", err)
+	}
 
-			if err := uc.senderRepo.SendRawEmail(from, to, req.Subject, bodyWithCode, htmlBodyWithCode); err != nil {
-				return nil, fmt.Errorf("failed to send reply to %s: %w", to, err)
-			}
+	if len(req.Attachments) == 0 {
+		req.Attachments = current.attachments()
+	}
+	identity, err := uc.prepare(req)
+	if err != nil {
+		return nil, err
+	}
 
-			if err := uc.sentMailRepo.Create(&entity.SentMail{
-				ManagementCode: code,
-				ParentThreadID: threadID,
-				RecipientEmail: to,
-				Subject:        req.Subject,
-				Body:           bodyWithCode,
-			}); err != nil {
-				return nil, fmt.Errorf("failed to save sent mail record: %w", err)
-			}
+	if batch.SendType == "reply" {
+		uc.fillReplyHeaders(req, batch.ThreadID)
+	} else {
+		req.InReplyTo, req.References, req.ReplyCode = "", nil, ""
+	}
+
+	updated := newOutboxPayload(req, identity)
+	updated.issueCodes(current.Codes)
+
+	payload, err := encodeOutboxPayload(updated)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode outbox payload: %w", err)
+	}
+
+	batch.Subject = req.Subject
+	batch.Payload = payload
+	batch.SendAt = uc.sendAt(req.SendAt)
+	return uc.saveScheduled(batch)
+}
+
+func (uc *SendMailUseCase) Reschedule(batchID string, sendAt *time.Time) (*SendStatus, error) {
+	batch, err := uc.getBatch(batchID)
+	if err != nil {
+		return nil, err
+	}
+
+	batch.SendAt = uc.sendAt(sendAt)
+	return uc.saveScheduled(batch)
+}
+
+func (uc *SendMailUseCase) Cancel(batchID string) error {
+	canceled, err := uc.outboxRepo.CancelBatch(batchID)
+	if err != nil {
+		return fmt.Errorf("failed to cancel mail: %w", err)
+	}
+	if !canceled {
+		return ErrSendNotPending
+	}
+	return nil
+}
+
+func (uc *SendMailUseCase) saveScheduled(batch *entity.OutboxBatch) (*SendStatus, error) {
+	saved, err := uc.outboxRepo.UpdateScheduledBatch(batch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update scheduled mail: %w", err)
+	}
+	if !saved {
+		return nil, ErrSendNotPending
+	}
+	return uc.Status(batch.ID)
+}
+
+func (uc *SendMailUseCase) getBatch(batchID string) (*entity.OutboxBatch, error) {
+	batch, err := uc.outboxRepo.GetBatch(batchID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSendNotFound
 		}
+		return nil, fmt.Errorf("failed to load send request: %w", err)
+	}
+	return batch, nil
+}
 
-	case "forward":
-		if req.ThreadID == "" {
-			return nil, fmt.Errorf("thread_id is required for forward")
+// prepare validates the request and resolves the sender identity.
+func (uc *SendMailUseCase) prepare(req *SendRequest) (*entity.SenderIdentity, error) {
+	from := req.FromAddress
+	if strings.TrimSpace(from) == "" {
+		return nil, fmt.Errorf("from_address is required")
+	}
+	if err := validateMessageSize(req); err != nil {
+		return nil, err
+	}
+	recipients := collectRecipients(req.To, req.Cc, req.Bcc)
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("at least one recipient is required")
+	}
+	if err := uc.checkSuppressed(recipients); err != nil {
+		return nil, err
+	}
+
+	return uc.findIdentity(from, req.UID, req.System)
+}
+
+// findIdentity loads the sender identity of from and checks that uid may use it
+// and that SES has verified it.
+func (uc *SendMailUseCase) findIdentity(from, uid string, system bool) (*entity.SenderIdentity, error) {
+	address := strings.TrimSpace(from)
+	if addr, err := mail.ParseAddress(address); err == nil {
+		address = addr.Address
+	}
+
+	identity, err := uc.identityRepo.FindByAddress(address)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrSenderNotAllowed, address)
 		}
-		threadID = req.ThreadID
+		return nil, fmt.Errorf("failed to load sender identity: %w", err)
+	}
+	if !system && !identity.AllowsUser(uid) {
+		return nil, fmt.Errorf("%w: %s", ErrSenderNotAllowed, address)
+	}
+	if err := uc.verification.CheckSender(identity.Address); err != nil {
+		return nil, err
+	}
+	return identity, nil
+}
+
+func (uc *SendMailUseCase) checkSuppressed(recipients []recipient) error {
+	emails := make([]string, 0, len(recipients))
+	for _, rcpt := range recipients {
+		emails = append(emails, rcpt.Address)
+	}
 
-		if len(req.To) == 1 {
-			code := uuid.New().String()
-			managementCodes = append(managementCodes, code)
+	suppressed, err := uc.suppressionRepo.FindByEmails(emails)
+	if err != nil {
+		return fmt.Errorf("failed to check suppression list: %w", err)
+	}
+	if len(suppressed) == 0 {
+		return nil
+	}
 
-			bodyWithCode := appendManagementCode(req.Body, code)
-			htmlBodyWithCode := ""
-			if req.HTMLBody != "" {
-				htmlBodyWithCode = appendManagementCodeHTML(req.HTMLBody, code)
-			}
+	addresses := make([]string, 0, len(suppressed))
+	for _, address := range suppressed {
+		addresses = append(addresses, address.Email)
+	}
+	return fmt.Errorf("%w: %s", ErrRecipientSuppressed, strings.Join(addresses, ", "))
+}
 
-			if err := uc.senderRepo.SendRawEmail(from, req.To[0], req.Subject, bodyWithCode, htmlBodyWithCode); err != nil {
-				return nil, fmt.Errorf("failed to forward email: %w", err)
-			}
+// sendAt never returns a time earlier than the end of the undo window.
+func (uc *SendMailUseCase) sendAt(requested *time.Time) time.Time {
+	earliest := time.Now().Add(uc.undoWindow)
+	if requested == nil || requested.Before(earliest) {
+		return earliest
+	}
+	return *requested
+}
 
-			if err := uc.sentMailRepo.Create(&entity.SentMail{
-				ManagementCode: code,
-				ParentThreadID: threadID,
-				RecipientEmail: req.To[0],
-				Subject:        req.Subject,
-				Body:           bodyWithCode,
-			}); err != nil {
-				return nil, fmt.Errorf("failed to save sent mail record: %w", err)
+func collectRecipients(to, cc, bcc []string) []recipient {
+	seen := map[string]struct{}{}
+	var recipients []recipient
+	add := func(addresses []string, recipientType string) {
+		for _, addr := range addresses {
+			addr = strings.TrimSpace(addr)
+			key := strings.ToLower(addr)
+			if addr == "" {
+				continue
 			}
-		} else {
-			for _, to := range req.To {
-				childCode := uuid.New().String()
-				managementCodes = append(managementCodes, childCode)
-
-				bodyWithCode := appendManagementCode(req.Body, childCode)
-				htmlBodyWithCode := ""
-				if req.HTMLBody != "" {
-					htmlBodyWithCode = appendManagementCodeHTML(req.HTMLBody, childCode)
-				}
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			recipients = append(recipients, recipient{Address: addr, Type: recipientType})
+		}
+	}
 
-				if err := uc.senderRepo.SendRawEmail(from, to, req.Subject, bodyWithCode, htmlBodyWithCode); err != nil {
-					return nil, fmt.Errorf("failed to forward email to %s: %w", to, err)
-				}
+	add(to, entity.RecipientTypeTo)
+	add(cc, entity.RecipientTypeCc)
+	add(bcc, entity.RecipientTypeBcc)
+	return recipients
+}
 
-				if err := uc.sentMailRepo.Create(&entity.SentMail{
-					ManagementCode: childCode,
-					ParentThreadID: threadID,
-					RecipientEmail: to,
-					Subject:        req.Subject,
-					Body:           bodyWithCode,
-				}); err != nil {
-					return nil, fmt.Errorf("failed to save sent mail record: %w", err)
+// fillReplyHeaders answers the latest mail sent in the thread when the client
+// did not say which message it is replying to, taking its code as ReplyCode
+// unless one was given, and makes sure the parent is the last entry of
+// References.
+func (uc *SendMailUseCase) fillReplyHeaders(req *SendRequest, threadID string) {
+	if req.InReplyTo == "" {
+		sentMails, err := uc.sentMailRepo.FindByParentThreadID(threadID)
+		if err == nil {
+			for i := len(sentMails) - 1; i >= 0; i-- {
+				// Recipients of SES mail saw the Message-ID SES assigned.
+				if sentMails[i].SESMessageID != "" {
+					req.InReplyTo = sentMails[i].SESMessageID
+				} else {
+					req.InReplyTo = sentMails[i].MessageID
+				}
+				if req.InReplyTo != "" {
+					if req.ReplyCode == "" {
+						req.ReplyCode = sentMails[i].ManagementCode
+					}
+					break
 				}
 			}
 		}
+	}
+	if req.InReplyTo != "" && (len(req.References) == 0 || req.References[len(req.References)-1] != req.InReplyTo) {
+		req.References = append(req.References, req.InReplyTo)
+	}
+}
 
-	default:
-		return nil, fmt.Errorf("unsupported send type: %s", req.SendType)
+func newMessageID(from string) string {
+	domain := "localhost"
+	if idx := strings.LastIndex(from, "@"); idx != -1 && idx < len(from)-1 {
+		domain = strings.TrimSuffix(from[idx+1:], ">")
 	}
+	return fmt.Sprintf("<%s@%s>", uuid.New().String(), domain)
+}
 
-	return &SendResponse{
-		ThreadID:        threadID,
-		ManagementCodes: managementCodes,
-	}, nil
+// validateMessageSize rejects requests whose encoded size would exceed the SES
+// limit before anything is sent. Base64 grows content by 4/3 plus line breaks.
+func validateMessageSize(req *SendRequest) error {
+	size := int64(len(req.Subject) + len(req.Body) + len(req.HTMLBody))
+	for _, att := range req.Attachments {
+		encoded := int64(base64.StdEncoding.EncodedLen(len(att.Content)))
+		size += encoded + encoded/76*2 + 512
+	}
+	if size > repository.MaxRawMessageSize {
+		return fmt.Errorf("%w: about %.1f MB after encoding, limit is %d MB",
+			repository.ErrMessageTooLarge, float64(size)/(1024*1024), repository.MaxRawMessageSize/(1024*1024))
+	}
+	return nil
+}
+
+// appendSignature uses the "-- " separator understood by most mail clients.
+func appendSignature(body, signature string) string {
+	return strings.TrimRight(body, "\n") + "\n\n-- \n" + strings.TrimRight(signature, "\n")
+}
+
+// appendSignatureHTML falls back to the escaped text signature when the
+// identity has no HTML one.
+func appendSignatureHTML(html, signatureHTML, signatureText string) string {
+	if signatureHTML == "" {
+		signatureHTML = strings.ReplaceAll(htmlpkg.EscapeString(strings.TrimRight(signatureText, "\n")), "\n", "<br>")
+	}
+	signature := `<br><div class="signature">` + signatureHTML + `</div>`
+	if idx := strings.LastIndex(html, "</body>"); idx != -1 {
+		return html[:idx] + signature + html[idx:]
+	}
+	return html + signature
 }
 
 func appendManagementCode(body, code string) string {
@@ -0,0 +1,172 @@
+package send
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rikut0904/mailer-backend/internal/domain/entity"
+	"github.com/rikut0904/mailer-backend/internal/domain/repository"
+)
+
+var (
+	// ErrSenderNotVerified is returned when SES has not verified the from
+	// address or its domain, so the send would be rejected by SES anyway.
+	ErrSenderNotVerified = errors.New("from_address is not verified in SES")
+	// ErrSenderCheckFailed is returned when SES refused the lookup, e.g. for
+	// missing permissions, so the sender cannot be vouched for.
+	ErrSenderCheckFailed = errors.New("failed to check from_address in SES")
+)
+
+const (
+	// identityCacheTTL bounds how stale a cached verification status can be.
+	identityCacheTTL = 10 * time.Minute
+	// identityNotFoundTTL is shorter so a newly created identity shows up soon.
+	identityNotFoundTTL = 30 * time.Second
+	// identityLookupGap spaces GetEmailIdentity calls to stay under the SES
+	// API rate limit when many identities are refreshed at once.
+	identityLookupGap = 100 * time.Millisecond
+)
+
+type identityCacheEntry struct {
+	// status is nil when SES does not know the identity
+	status  *entity.EmailIdentityStatus
+	expires time.Time
+}
+
+// SenderVerificationUseCase checks from addresses against the SES identity
+// verification status, caching lookups for identityCacheTTL.
+type SenderVerificationUseCase struct {
+	identityRepo repository.EmailIdentityRepository
+	settingsRepo repository.SystemSettingRepository
+
+	mu         sync.Mutex
+	cache      map[string]identityCacheEntry
+	nextLookup time.Time
+}
+
+func NewSenderVerificationUseCase(
+	identityRepo repository.EmailIdentityRepository,
+	settingsRepo repository.SystemSettingRepository,
+) *SenderVerificationUseCase {
+	return &SenderVerificationUseCase{
+		identityRepo: identityRepo,
+		settingsRepo: settingsRepo,
+		cache:        make(map[string]identityCacheEntry),
+	}
+}
+
+// CheckSender returns ErrSenderNotVerified unless address or its domain is
+// verified for sending. It only applies when SES is the configured transport.
+// The check is skipped while SES is not configured or only temporarily
+// unavailable; any other lookup error fails it with ErrSenderCheckFailed.
+func (uc *SenderVerificationUseCase) CheckSender(address string) error {
+	settings, err := uc.settingsRepo.Get()
+	if err != nil {
+		return fmt.Errorf("failed to load system settings: %w", err)
+	}
+	if settings.MailTransport == entity.MailTransportSMTP {
+		return nil
+	}
+
+	address = strings.ToLower(strings.TrimSpace(address))
+	candidates := []string{address}
+	if at := strings.LastIndex(address, "@"); at != -1 {
+		candidates = append(candidates, address[at+1:])
+	}
+
+	for _, identity := range candidates {
+		status, err := uc.lookup(identity)
+		switch {
+		case errors.Is(err, repository.ErrSESNotConfigured):
+			return nil
+		case errors.Is(err, repository.ErrEmailIdentityUnavailable):
+			log.Printf("sender verification: skipped check for %s: %v", address, err)
+			return nil
+		case err != nil:
+			return fmt.Errorf("%w: %s: %v", ErrSenderCheckFailed, address, err)
+		}
+		if status != nil && status.VerifiedForSending {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrSenderNotVerified, address)
+}
+
+// List returns every SES identity with its DKIM and MAIL FROM status.
+// refresh bypasses the cache.
+func (uc *SenderVerificationUseCase) List(refresh bool) ([]entity.EmailIdentityStatus, error) {
+	identities, err := uc.identityRepo.ListEmailIdentities()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]entity.EmailIdentityStatus, 0, len(identities))
+	for _, summary := range identities {
+		if refresh {
+			uc.forget(summary.Identity)
+		}
+		status, err := uc.lookup(summary.Identity)
+		if err != nil {
+			return nil, err
+		}
+		if status == nil {
+			// deleted between the list and the lookup
+			continue
+		}
+		statuses = append(statuses, *status)
+	}
+	return statuses, nil
+}
+
+func (uc *SenderVerificationUseCase) lookup(identity string) (*entity.EmailIdentityStatus, error) {
+	key := strings.ToLower(identity)
+
+	uc.mu.Lock()
+	entry, ok := uc.cache[key]
+	uc.mu.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.status, nil
+	}
+
+	uc.throttle()
+	status, err := uc.identityRepo.GetEmailIdentity(identity)
+	if err != nil && !errors.Is(err, repository.ErrEmailIdentityNotFound) {
+		return nil, err
+	}
+
+	ttl := identityCacheTTL
+	if status == nil {
+		ttl = identityNotFoundTTL
+	}
+	uc.mu.Lock()
+	uc.cache[key] = identityCacheEntry{status: status, expires: time.Now().Add(ttl)}
+	uc.mu.Unlock()
+	return status, nil
+}
+
+// throttle reserves the next free slot identityLookupGap after the previous
+// lookup and waits for it, so concurrent callers are spaced out too.
+func (uc *SenderVerificationUseCase) throttle() {
+	uc.mu.Lock()
+	now := time.Now()
+	slot := uc.nextLookup
+	if slot.Before(now) {
+		slot = now
+	}
+	uc.nextLookup = slot.Add(identityLookupGap)
+	uc.mu.Unlock()
+
+	if wait := time.Until(slot); wait > 0 {
+		time.Sleep(wait)
+	}
+}
+
+func (uc *SenderVerificationUseCase) forget(identity string) {
+	uc.mu.Lock()
+	delete(uc.cache, strings.ToLower(identity))
+	uc.mu.Unlock()
+}
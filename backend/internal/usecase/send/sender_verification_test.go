@@ -0,0 +1,122 @@
+package send
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rikut0904/mailer-backend/internal/domain/entity"
+	"github.com/rikut0904/mailer-backend/internal/domain/repository"
+)
+
+type fakeSettingsRepo struct {
+	setting *entity.SystemSetting
+	err     error
+}
+
+func (r *fakeSettingsRepo) Get() (*entity.SystemSetting, error) { return r.setting, r.err }
+
+func (r *fakeSettingsRepo) Upsert(setting *entity.SystemSetting) error {
+	r.setting = setting
+	return nil
+}
+
+// fakeIdentityRepo answers GetEmailIdentity from statuses, or with err when
+// set, and counts the lookups.
+type fakeIdentityRepo struct {
+	statuses map[string]*entity.EmailIdentityStatus
+	err      error
+	lookups  int
+}
+
+func (r *fakeIdentityRepo) ListEmailIdentities() ([]entity.EmailIdentityStatus, error) {
+	var identities []entity.EmailIdentityStatus
+	for _, status := range r.statuses {
+		identities = append(identities, *status)
+	}
+	return identities, nil
+}
+
+func (r *fakeIdentityRepo) GetEmailIdentity(identity string) (*entity.EmailIdentityStatus, error) {
+	r.lookups++
+	if r.err != nil {
+		return nil, r.err
+	}
+	if status, ok := r.statuses[identity]; ok {
+		return status, nil
+	}
+	return nil, repository.ErrEmailIdentityNotFound
+}
+
+func verified(identity string) *entity.EmailIdentityStatus {
+	return &entity.EmailIdentityStatus{Identity: identity, VerifiedForSending: true}
+}
+
+func TestCheckSender(t *testing.T) {
+	accessDenied := errors.New("AccessDeniedException: not authorized to perform ses:GetEmailIdentity")
+
+	tests := []struct {
+		name      string
+		transport string
+		statuses  map[string]*entity.EmailIdentityStatus
+		lookupErr error
+		want      error
+	}{
+		{name: "verified address", statuses: map[string]*entity.EmailIdentityStatus{"info@example.com": verified("info@example.com")}},
+		{name: "verified domain", statuses: map[string]*entity.EmailIdentityStatus{"example.com": verified("example.com")}},
+		{name: "unverified", statuses: map[string]*entity.EmailIdentityStatus{"example.com": {Identity: "example.com"}}, want: ErrSenderNotVerified},
+		{name: "unknown identity", want: ErrSenderNotVerified},
+		{name: "smtp transport", transport: entity.MailTransportSMTP},
+		{name: "ses not configured", lookupErr: repository.ErrSESNotConfigured},
+		{name: "throttled", lookupErr: repository.ErrEmailIdentityUnavailable},
+		{name: "access denied", lookupErr: accessDenied, want: ErrSenderCheckFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identities := &fakeIdentityRepo{statuses: tt.statuses, err: tt.lookupErr}
+			settings := &fakeSettingsRepo{setting: &entity.SystemSetting{MailTransport: tt.transport}}
+			uc := NewSenderVerificationUseCase(identities, settings)
+
+			err := uc.CheckSender("Info@Example.com")
+			if tt.want == nil && err != nil {
+				t.Fatalf("CheckSender: %v", err)
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Fatalf("CheckSender error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckSenderSettingsError(t *testing.T) {
+	uc := NewSenderVerificationUseCase(&fakeIdentityRepo{}, &fakeSettingsRepo{err: errors.New("connection refused")})
+	if err := uc.CheckSender("info@example.com"); err == nil {
+		t.Fatal("CheckSender succeeded without settings")
+	}
+}
+
+func TestCheckSenderCachesLookups(t *testing.T) {
+	identities := &fakeIdentityRepo{statuses: map[string]*entity.EmailIdentityStatus{"info@example.com": verified("info@example.com")}}
+	uc := NewSenderVerificationUseCase(identities, &fakeSettingsRepo{setting: &entity.SystemSetting{}})
+
+	for range 3 {
+		if err := uc.CheckSender("info@example.com"); err != nil {
+			t.Fatalf("CheckSender: %v", err)
+		}
+	}
+	if identities.lookups != 1 {
+		t.Errorf("lookups = %d, want 1", identities.lookups)
+	}
+}
+
+func TestCheckSenderDoesNotCacheFailures(t *testing.T) {
+	identities := &fakeIdentityRepo{err: repository.ErrEmailIdentityUnavailable}
+	uc := NewSenderVerificationUseCase(identities, &fakeSettingsRepo{setting: &entity.SystemSetting{}})
+
+	if err := uc.CheckSender("info@example.com"); err != nil {
+		t.Fatalf("CheckSender: %v", err)
+	}
+	identities.err = nil
+	if err := uc.CheckSender("info@example.com"); !errors.Is(err, ErrSenderNotVerified) {
+		t.Fatalf("CheckSender error = %v, want %v", err, ErrSenderNotVerified)
+	}
+}
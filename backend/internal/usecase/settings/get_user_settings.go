@@ -21,8 +21,8 @@ func (uc *GetUserSettingsUseCase) Execute(uid string) (*entity.UserSetting, erro
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return &entity.UserSetting{
-				UID:               uid,
-				DiscordWebhookURL: "",
+				UID:                  uid,
+				NotificationChannels: []entity.NotificationChannel{},
 			}, nil
 		}
 		return nil, err
@@ -2,12 +2,22 @@ package settings
 
 import (
 	"fmt"
+	"net/mail"
+	"net/url"
+	"path"
 	"strings"
 
+	"github.com/google/uuid"
 	"github.com/rikut0904/mailer-backend/internal/domain/entity"
 	"github.com/rikut0904/mailer-backend/internal/domain/repository"
 )
 
+// Bounds on what one user can configure.
+const (
+	maxNotificationChannels = 20
+	maxNotificationRules    = 50
+)
+
 type UpdateUserSettingsUseCase struct {
 	repo repository.UserSettingRepository
 }
@@ -16,34 +26,151 @@ func NewUpdateUserSettingsUseCase(repo repository.UserSettingRepository) *Update
 	return &UpdateUserSettingsUseCase{repo: repo}
 }
 
-func (uc *UpdateUserSettingsUseCase) Execute(uid string, discordWebhookURL string, selectedDomainID string) error {
-	if err := validateDiscordWebhookURL(discordWebhookURL); err != nil {
+type UserSettingsInput struct {
+	NotificationChannels []entity.NotificationChannel `json:"notification_channels"`
+	NotificationRules    []entity.NotificationRule    `json:"notification_rules"`
+	QuietHours           entity.QuietHours            `json:"quiet_hours"`
+	UnreadDigest         entity.UnreadDigest          `json:"unread_digest"`
+	SelectedDomainID     string                       `json:"selected_domain_id"`
+}
+
+func (uc *UpdateUserSettingsUseCase) Execute(uid string, input *UserSettingsInput) error {
+	channels, err := normalizeChannels(input.NotificationChannels)
+	if err != nil {
 		return err
 	}
+	rules, err := normalizeRules(input.NotificationRules, channels)
+	if err != nil {
+		return err
+	}
+	if err := input.QuietHours.Validate(); err != nil {
+		return err
+	}
+	input.UnreadDigest.Schedule = strings.TrimSpace(input.UnreadDigest.Schedule)
+	input.UnreadDigest.ToAddress = strings.TrimSpace(input.UnreadDigest.ToAddress)
+	if err := input.UnreadDigest.Validate(); err != nil {
+		return err
+	}
+	// The address is the envelope recipient, so a display name is dropped.
+	if addr, err := mail.ParseAddress(input.UnreadDigest.ToAddress); err == nil {
+		input.UnreadDigest.ToAddress = addr.Address
+	}
 
 	setting := &entity.UserSetting{
-		UID:               uid,
-		DiscordWebhookURL: strings.TrimSpace(discordWebhookURL),
-		SelectedDomainID:  strings.TrimSpace(selectedDomainID),
+		UID:                  uid,
+		NotificationChannels: channels,
+		NotificationRules:    rules,
+		QuietHours:           input.QuietHours,
+		UnreadDigest:         input.UnreadDigest,
+		SelectedDomainID:     strings.TrimSpace(input.SelectedDomainID),
 	}
 	return uc.repo.Upsert(setting)
 }
 
-func validateDiscordWebhookURL(url string) error {
-	if strings.TrimSpace(url) == "" {
-		return nil
+func normalizeChannels(channels []entity.NotificationChannel) ([]entity.NotificationChannel, error) {
+	if len(channels) > maxNotificationChannels {
+		return nil, fmt.Errorf("too many notification channels (max %d)", maxNotificationChannels)
 	}
 
-	validPrefixes := []string{
-		"https://discord.com/api/webhooks/",
-		"https://discordapp.com/api/webhooks/",
+	normalized := make([]entity.NotificationChannel, 0, len(channels))
+	for _, channel := range channels {
+		channel.WebhookURL = strings.TrimSpace(channel.WebhookURL)
+		channel.RecipientAddress = strings.ToLower(strings.TrimSpace(channel.RecipientAddress))
+		if channel.WebhookURL == "" {
+			continue
+		}
+		if err := validateWebhookURL(channel.Type, channel.WebhookURL); err != nil {
+			return nil, err
+		}
+		if channel.RecipientAddress != "" {
+			if _, err := mail.ParseAddress(channel.RecipientAddress); err != nil {
+				return nil, fmt.Errorf("invalid recipient address: %s", channel.RecipientAddress)
+			}
+		}
+		if channel.ID == "" {
+			channel.ID = uuid.New().String()
+		}
+		channel.Name = strings.TrimSpace(channel.Name)
+		normalized = append(normalized, channel)
 	}
+	return normalized, nil
+}
 
-	for _, prefix := range validPrefixes {
-		if strings.HasPrefix(url, prefix) {
-			return nil
+// normalizeRules drops rules whose channel was removed in the same update.
+func normalizeRules(rules []entity.NotificationRule, channels []entity.NotificationChannel) ([]entity.NotificationRule, error) {
+	if len(rules) > maxNotificationRules {
+		return nil, fmt.Errorf("too many notification rules (max %d)", maxNotificationRules)
+	}
+
+	channelIDs := make(map[string]bool, len(channels))
+	for _, channel := range channels {
+		channelIDs[channel.ID] = true
+	}
+
+	normalized := make([]entity.NotificationRule, 0, len(rules))
+	for _, rule := range rules {
+		if !channelIDs[rule.ChannelID] {
+			continue
+		}
+		rule.Name = strings.TrimSpace(rule.Name)
+		rule.RecipientAddress = strings.ToLower(strings.TrimSpace(rule.RecipientAddress))
+		rule.SenderPattern = strings.TrimSpace(rule.SenderPattern)
+		rule.Mention = strings.TrimSpace(rule.Mention)
+
+		keywords := make([]string, 0, len(rule.SubjectKeywords))
+		for _, keyword := range rule.SubjectKeywords {
+			if keyword = strings.TrimSpace(keyword); keyword != "" {
+				keywords = append(keywords, keyword)
+			}
+		}
+		rule.SubjectKeywords = keywords
+
+		if rule.RecipientAddress != "" {
+			if _, err := mail.ParseAddress(rule.RecipientAddress); err != nil {
+				return nil, fmt.Errorf("invalid recipient address: %s", rule.RecipientAddress)
+			}
 		}
+		if _, err := path.Match(rule.SenderPattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid sender pattern: %s", rule.SenderPattern)
+		}
+		if rule.ID == "" {
+			rule.ID = uuid.New().String()
+		}
+		normalized = append(normalized, rule)
 	}
+	return normalized, nil
+}
 
-	return fmt.Errorf("invalid discord webhook url")
+func validateWebhookURL(channelType, webhookURL string) error {
+	switch channelType {
+	case entity.NotificationChannelDiscord:
+		for _, prefix := range []string{
+			"https://discord.com/api/webhooks/",
+			"https://discordapp.com/api/webhooks/",
+		} {
+			if strings.HasPrefix(webhookURL, prefix) {
+				return nil
+			}
+		}
+		return fmt.Errorf("invalid discord webhook url")
+	case entity.NotificationChannelSlack:
+		if strings.HasPrefix(webhookURL, "https://hooks.slack.com/") {
+			return nil
+		}
+		return fmt.Errorf("invalid slack webhook url")
+	case entity.NotificationChannelTeams:
+		// Office 365 connectors and Power Automate workflow URLs
+		parsed, err := url.Parse(webhookURL)
+		if err == nil && parsed.Scheme == "https" {
+			host := strings.ToLower(parsed.Hostname())
+			for _, suffix := range []string{".webhook.office.com", ".logic.azure.com", ".powerplatform.com"} {
+				if strings.HasSuffix(host, suffix) {
+					return nil
+				}
+			}
+		}
+		return fmt.Errorf("invalid teams webhook url")
+	default:
+		return fmt.Errorf("unknown notification channel type: %q", channelType)
+	}
 }
@@ -39,6 +39,9 @@ type ThreadMessage struct {
 	Code      string    `json:"management_code,omitempty"`
 	IsRead    bool      `json:"is_read,omitempty"`
 	IsStarred bool      `json:"is_starred,omitempty"`
+	// Delivery state of sent messages, updated from SES notifications
+	DeliveryStatus string `json:"delivery_status,omitempty"`
+	DeliveryDetail string `json:"delivery_detail,omitempty"`
 }
 
 type ThreadResponse struct {
@@ -95,6 +98,9 @@ func (uc *GetThreadUseCase) Execute(storageRepo repository.MailStorageRepository
 			Body:    sent.Body,
 			Date:    sent.SentAt,
 			Code:    sent.ManagementCode,
+
+			DeliveryStatus: sent.DeliveryStatus,
+			DeliveryDetail: sent.DeliveryDetail,
 		})
 	}
 
@@ -0,0 +1,66 @@
+package webhook
+
+import (
+	"time"
+
+	"github.com/rikut0904/mailer-backend/internal/domain/entity"
+)
+
+// MailData is the data of mail.received, thread.linked and mail.deleted.
+// mail.deleted only carries DomainID and S3Key.
+type MailData struct {
+	DomainID  string     `json:"domain_id"`
+	S3Key     string     `json:"s3_key"`
+	MessageID string     `json:"message_id,omitempty"`
+	From      string     `json:"from,omitempty"`
+	To        string     `json:"to,omitempty"`
+	Subject   string     `json:"subject,omitempty"`
+	Snippet   string     `json:"snippet,omitempty"`
+	Date      *time.Time `json:"date,omitempty"`
+	ThreadID  string     `json:"thread_id,omitempty"`
+}
+
+// SentMailData is the data of mail.sent and mail.bounced.
+type SentMailData struct {
+	ManagementCode    string `json:"management_code"`
+	ThreadID          string `json:"thread_id,omitempty"`
+	MessageID         string `json:"message_id,omitempty"`
+	ProviderMessageID string `json:"provider_message_id,omitempty"`
+	Recipient         string `json:"recipient"`
+	Subject           string `json:"subject,omitempty"`
+	// DeliveryStatus and Detail are set for mail.bounced
+	DeliveryStatus string `json:"delivery_status,omitempty"`
+	Detail         string `json:"detail,omitempty"`
+}
+
+func NewMailData(state *entity.MailState) MailData {
+	data := MailData{
+		DomainID:  state.DomainID,
+		S3Key:     state.S3Key,
+		MessageID: state.MessageID,
+		From:      state.FromAddress,
+		To:        state.ToAddress,
+		Subject:   state.Subject,
+		Snippet:   state.Snippet,
+	}
+	if !state.MailDate.IsZero() {
+		date := state.MailDate
+		data.Date = &date
+	}
+	if state.ThreadID != nil {
+		data.ThreadID = *state.ThreadID
+	}
+	return data
+}
+
+func NewSentMailData(sent *entity.SentMail) SentMailData {
+	return SentMailData{
+		ManagementCode:    sent.ManagementCode,
+		ThreadID:          sent.ParentThreadID,
+		MessageID:         sent.MessageID,
+		ProviderMessageID: sent.ProviderMessageID,
+		Recipient:         sent.RecipientEmail,
+		Subject:           sent.Subject,
+		DeliveryStatus:    sent.DeliveryStatus,
+	}
+}
@@ -0,0 +1,237 @@
+package webhook
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rikut0904/mailer-backend/internal/domain/entity"
+	"github.com/rikut0904/mailer-backend/internal/domain/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrSubscriptionNotFound = errors.New("webhook subscription not found")
+	ErrSubscriptionInvalid  = errors.New("invalid webhook subscription")
+)
+
+const (
+	// maxDeliveryLog bounds how many deliveries are returned per subscription.
+	maxDeliveryLog = 200
+	// publishQueueSize bounds the events waiting for WebhookWorker; events
+	// published while it is full are dropped and logged.
+	publishQueueSize = 1024
+)
+
+// WebhookUseCase manages webhook subscriptions and queues events for them.
+// Publish is safe to call on a nil *WebhookUseCase.
+type WebhookUseCase struct {
+	webhookRepo repository.WebhookRepository
+	published   chan Event
+}
+
+func NewWebhookUseCase(webhookRepo repository.WebhookRepository) *WebhookUseCase {
+	return &WebhookUseCase{
+		webhookRepo: webhookRepo,
+		published:   make(chan Event, publishQueueSize),
+	}
+}
+
+type SubscriptionInput struct {
+	URL         string   `json:"url"`
+	Events      []string `json:"events"`
+	Secret      string   `json:"secret"`
+	Description string   `json:"description"`
+	Enabled     bool     `json:"enabled"`
+}
+
+// Event is the JSON body of every webhook request.
+type Event struct {
+	ID        string    `json:"id"`
+	Event     string    `json:"event"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+}
+
+func (uc *WebhookUseCase) List() ([]entity.WebhookSubscription, error) {
+	subscriptions, err := uc.webhookRepo.ListSubscriptions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook subscriptions: %w", err)
+	}
+	return subscriptions, nil
+}
+
+// Create generates a secret when input has none.
+func (uc *WebhookUseCase) Create(input *SubscriptionInput) (*entity.WebhookSubscription, error) {
+	if err := input.validate(); err != nil {
+		return nil, err
+	}
+
+	secret := strings.TrimSpace(input.Secret)
+	if secret == "" {
+		secret = newSecret()
+	}
+	subscription := &entity.WebhookSubscription{
+		ID:          uuid.New().String(),
+		URL:         strings.TrimSpace(input.URL),
+		Events:      input.Events,
+		Secret:      secret,
+		Description: strings.TrimSpace(input.Description),
+		Enabled:     input.Enabled,
+	}
+	if err := uc.webhookRepo.CreateSubscription(subscription); err != nil {
+		return nil, fmt.Errorf("failed to create webhook subscription: %w", err)
+	}
+	return subscription, nil
+}
+
+// Update keeps the current secret when input has none.
+func (uc *WebhookUseCase) Update(id string, input *SubscriptionInput) (*entity.WebhookSubscription, error) {
+	if err := input.validate(); err != nil {
+		return nil, err
+	}
+
+	subscription, err := uc.get(id)
+	if err != nil {
+		return nil, err
+	}
+	subscription.URL = strings.TrimSpace(input.URL)
+	subscription.Events = input.Events
+	subscription.Description = strings.TrimSpace(input.Description)
+	subscription.Enabled = input.Enabled
+	if secret := strings.TrimSpace(input.Secret); secret != "" {
+		subscription.Secret = secret
+	}
+
+	if err := uc.webhookRepo.UpdateSubscription(subscription); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSubscriptionNotFound
+		}
+		return nil, fmt.Errorf("failed to update webhook subscription: %w", err)
+	}
+	return subscription, nil
+}
+
+func (uc *WebhookUseCase) Delete(id string) error {
+	if err := uc.webhookRepo.DeleteSubscription(id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrSubscriptionNotFound
+		}
+		return fmt.Errorf("failed to delete webhook subscription: %w", err)
+	}
+	return nil
+}
+
+// Deliveries returns the newest maxDeliveryLog deliveries of a subscription.
+func (uc *WebhookUseCase) Deliveries(id string) ([]entity.WebhookDelivery, error) {
+	if _, err := uc.get(id); err != nil {
+		return nil, err
+	}
+	deliveries, err := uc.webhookRepo.ListDeliveries(id, maxDeliveryLog)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// Publish hands event to WebhookWorker, which queues it for every enabled
+// subscription that wants it. It never blocks or touches the database, so
+// callers never wait or fail because of webhooks.
+func (uc *WebhookUseCase) Publish(event string, data any) {
+	if uc == nil {
+		return
+	}
+
+	select {
+	case uc.published <- Event{Event: event, CreatedAt: time.Now(), Data: data}:
+	default:
+		log.Printf("webhook: publish queue is full, dropped %s", event)
+	}
+}
+
+// fanOut stores a delivery of every event for each subscription that wants
+// it, listing the subscriptions once for all of them.
+func (uc *WebhookUseCase) fanOut(events []Event) {
+	subscriptions, err := uc.webhookRepo.ListSubscriptions()
+	if err != nil {
+		log.Printf("webhook: failed to list subscriptions for %d events: %v", len(events), err)
+		return
+	}
+
+	now := time.Now()
+	var deliveries []entity.WebhookDelivery
+	for _, event := range events {
+		for _, subscription := range subscriptions {
+			if !subscription.Subscribes(event.Event) {
+				continue
+			}
+			event.ID = uuid.New().String()
+			payload, err := json.Marshal(event)
+			if err != nil {
+				log.Printf("webhook: failed to encode %s: %v", event.Event, err)
+				break
+			}
+			deliveries = append(deliveries, entity.WebhookDelivery{
+				ID:             event.ID,
+				SubscriptionID: subscription.ID,
+				Event:          event.Event,
+				Payload:        string(payload),
+				Status:         entity.WebhookDeliveryStatusPending,
+				NextAttemptAt:  now,
+			})
+		}
+	}
+
+	if err := uc.webhookRepo.CreateDeliveries(deliveries); err != nil {
+		log.Printf("webhook: failed to queue %d deliveries: %v", len(deliveries), err)
+	}
+}
+
+func (uc *WebhookUseCase) get(id string) (*entity.WebhookSubscription, error) {
+	subscription, err := uc.webhookRepo.GetSubscription(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSubscriptionNotFound
+		}
+		return nil, fmt.Errorf("failed to load webhook subscription: %w", err)
+	}
+	return subscription, nil
+}
+
+func (input *SubscriptionInput) validate() error {
+	parsed, err := url.Parse(strings.TrimSpace(input.URL))
+	if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+		return fmt.Errorf("%w: url must be an http(s) URL", ErrSubscriptionInvalid)
+	}
+	for _, event := range input.Events {
+		if !isKnownEvent(event) {
+			return fmt.Errorf("%w: unknown event %q", ErrSubscriptionInvalid, event)
+		}
+	}
+	return nil
+}
+
+func isKnownEvent(event string) bool {
+	for _, known := range entity.WebhookEvents {
+		if event == known {
+			return true
+		}
+	}
+	return false
+}
+
+func newSecret() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand does not fail on supported platforms.
+		panic(err)
+	}
+	return "whsec_" + hex.EncodeToString(b)
+}
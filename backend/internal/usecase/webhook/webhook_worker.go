@@ -0,0 +1,242 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rikut0904/mailer-backend/internal/domain/entity"
+	"github.com/rikut0904/mailer-backend/internal/domain/repository"
+)
+
+// Request headers. SignatureHeader is "sha256=" followed by the hex
+// HMAC-SHA256 of "<TimestampHeader>.<body>" keyed with the subscription secret.
+const (
+	SignatureHeader = "X-Mailer-Signature"
+	TimestampHeader = "X-Mailer-Timestamp"
+	EventHeader     = "X-Mailer-Event"
+	DeliveryHeader  = "X-Mailer-Delivery"
+)
+
+const (
+	webhookClaimLimit   = 50
+	webhookMaxAttempts  = 10
+	webhookBaseBackoff  = 30 * time.Second
+	webhookMaxBackoff   = time.Hour
+	webhookStaleAfter   = 10 * time.Minute
+	webhookTimeout      = 10 * time.Second
+	maxLoggedErrorBytes = 512
+)
+
+// WebhookWorker turns published events into deliveries and delivers them.
+// Any response other than 2xx, redirects included, and any network error is
+// retried with exponential backoff up to webhookMaxAttempts.
+type WebhookWorker struct {
+	webhookRepo repository.WebhookRepository
+	webhookUC   *WebhookUseCase
+	httpClient  *http.Client
+	interval    time.Duration
+	mu          sync.Mutex
+}
+
+func NewWebhookWorker(webhookRepo repository.WebhookRepository, webhookUC *WebhookUseCase, interval time.Duration) *WebhookWorker {
+	return &WebhookWorker{
+		webhookRepo: webhookRepo,
+		webhookUC:   webhookUC,
+		httpClient: &http.Client{
+			Timeout: webhookTimeout,
+			// A redirect could send the signed payload to another host.
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		interval: interval,
+	}
+}
+
+// Sign returns the SignatureHeader value for body sent at timestamp.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Run fans out published events as they arrive and delivers the queued ones
+// every interval. Deliveries are not sent when interval is 0.
+func (w *WebhookWorker) Run(ctx context.Context) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		w.runFanOut(ctx)
+	}()
+	defer func() { <-done }()
+
+	if w.interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.RunOnce()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runFanOut stores the published events until ctx is done, then stores the
+// ones still waiting. Events that arrived together are stored together.
+func (w *WebhookWorker) runFanOut(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			if events := w.takePublished(nil); len(events) > 0 {
+				w.webhookUC.fanOut(events)
+			}
+			return
+		case event := <-w.webhookUC.published:
+			w.webhookUC.fanOut(w.takePublished([]Event{event}))
+		}
+	}
+}
+
+// takePublished appends the events waiting in the publish queue to events.
+func (w *WebhookWorker) takePublished(events []Event) []Event {
+	for {
+		select {
+		case event := <-w.webhookUC.published:
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+func (w *WebhookWorker) RunOnce() {
+	if !w.mu.TryLock() {
+		return
+	}
+	defer w.mu.Unlock()
+
+	now := time.Now()
+	if n, err := w.webhookRepo.RequeueStaleDeliveries(now.Add(-webhookStaleAfter)); err != nil {
+		log.Printf("webhook: failed to requeue stale deliveries: %v", err)
+	} else if n > 0 {
+		log.Printf("webhook: requeued %d stale deliveries", n)
+	}
+
+	deliveries, err := w.webhookRepo.ClaimDueDeliveries(now, webhookClaimLimit)
+	if err != nil {
+		log.Printf("webhook: failed to claim deliveries: %v", err)
+	}
+
+	subscriptions := map[string]*entity.WebhookSubscription{}
+	for i := range deliveries {
+		delivery := &deliveries[i]
+
+		subscription, ok := subscriptions[delivery.SubscriptionID]
+		if !ok {
+			subscription, err = w.webhookRepo.GetSubscription(delivery.SubscriptionID)
+			if err != nil {
+				subscription = nil
+			}
+			subscriptions[delivery.SubscriptionID] = subscription
+		}
+		if subscription == nil || !subscription.Enabled {
+			w.fail(delivery, "subscription was deleted or disabled")
+			continue
+		}
+
+		status, err := w.post(subscription, delivery)
+		w.finish(delivery, status, err)
+	}
+}
+
+func (w *WebhookWorker) post(subscription *entity.WebhookSubscription, delivery *entity.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequest(http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "mailer-webhook/1")
+	req.Header.Set(EventHeader, delivery.Event)
+	req.Header.Set(DeliveryHeader, delivery.ID)
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(subscription.Secret, timestamp, body))
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxLoggedErrorBytes))
+		return resp.StatusCode, fmt.Errorf("receiver returned status %d: %s", resp.StatusCode, bytes.TrimSpace(snippet))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return resp.StatusCode, nil
+}
+
+// finish records the outcome of one delivery attempt.
+func (w *WebhookWorker) finish(delivery *entity.WebhookDelivery, status int, postErr error) {
+	now := time.Now()
+	delivery.Attempts++
+	delivery.ResponseStatus = status
+
+	switch {
+	case postErr == nil:
+		delivery.Status = entity.WebhookDeliveryStatusSucceeded
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+	case delivery.Attempts < webhookMaxAttempts:
+		delivery.Status = entity.WebhookDeliveryStatusPending
+		delivery.NextAttemptAt = now.Add(webhookBackoff(delivery.Attempts))
+		delivery.LastError = postErr.Error()
+	default:
+		delivery.Status = entity.WebhookDeliveryStatusFailed
+		delivery.LastError = postErr.Error()
+	}
+
+	if err := w.webhookRepo.UpdateDelivery(delivery); err != nil {
+		log.Printf("webhook: failed to update delivery %s: %v", delivery.ID, err)
+	}
+}
+
+func (w *WebhookWorker) fail(delivery *entity.WebhookDelivery, reason string) {
+	delivery.Status = entity.WebhookDeliveryStatusFailed
+	delivery.LastError = reason
+	if err := w.webhookRepo.UpdateDelivery(delivery); err != nil {
+		log.Printf("webhook: failed to update delivery %s: %v", delivery.ID, err)
+	}
+}
+
+func webhookBackoff(attempts int) time.Duration {
+	backoff := webhookBaseBackoff
+	for i := 1; i < attempts && backoff < webhookMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > webhookMaxBackoff {
+		return webhookMaxBackoff
+	}
+	return backoff
+}
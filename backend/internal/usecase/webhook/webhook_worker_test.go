@@ -0,0 +1,300 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rikut0904/mailer-backend/internal/domain/entity"
+	"gorm.io/gorm"
+)
+
+// fakeWebhookRepo keeps subscriptions and deliveries in memory.
+type fakeWebhookRepo struct {
+	mu            sync.Mutex
+	subscriptions []entity.WebhookSubscription
+	deliveries    []entity.WebhookDelivery
+}
+
+func (r *fakeWebhookRepo) ListSubscriptions() ([]entity.WebhookSubscription, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]entity.WebhookSubscription(nil), r.subscriptions...), nil
+}
+
+func (r *fakeWebhookRepo) GetSubscription(id string) (*entity.WebhookSubscription, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, subscription := range r.subscriptions {
+		if subscription.ID == id {
+			return &subscription, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeWebhookRepo) CreateSubscription(subscription *entity.WebhookSubscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subscriptions = append(r.subscriptions, *subscription)
+	return nil
+}
+
+func (r *fakeWebhookRepo) UpdateSubscription(subscription *entity.WebhookSubscription) error {
+	return nil
+}
+
+func (r *fakeWebhookRepo) DeleteSubscription(id string) error {
+	return nil
+}
+
+func (r *fakeWebhookRepo) CreateDeliveries(deliveries []entity.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deliveries = append(r.deliveries, deliveries...)
+	return nil
+}
+
+func (r *fakeWebhookRepo) ListDeliveries(subscriptionID string, limit int) ([]entity.WebhookDelivery, error) {
+	return nil, nil
+}
+
+func (r *fakeWebhookRepo) ClaimDueDeliveries(now time.Time, limit int) ([]entity.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var claimed []entity.WebhookDelivery
+	for i := range r.deliveries {
+		delivery := &r.deliveries[i]
+		if delivery.Status == entity.WebhookDeliveryStatusPending && !delivery.NextAttemptAt.After(now) {
+			delivery.Status = entity.WebhookDeliveryStatusSending
+			claimed = append(claimed, *delivery)
+		}
+	}
+	return claimed, nil
+}
+
+func (r *fakeWebhookRepo) RequeueStaleDeliveries(before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (r *fakeWebhookRepo) UpdateDelivery(delivery *entity.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.deliveries {
+		if r.deliveries[i].ID == delivery.ID {
+			r.deliveries[i] = *delivery
+		}
+	}
+	return nil
+}
+
+func (r *fakeWebhookRepo) delivery(t *testing.T) entity.WebhookDelivery {
+	t.Helper()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.deliveries) != 1 {
+		t.Fatalf("got %d deliveries, want 1", len(r.deliveries))
+	}
+	return r.deliveries[0]
+}
+
+// makeDue lets the next RunOnce retry the delivery without waiting out the backoff.
+func (r *fakeWebhookRepo) makeDue() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.deliveries {
+		r.deliveries[i].NextAttemptAt = time.Now()
+	}
+}
+
+func newTestWorker(t *testing.T, handler http.HandlerFunc, events ...string) (*WebhookWorker, *fakeWebhookRepo) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	repo := &fakeWebhookRepo{subscriptions: []entity.WebhookSubscription{{
+		ID:      "sub-1",
+		URL:     server.URL,
+		Events:  events,
+		Secret:  "whsec_test",
+		Enabled: true,
+	}}}
+	uc := NewWebhookUseCase(repo)
+	return NewWebhookWorker(repo, uc, time.Minute), repo
+}
+
+// publish runs Publish through the fan-out the way Run does.
+func publish(w *WebhookWorker, event string, data any) {
+	w.webhookUC.Publish(event, data)
+	w.webhookUC.fanOut(w.takePublished(nil))
+}
+
+func TestWebhookWorkerSignsRequests(t *testing.T) {
+	type received struct {
+		header http.Header
+		body   []byte
+	}
+	requests := make(chan received, 1)
+	worker, repo := newTestWorker(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- received{header: r.Header.Clone(), body: body}
+	})
+
+	publish(worker, entity.WebhookEventMailSent, SentMailData{ManagementCode: "code-1", Recipient: "user@example.com"})
+	worker.RunOnce()
+
+	req := <-requests
+	timestamp := req.header.Get(TimestampHeader)
+	if _, err := strconv.ParseInt(timestamp, 10, 64); err != nil {
+		t.Fatalf("%s = %q: %v", TimestampHeader, timestamp, err)
+	}
+	mac := hmac.New(sha256.New, []byte("whsec_test"))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(req.body)
+	if got, want := req.header.Get(SignatureHeader), "sha256="+hex.EncodeToString(mac.Sum(nil)); got != want {
+		t.Errorf("%s = %q, want %q", SignatureHeader, got, want)
+	}
+
+	delivery := repo.delivery(t)
+	if got := req.header.Get(DeliveryHeader); got != delivery.ID {
+		t.Errorf("%s = %q, want %q", DeliveryHeader, got, delivery.ID)
+	}
+	if got := req.header.Get(EventHeader); got != entity.WebhookEventMailSent {
+		t.Errorf("%s = %q, want %q", EventHeader, got, entity.WebhookEventMailSent)
+	}
+
+	var event Event
+	if err := json.Unmarshal(req.body, &event); err != nil {
+		t.Fatalf("body: %v", err)
+	}
+	if event.ID != delivery.ID || event.Event != entity.WebhookEventMailSent {
+		t.Errorf("body = %s", req.body)
+	}
+	if delivery.Status != entity.WebhookDeliveryStatusSucceeded || delivery.ResponseStatus != http.StatusOK {
+		t.Errorf("delivery status = %s (%d), want succeeded (200)", delivery.Status, delivery.ResponseStatus)
+	}
+}
+
+func TestWebhookWorkerRetriesWithBackoff(t *testing.T) {
+	var calls int
+	worker, repo := newTestWorker(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+		}
+	})
+
+	publish(worker, entity.WebhookEventMailReceived, MailData{DomainID: "d", S3Key: "k"})
+	for attempt, want := range []time.Duration{webhookBaseBackoff, 2 * webhookBaseBackoff} {
+		before := time.Now()
+		worker.RunOnce()
+
+		delivery := repo.delivery(t)
+		if delivery.Status != entity.WebhookDeliveryStatusPending || delivery.Attempts != attempt+1 {
+			t.Fatalf("attempt %d: status %s, attempts %d", attempt+1, delivery.Status, delivery.Attempts)
+		}
+		if delivery.ResponseStatus != http.StatusServiceUnavailable || delivery.LastError == "" {
+			t.Errorf("attempt %d: response %d, error %q", attempt+1, delivery.ResponseStatus, delivery.LastError)
+		}
+		if wait := delivery.NextAttemptAt.Sub(before); wait < want || wait > want+time.Second {
+			t.Errorf("attempt %d: retried after %s, want %s", attempt+1, wait, want)
+		}
+
+		// Not due yet: nothing is sent.
+		worker.RunOnce()
+		if calls != attempt+1 {
+			t.Fatalf("sent %d times before the backoff passed", calls)
+		}
+		repo.makeDue()
+	}
+
+	worker.RunOnce()
+	if delivery := repo.delivery(t); delivery.Status != entity.WebhookDeliveryStatusSucceeded || delivery.Attempts != 3 {
+		t.Errorf("status %s after %d attempts, want succeeded after 3", delivery.Status, delivery.Attempts)
+	}
+}
+
+func TestWebhookWorkerGivesUpAfterMaxAttempts(t *testing.T) {
+	worker, repo := newTestWorker(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	publish(worker, entity.WebhookEventMailDeleted, MailData{DomainID: "d", S3Key: "k"})
+	for range webhookMaxAttempts {
+		repo.makeDue()
+		worker.RunOnce()
+	}
+	if delivery := repo.delivery(t); delivery.Status != entity.WebhookDeliveryStatusFailed || delivery.Attempts != webhookMaxAttempts {
+		t.Errorf("status %s after %d attempts, want failed after %d", delivery.Status, delivery.Attempts, webhookMaxAttempts)
+	}
+}
+
+func TestWebhookWorkerDoesNotFollowRedirects(t *testing.T) {
+	redirected := false
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		redirected = true
+	}))
+	defer target.Close()
+
+	worker, repo := newTestWorker(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusTemporaryRedirect)
+	})
+
+	publish(worker, entity.WebhookEventMailSent, SentMailData{ManagementCode: "code-1"})
+	worker.RunOnce()
+
+	if redirected {
+		t.Error("the signed request was sent to the redirect target")
+	}
+	if delivery := repo.delivery(t); delivery.Status != entity.WebhookDeliveryStatusPending || delivery.ResponseStatus != http.StatusTemporaryRedirect {
+		t.Errorf("status %s (%d), want pending (307)", delivery.Status, delivery.ResponseStatus)
+	}
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{7, 32 * time.Minute},
+		{8, time.Hour},
+		{webhookMaxAttempts, time.Hour},
+	}
+	for _, tt := range tests {
+		if got := webhookBackoff(tt.attempts); got != tt.want {
+			t.Errorf("webhookBackoff(%d) = %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestPublishFansOutOnTheWorker(t *testing.T) {
+	worker, repo := newTestWorker(t, func(w http.ResponseWriter, r *http.Request) {}, entity.WebhookEventMailSent)
+	repo.subscriptions = append(repo.subscriptions, entity.WebhookSubscription{ID: "disabled"})
+
+	worker.webhookUC.Publish(entity.WebhookEventMailSent, SentMailData{ManagementCode: "code-1"})
+	worker.webhookUC.Publish(entity.WebhookEventMailReceived, MailData{DomainID: "d", S3Key: "k"})
+	if len(repo.deliveries) != 0 {
+		t.Fatal("Publish stored deliveries itself")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	worker.runFanOut(ctx)
+
+	delivery := repo.delivery(t)
+	if delivery.SubscriptionID != "sub-1" || delivery.Event != entity.WebhookEventMailSent {
+		t.Errorf("delivery = %s for %s, want mail.sent for sub-1", delivery.Event, delivery.SubscriptionID)
+	}
+}
@@ -2,7 +2,10 @@ package config
 
 import (
 	"fmt"
+	"log"
 	"os"
+	"strconv"
+	"time"
 )
 
 type Config struct {
@@ -12,18 +15,55 @@ type Config struct {
 	FirebaseAPIKey     string
 	FirebaseAuthDomain string
 	AllowedOrigins     []string
-	AutoMigrate        bool
+	// AppURL is the frontend base URL used for links in notifications
+	AppURL               string
+	AutoMigrate          bool
+	InboundToken         string
+	VerifySNSSignature   bool
+	SyncInterval         time.Duration
+	SyncFullScanInterval time.Duration
+	LocalStorageRoot     string
+	OutboxPollInterval   time.Duration
+	WebhookPollInterval  time.Duration
+	SendUndoWindow       time.Duration
+	// SendRateLimit is the maximum number of messages sent per second; set it
+	// to the SES account's maximum send rate. 0 disables throttling.
+	SendRateLimit int
+	// UnreadDigestFrom is the sender of unread digest mails; empty disables them
+	UnreadDigestFrom string
 }
 
+const (
+	minSendUndoWindow = 10 * time.Second
+	maxSendUndoWindow = 30 * time.Second
+)
+
 func Load() (*Config, error) {
 	cfg := &Config{
-		Port:               getEnv("PORT", "8080"),
-		DatabaseURL:        os.Getenv("DATABASE_URL"),
-		FirebaseProjectID:  os.Getenv("FIREBASE_PROJECT_ID"),
-		FirebaseAPIKey:     os.Getenv("FIREBASE_API_KEY"),
-		FirebaseAuthDomain: os.Getenv("FIREBASE_AUTH_DOMAIN"),
-		AllowedOrigins:     []string{getEnv("ALLOWED_ORIGIN", "http://localhost:3000")},
-		AutoMigrate:        getEnvBool("AUTO_MIGRATE", true),
+		Port:                 getEnv("PORT", "8080"),
+		DatabaseURL:          os.Getenv("DATABASE_URL"),
+		FirebaseProjectID:    os.Getenv("FIREBASE_PROJECT_ID"),
+		FirebaseAPIKey:       os.Getenv("FIREBASE_API_KEY"),
+		FirebaseAuthDomain:   os.Getenv("FIREBASE_AUTH_DOMAIN"),
+		AllowedOrigins:       []string{getEnv("ALLOWED_ORIGIN", "http://localhost:3000")},
+		AppURL:               getEnv("APP_URL", getEnv("ALLOWED_ORIGIN", "http://localhost:3000")),
+		AutoMigrate:          getEnvBool("AUTO_MIGRATE", true),
+		InboundToken:         os.Getenv("INBOUND_TOKEN"),
+		VerifySNSSignature:   getEnvBool("VERIFY_SNS_SIGNATURE", true),
+		SyncInterval:         getEnvDuration("SYNC_INTERVAL", 5*time.Minute),
+		SyncFullScanInterval: getEnvDuration("SYNC_FULL_SCAN_INTERVAL", 24*time.Hour),
+		LocalStorageRoot:     os.Getenv("LOCAL_STORAGE_ROOT"),
+		OutboxPollInterval:   getEnvDuration("OUTBOX_POLL_INTERVAL", 5*time.Second),
+		WebhookPollInterval:  getEnvDuration("WEBHOOK_POLL_INTERVAL", 5*time.Second),
+		SendUndoWindow:       getEnvDuration("SEND_UNDO_WINDOW", 10*time.Second),
+		SendRateLimit:        getEnvInt("SEND_RATE_LIMIT", 1),
+		UnreadDigestFrom:     os.Getenv("UNREAD_DIGEST_FROM"),
+	}
+
+	if cfg.SendUndoWindow < minSendUndoWindow || cfg.SendUndoWindow > maxSendUndoWindow {
+		clamped := min(max(cfg.SendUndoWindow, minSendUndoWindow), maxSendUndoWindow)
+		log.Printf("SEND_UNDO_WINDOW %s is outside %s-%s, using %s", cfg.SendUndoWindow, minSendUndoWindow, maxSendUndoWindow, clamped)
+		cfg.SendUndoWindow = clamped
 	}
 
 	if cfg.DatabaseURL == "" {
@@ -51,3 +91,21 @@ func getEnvBool(key string, fallback bool) bool {
 	}
 	return fallback
 }
+
+func getEnvInt(key string, fallback int) int {
+	if v := os.Getenv(key); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			return n
+		}
+	}
+	return fallback
+}
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	if v := os.Getenv(key); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
+	}
+	return fallback
+}
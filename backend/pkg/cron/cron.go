@@ -0,0 +1,167 @@
+// Package cron parses five-field cron expressions
+// ("minute hour day-of-month month day-of-week").
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a parsed cron expression. Fields accept "*", numbers, ranges
+// ("1-5"), lists ("1,15") and steps ("*/15", "0-30/10"). Day of week is 0-6
+// with 0 (or 7) for Sunday. As in cron, when both day of month and day of
+// week are restricted a day matching either one matches.
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+	domStar, hourStar, dowStar    bool
+}
+
+var aliases = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+}
+
+type bounds struct{ min, max int }
+
+var fieldBounds = []bounds{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7}}
+
+// Parse parses spec, which may also be @hourly, @daily, @weekly or @monthly.
+func Parse(spec string) (*Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if alias, ok := aliases[spec]; ok {
+		spec = alias
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron: expected 5 fields, got %d", len(fields))
+	}
+
+	var bits [5]uint64
+	for i, field := range fields {
+		b, err := parseField(field, fieldBounds[i])
+		if err != nil {
+			return nil, err
+		}
+		bits[i] = b
+	}
+	// 7 is Sunday too.
+	if bits[4]&(1<<7) != 0 {
+		bits[4] = bits[4]&^(1<<7) | 1
+	}
+
+	return &Schedule{
+		minute:   bits[0],
+		hour:     bits[1],
+		dom:      bits[2],
+		month:    bits[3],
+		dow:      bits[4],
+		domStar:  strings.HasPrefix(fields[2], "*"),
+		hourStar: strings.HasPrefix(fields[1], "*"),
+		dowStar:  strings.HasPrefix(fields[4], "*"),
+	}, nil
+}
+
+func parseField(field string, b bounds) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("cron: invalid step in %q", part)
+			}
+			rangePart, step = part[:i], n
+		}
+
+		from, to := b.min, b.max
+		if rangePart != "*" {
+			bounds := strings.SplitN(rangePart, "-", 2)
+			n, err := strconv.Atoi(bounds[0])
+			if err != nil {
+				return 0, fmt.Errorf("cron: invalid value %q", part)
+			}
+			from, to = n, n
+			if len(bounds) == 2 {
+				if to, err = strconv.Atoi(bounds[1]); err != nil {
+					return 0, fmt.Errorf("cron: invalid value %q", part)
+				}
+			} else if step > 1 {
+				to = b.max
+			}
+		}
+		if from < b.min || to > b.max || from > to {
+			return 0, fmt.Errorf("cron: %q out of range %d-%d", part, b.min, b.max)
+		}
+		for v := from; v <= to; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// Next returns the first minute after t that matches the schedule, in t's
+// location. It returns the zero time when nothing matches within five years
+// (e.g. "0 0 30 2 *").
+//
+// Wall times skipped when clocks go forward do not match. Wall times repeated
+// when clocks go back match once, unless the hour field is "*" or a step over
+// it, so that hourly schedules keep running every hour.
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = after(t, t.Year(), t.Month()+1, 1, 0)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = after(t, t.Year(), t.Month(), t.Day()+1, 0)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = after(t, t.Year(), t.Month(), t.Day(), t.Hour()+1)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		if !s.hourStar && repeated(t) {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// after returns the start of the given hour in t's location. time.Date moves
+// an hour skipped when clocks go forward back to the hour before, which may
+// not be after t; the hour after the gap is used then.
+func after(t time.Time, year int, month time.Month, day, hour int) time.Time {
+	next := time.Date(year, month, day, hour, 0, 0, 0, t.Location())
+	if !next.After(t) {
+		next = next.Add(time.Hour)
+	}
+	return next
+}
+
+// repeated reports whether the wall time of t already occurred an hour
+// earlier, i.e. t is in the hour repeated when clocks go back.
+func repeated(t time.Time) bool {
+	earlier := t.Add(-time.Hour)
+	return earlier.Hour() == t.Hour() && earlier.Minute() == t.Minute()
+}
+
+func (s *Schedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
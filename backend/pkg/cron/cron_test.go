@@ -0,0 +1,164 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseErrors(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * 32 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"a * * * *",
+		"1-a * * * *",
+		"@yearly",
+	} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", spec)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := func(value string) time.Time {
+		t.Helper()
+		parsed, err := time.ParseInLocation("2006-01-02 15:04", value, tokyo)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return parsed
+	}
+
+	// 2026-10-16 and 2026-11-13 are Fridays.
+	tests := []struct {
+		spec, from, want string
+	}{
+		{"* * * * *", "2026-10-16 10:07", "2026-10-16 10:08"},
+		{"*/15 * * * *", "2026-10-16 10:07", "2026-10-16 10:15"},
+		{"*/15 * * * *", "2026-10-16 23:50", "2026-10-17 00:00"},
+		{"5/15 * * * *", "2026-10-16 10:21", "2026-10-16 10:35"},
+		{"0-30/10 8 * * *", "2026-10-16 08:25", "2026-10-16 08:30"},
+		{"0-30/10 8 * * *", "2026-10-16 08:30", "2026-10-17 08:00"},
+		{"0 9-17/4 * * *", "2026-10-16 13:00", "2026-10-16 17:00"},
+		{"0 0 1,15 * *", "2026-01-02 00:00", "2026-01-15 00:00"},
+		{"0 0 1 */3 *", "2026-02-10 00:00", "2026-04-01 00:00"},
+		{"0 9 * * 1-5", "2026-10-16 09:00", "2026-10-19 09:00"},
+		{"0 9 * * 7", "2026-10-16 09:00", "2026-10-18 09:00"},
+		{"0 9 * * 0", "2026-10-16 09:00", "2026-10-18 09:00"},
+		{"0 0 31 * *", "2026-04-01 00:00", "2026-05-31 00:00"},
+		{"0 0 29 2 *", "2026-01-01 00:00", "2028-02-29 00:00"},
+		// Day of month and day of week both restricted: either one matches.
+		{"0 0 13 * 5", "2026-10-12 00:00", "2026-10-13 00:00"},
+		{"0 0 13 * 5", "2026-10-13 00:00", "2026-10-16 00:00"},
+		// Either one a star: both must match.
+		{"0 0 13 * *", "2026-10-13 00:00", "2026-11-13 00:00"},
+		{"0 0 * * 5", "2026-10-13 00:00", "2026-10-16 00:00"},
+		{"0 0 */13 * 5", "2026-10-13 00:00", "2026-11-27 00:00"},
+		{"@hourly", "2026-10-16 10:07", "2026-10-16 11:00"},
+		{"@daily", "2026-10-16 10:07", "2026-10-17 00:00"},
+		{"@weekly", "2026-10-16 10:07", "2026-10-18 00:00"},
+		{"@monthly", "2026-10-16 10:07", "2026-11-01 00:00"},
+	}
+	for _, tt := range tests {
+		schedule, err := Parse(tt.spec)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.spec, err)
+			continue
+		}
+		if got, want := schedule.Next(at(tt.from)), at(tt.want); !got.Equal(want) {
+			t.Errorf("%q after %s = %s, want %s", tt.spec, tt.from, got, want)
+		}
+	}
+}
+
+func TestNextNeverMatches(t *testing.T) {
+	schedule, err := Parse("0 0 30 2 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := schedule.Next(time.Now()); !got.IsZero() {
+		t.Errorf("Next = %s, want the zero time", got)
+	}
+}
+
+func TestNextDST(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Clocks go forward at 2026-03-08 02:00 EST and back at 2026-11-01 02:00 EDT.
+	tests := []struct {
+		name, spec string
+		from, want time.Time
+	}{
+		{
+			"skipped time does not match",
+			"30 2 * * *",
+			time.Date(2026, 3, 7, 3, 0, 0, 0, newYork),
+			time.Date(2026, 3, 9, 2, 30, 0, 0, newYork),
+		},
+		{
+			"time after the gap",
+			"30 3 * * *",
+			time.Date(2026, 3, 8, 0, 0, 0, 0, newYork),
+			time.Date(2026, 3, 8, 3, 30, 0, 0, newYork),
+		},
+		{
+			"hourly across the gap",
+			"30 * * * *",
+			time.Date(2026, 3, 8, 1, 30, 0, 0, newYork),
+			time.Date(2026, 3, 8, 3, 30, 0, 0, newYork),
+		},
+		{
+			"repeated time matches first",
+			"30 1 * * *",
+			time.Date(2026, 11, 1, 0, 0, 0, 0, newYork),
+			time.Date(2026, 11, 1, 5, 30, 0, 0, time.UTC),
+		},
+		{
+			"repeated time matches once",
+			"30 1 * * *",
+			time.Date(2026, 11, 1, 5, 30, 0, 0, time.UTC).In(newYork),
+			time.Date(2026, 11, 2, 1, 30, 0, 0, newYork),
+		},
+		{
+			"hourly in the repeated hour",
+			"30 * * * *",
+			time.Date(2026, 11, 1, 5, 30, 0, 0, time.UTC).In(newYork),
+			time.Date(2026, 11, 1, 6, 30, 0, 0, time.UTC),
+		},
+		{
+			"daily in UTC is a day apart",
+			"0 12 * * *",
+			time.Date(2026, 10, 31, 12, 0, 0, 0, newYork),
+			time.Date(2026, 11, 1, 12, 0, 0, 0, newYork),
+		},
+	}
+	for _, tt := range tests {
+		schedule, err := Parse(tt.spec)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		got := schedule.Next(tt.from)
+		if !got.Equal(tt.want) {
+			t.Errorf("%s: %q after %s = %s, want %s", tt.name, tt.spec, tt.from, got, tt.want)
+		}
+		if got.Location() != newYork {
+			t.Errorf("%s: Next returned a time in %s", tt.name, got.Location())
+		}
+	}
+}
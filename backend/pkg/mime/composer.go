@@ -0,0 +1,358 @@
+package mime
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/rikut0904/mailer-backend/internal/domain/entity"
+	"golang.org/x/net/idna"
+)
+
+// MaxRawMessageSize is the SES v2 limit for a raw message, after encoding.
+const MaxRawMessageSize = 40 * 1024 * 1024
+
+var ErrMessageTooLarge = errors.New("message exceeds the maximum size")
+
+// maxLineLength is the line length RFC 5322 asks header fields to be folded at.
+const maxLineLength = 78
+
+var headerSanitizer = strings.NewReplacer("\r\n", " ", "\r", " ", "\n", " ")
+
+// part is one MIME entity: its headers and a function writing its encoded body.
+type part struct {
+	header textproto.MIMEHeader
+	write  func(w io.Writer) error
+}
+
+// BuildRawMessage renders the copy of outgoing addressed to its envelope
+// recipient. It is shared by every MailSenderRepository implementation.
+//
+// Text parts are quoted-printable, attachments base64; header fields are
+// folded, non-ASCII display names and subjects are RFC 2047 encoded and
+// internationalized domains are converted to punycode. Date is always set and
+// a Message-ID is generated when outgoing has none.
+func BuildRawMessage(outgoing *entity.OutgoingMail) ([]byte, error) {
+	from, err := formatAddress(outgoing.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid from: %w", err)
+	}
+	to, err := formatAddressList(outgoing.To)
+	if err != nil {
+		return nil, fmt.Errorf("invalid to: %w", err)
+	}
+	cc, err := formatAddressList(outgoing.Cc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cc: %w", err)
+	}
+	var replyTo string
+	if strings.TrimSpace(outgoing.ReplyTo) != "" {
+		if replyTo, err = formatAddressList([]string{outgoing.ReplyTo}); err != nil {
+			return nil, fmt.Errorf("invalid reply-to: %w", err)
+		}
+	}
+
+	messageID := outgoing.MessageID
+	if messageID == "" {
+		messageID = generateMessageID(outgoing.From)
+	}
+
+	var buf bytes.Buffer
+	writeHeader(&buf, "Date", time.Now().Format(time.RFC1123Z))
+	writeHeader(&buf, "From", from)
+	if to != "" {
+		writeHeader(&buf, "To", to)
+	} else {
+		writeHeader(&buf, "To", "undisclosed-recipients:;")
+	}
+	if cc != "" {
+		writeHeader(&buf, "Cc", cc)
+	}
+	if replyTo != "" {
+		writeHeader(&buf, "Reply-To", replyTo)
+	}
+	writeHeader(&buf, "Subject", encodeText(outgoing.Subject))
+	writeHeader(&buf, "Message-ID", messageID)
+	if outgoing.InReplyTo != "" {
+		writeHeader(&buf, "In-Reply-To", outgoing.InReplyTo)
+	}
+	if len(outgoing.References) > 0 {
+		writeHeader(&buf, "References", strings.Join(outgoing.References, " "))
+	}
+	if outgoing.ManagementCode != "" {
+		writeHeader(&buf, entity.ManagementCodeHeader, outgoing.ManagementCode)
+	}
+	writeHeader(&buf, "MIME-Version", "1.0")
+
+	root := bodyPart(outgoing)
+	for _, key := range sortedKeys(root.header) {
+		for _, value := range root.header[key] {
+			writeHeader(&buf, key, value)
+		}
+	}
+	buf.WriteString("\r\n")
+	if err := root.write(&buf); err != nil {
+		return nil, fmt.Errorf("failed to encode message body: %w", err)
+	}
+
+	if buf.Len() > MaxRawMessageSize {
+		return nil, fmt.Errorf("%w: %d bytes (limit %d bytes)", ErrMessageTooLarge, buf.Len(), MaxRawMessageSize)
+	}
+	return buf.Bytes(), nil
+}
+
+// bodyPart nests the text, optional HTML alternative and attachments as
+// multipart/mixed > multipart/alternative > text/plain, text/html, leaving out
+// multipart levels that would hold a single part.
+func bodyPart(outgoing *entity.OutgoingMail) part {
+	body := textPart("text/plain", outgoing.TextBody)
+	if outgoing.HTMLBody != "" {
+		body = multipartPart("alternative", []part{body, textPart("text/html", outgoing.HTMLBody)})
+	}
+	if len(outgoing.Attachments) == 0 {
+		return body
+	}
+
+	parts := []part{body}
+	for _, att := range outgoing.Attachments {
+		parts = append(parts, attachmentPart(att))
+	}
+	return multipartPart("mixed", parts)
+}
+
+func textPart(mediaType, text string) part {
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", mime.FormatMediaType(mediaType, map[string]string{"charset": "UTF-8"}))
+	header.Set("Content-Transfer-Encoding", "quoted-printable")
+
+	return part{
+		header: header,
+		write: func(w io.Writer) error {
+			qp := quotedprintable.NewWriter(w)
+			if _, err := io.WriteString(qp, text); err != nil {
+				return err
+			}
+			if err := qp.Close(); err != nil {
+				return err
+			}
+			_, err := io.WriteString(w, "\r\n")
+			return err
+		},
+	}
+}
+
+func attachmentPart(att entity.Attachment) part {
+	contentType := att.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType, params = "application/octet-stream", map[string]string{}
+	}
+	params["name"] = att.Filename
+
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", mime.FormatMediaType(mediaType, params))
+	header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": att.Filename}))
+	header.Set("Content-Transfer-Encoding", "base64")
+
+	return part{
+		header: header,
+		write: func(w io.Writer) error {
+			encoded := base64.StdEncoding.EncodeToString(att.Content)
+			for len(encoded) > 0 {
+				n := min(76, len(encoded))
+				if _, err := io.WriteString(w, encoded[:n]+"\r\n"); err != nil {
+					return err
+				}
+				encoded = encoded[n:]
+			}
+			return nil
+		},
+	}
+}
+
+func multipartPart(subtype string, parts []part) part {
+	boundary := randomBoundary()
+
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", mime.FormatMediaType("multipart/"+subtype, map[string]string{"boundary": boundary}))
+
+	return part{
+		header: header,
+		write: func(w io.Writer) error {
+			mw := multipart.NewWriter(w)
+			if err := mw.SetBoundary(boundary); err != nil {
+				return err
+			}
+			for _, p := range parts {
+				pw, err := mw.CreatePart(p.header)
+				if err != nil {
+					return err
+				}
+				if err := p.write(pw); err != nil {
+					return err
+				}
+			}
+			return mw.Close()
+		},
+	}
+}
+
+// writeHeader writes a header field, folding it so lines stay within
+// maxLineLength where possible. It only folds at a single space between words:
+// unfolding turns the line break and indentation into one space, so the value
+// reads back unchanged. CR and LF in value are replaced to prevent header
+// injection.
+func writeHeader(buf *bytes.Buffer, name, value string) {
+	value = headerSanitizer.Replace(value)
+
+	var pieces []string
+	start := 0
+	for i := 1; i < len(value)-1; i++ {
+		if value[i] == ' ' && !isHeaderSpace(value[i-1]) && !isHeaderSpace(value[i+1]) {
+			pieces = append(pieces, value[start:i])
+			start = i
+		}
+	}
+	pieces = append(pieces, value[start:])
+
+	line := name + ":"
+	if value != "" {
+		line += " " + pieces[0]
+	}
+	for _, piece := range pieces[1:] {
+		if len(line)+len(piece) > maxLineLength {
+			buf.WriteString(line)
+			buf.WriteString("\r\n")
+			line = ""
+		}
+		line += piece
+	}
+	buf.WriteString(line)
+	buf.WriteString("\r\n")
+}
+
+func isHeaderSpace(c byte) bool {
+	return c == ' ' || c == '\t'
+}
+
+// maxEncodedWordBytes keeps each B-encoded word (12 characters of framing
+// plus 4/3 of the bytes) short enough to follow a field name on one line.
+const maxEncodedWordBytes = 39
+
+// encodeText returns s as-is when it is printable ASCII and as B-encoded
+// words otherwise; B is more compact than Q for Japanese text. The words are
+// split at rune boundaries so the header can be folded between them.
+func encodeText(s string) string {
+	s = headerSanitizer.Replace(s)
+	ascii := true
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf || (s[i] < ' ' && s[i] != '\t') {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		return s
+	}
+
+	var words []string
+	for len(s) > 0 {
+		n := 0
+		for n < len(s) {
+			_, size := utf8.DecodeRuneInString(s[n:])
+			if n+size > maxEncodedWordBytes {
+				break
+			}
+			n += size
+		}
+		words = append(words, "=?UTF-8?b?"+base64.StdEncoding.EncodeToString([]byte(s[:n]))+"?=")
+		s = s[n:]
+	}
+	return strings.Join(words, " ")
+}
+
+func formatAddressList(raw []string) (string, error) {
+	var formatted []string
+	for _, entry := range raw {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		addresses, err := mail.ParseAddressList(entry)
+		if err != nil {
+			return "", fmt.Errorf("%q: %w", entry, err)
+		}
+		for _, addr := range addresses {
+			formatted = append(formatted, formatParsedAddress(addr))
+		}
+	}
+	return strings.Join(formatted, ", "), nil
+}
+
+func formatAddress(raw string) (string, error) {
+	addr, err := mail.ParseAddress(raw)
+	if err != nil {
+		return "", fmt.Errorf("%q: %w", raw, err)
+	}
+	return formatParsedAddress(addr), nil
+}
+
+// formatParsedAddress converts the domain to its ASCII (punycode) form.
+// net/mail encodes non-ASCII display names as RFC 2047 words.
+func formatParsedAddress(addr *mail.Address) string {
+	if at := strings.LastIndex(addr.Address, "@"); at != -1 {
+		if domain, err := idna.Lookup.ToASCII(addr.Address[at+1:]); err == nil {
+			addr.Address = addr.Address[:at+1] + domain
+		}
+	}
+	return addr.String()
+}
+
+func generateMessageID(from string) string {
+	domain := "localhost"
+	if addr, err := mail.ParseAddress(from); err == nil {
+		if at := strings.LastIndex(addr.Address, "@"); at != -1 {
+			if ascii, err := idna.Lookup.ToASCII(addr.Address[at+1:]); err == nil {
+				domain = ascii
+			}
+		}
+	}
+	return fmt.Sprintf("<%s@%s>", randomHex(16), domain)
+}
+
+func randomBoundary() string {
+	return "=_" + randomHex(15)
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand does not fail on supported platforms.
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+func sortedKeys(header textproto.MIMEHeader) []string {
+	keys := make([]string, 0, len(header))
+	for key := range header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,240 @@
+package mime
+
+import (
+	"bytes"
+	"net/mail"
+	"strings"
+	"testing"
+
+	"github.com/rikut0904/mailer-backend/internal/domain/entity"
+)
+
+func buildAndParse(t *testing.T, outgoing *entity.OutgoingMail) ([]byte, *entity.ParsedMail) {
+	t.Helper()
+	raw, err := BuildRawMessage(outgoing)
+	if err != nil {
+		t.Fatalf("BuildRawMessage: %v", err)
+	}
+	parsed, err := Parse(raw, "test")
+	if err != nil {
+		t.Fatalf("Parse: %v\n%s", err, raw)
+	}
+	return raw, parsed
+}
+
+func TestBuildRawMessageAddresses(t *testing.T) {
+	raw, parsed := buildAndParse(t, &entity.OutgoingMail{
+		From:     "山田 太郎 <taro@example.com>",
+		To:       []string{"Ünïcödé User <user@例え.jp>", "plain@example.org"},
+		Cc:       []string{"\"Doe, Jane\" <jane@bücher.de>"},
+		ReplyTo:  "返信先 <reply@example.com>",
+		Subject:  "hello",
+		TextBody: "body",
+	})
+
+	from, err := mail.ParseAddress(parsed.From)
+	if err != nil {
+		t.Fatalf("From %q: %v", parsed.From, err)
+	}
+	if from.Name != "山田 太郎" || from.Address != "taro@example.com" {
+		t.Errorf("From = %q <%s>", from.Name, from.Address)
+	}
+
+	to, err := mail.ParseAddressList(parsed.To)
+	if err != nil {
+		t.Fatalf("To %q: %v", parsed.To, err)
+	}
+	if len(to) != 2 || to[0].Name != "Ünïcödé User" || to[0].Address != "user@xn--r8jz45g.jp" || to[1].Address != "plain@example.org" {
+		t.Errorf("To = %q", parsed.To)
+	}
+
+	cc, err := mail.ParseAddressList(parsed.Cc)
+	if err != nil {
+		t.Fatalf("Cc %q: %v", parsed.Cc, err)
+	}
+	if len(cc) != 1 || cc[0].Name != "Doe, Jane" || cc[0].Address != "jane@xn--bcher-kva.de" {
+		t.Errorf("Cc = %q", parsed.Cc)
+	}
+
+	assertASCIIHeaders(t, raw)
+}
+
+func TestBuildRawMessageNonASCIILocalPart(t *testing.T) {
+	_, parsed := buildAndParse(t, &entity.OutgoingMail{
+		From:     "sender@example.com",
+		To:       []string{"用户@例子.中国"},
+		Subject:  "hello",
+		TextBody: "body",
+	})
+
+	to, err := mail.ParseAddress(parsed.To)
+	if err != nil {
+		t.Fatalf("To %q: %v", parsed.To, err)
+	}
+	if to.Address != "用户@xn--fsqu00a.xn--fiqs8s" {
+		t.Errorf("To = %q", to.Address)
+	}
+}
+
+func TestBuildRawMessageRejectsInvalidAddress(t *testing.T) {
+	_, err := BuildRawMessage(&entity.OutgoingMail{
+		From: "sender@example.com",
+		To:   []string{"not an address"},
+	})
+	if err == nil {
+		t.Fatal("expected an error for an invalid recipient")
+	}
+}
+
+func TestBuildRawMessageQuotedPrintableBody(t *testing.T) {
+	long := strings.Repeat("0123456789", 20)
+	text := "a=b, c==d, trailing=\n" + long + "\n日本語の本文です。" + strings.Repeat("長い行", 40) + "\nend  \n"
+	html := "<p style=\"color:red\">" + long + "</p>"
+
+	raw, parsed := buildAndParse(t, &entity.OutgoingMail{
+		From:     "sender@example.com",
+		To:       []string{"user@example.com"},
+		Subject:  "qp",
+		TextBody: text,
+		HTMLBody: html,
+	})
+
+	if got := normalizeNewlines(parsed.Body); got != normalizeNewlines(text) {
+		t.Errorf("text body round trip:\n got %q\nwant %q", got, text)
+	}
+	if got := normalizeNewlines(parsed.HTMLBody); got != html {
+		t.Errorf("html body round trip:\n got %q\nwant %q", got, html)
+	}
+	for _, line := range strings.Split(string(raw), "\r\n") {
+		if len(line) > 998 {
+			t.Errorf("line exceeds 998 octets: %d", len(line))
+		}
+	}
+	if !bytes.Contains(raw, []byte("a=3Db")) {
+		t.Error("expected '=' to be encoded as =3D")
+	}
+}
+
+func TestBuildRawMessageFoldsLongSubject(t *testing.T) {
+	for _, subject := range []string{
+		strings.Repeat("A long ASCII subject word ", 12) + "end",
+		strings.Repeat("Spaced  out\tsubject   words ", 8) + "end",
+		strings.Repeat("とても長い日本語の件名です。", 10),
+	} {
+		raw, parsed := buildAndParse(t, &entity.OutgoingMail{
+			From:     "sender@example.com",
+			To:       []string{"user@example.com"},
+			Subject:  subject,
+			TextBody: "body",
+		})
+
+		if parsed.Subject != subject {
+			t.Errorf("Subject round trip:\n got %q\nwant %q", parsed.Subject, subject)
+		}
+		header, _, _ := bytes.Cut(raw, []byte("\r\n\r\n"))
+		for _, line := range strings.Split(string(header), "\r\n") {
+			if len(line) > maxLineLength {
+				t.Errorf("header line longer than %d: %q", maxLineLength, line)
+			}
+		}
+		assertASCIIHeaders(t, raw)
+	}
+}
+
+func TestBuildRawMessageAttachments(t *testing.T) {
+	content := bytes.Repeat([]byte{0x00, 0xff, 0x10, 'a'}, 100)
+	raw, parsed := buildAndParse(t, &entity.OutgoingMail{
+		From:     "sender@example.com",
+		To:       []string{"user@example.com"},
+		Subject:  "attachments",
+		TextBody: "see attached",
+		HTMLBody: "<p>see attached</p>",
+		Attachments: []entity.Attachment{
+			{Filename: "請求書 2024年.pdf", ContentType: "application/pdf", Content: content},
+			{Filename: "résumé.txt", Content: []byte("hello")},
+		},
+	})
+
+	if len(parsed.Attachments) != 2 {
+		t.Fatalf("attachments = %d, want 2", len(parsed.Attachments))
+	}
+	first, second := parsed.Attachments[0], parsed.Attachments[1]
+	if first.Filename != "請求書 2024年.pdf" || first.ContentType != "application/pdf" || !bytes.Equal(first.Content, content) {
+		t.Errorf("first attachment = %q %q (%d bytes)", first.Filename, first.ContentType, len(first.Content))
+	}
+	if second.Filename != "résumé.txt" || string(second.Content) != "hello" {
+		t.Errorf("second attachment = %q %q", second.Filename, second.Content)
+	}
+	if normalizeNewlines(parsed.Body) != "see attached" || normalizeNewlines(parsed.HTMLBody) != "<p>see attached</p>" {
+		t.Errorf("bodies = %q / %q", parsed.Body, parsed.HTMLBody)
+	}
+	assertASCIIHeaders(t, raw)
+}
+
+func TestBuildRawMessageDateAndMessageID(t *testing.T) {
+	_, parsed := buildAndParse(t, &entity.OutgoingMail{
+		From:     "sender@例え.jp",
+		To:       []string{"user@example.com"},
+		Subject:  "ids",
+		TextBody: "body",
+	})
+	if !strings.HasPrefix(parsed.MessageID, "<") || !strings.HasSuffix(parsed.MessageID, "@xn--r8jz45g.jp>") {
+		t.Errorf("generated Message-ID = %q", parsed.MessageID)
+	}
+
+	raw, parsed := buildAndParse(t, &entity.OutgoingMail{
+		From:           "sender@example.com",
+		To:             []string{"user@example.com"},
+		MessageID:      "<fixed@example.com>",
+		InReplyTo:      "<parent@example.com>",
+		References:     []string{"<root@example.com>", "<parent@example.com>"},
+		ManagementCode: "code-1",
+		Subject:        "ids",
+		TextBody:       "body",
+	})
+	if parsed.MessageID != "<fixed@example.com>" || parsed.InReplyTo != "<parent@example.com>" {
+		t.Errorf("Message-ID = %q, In-Reply-To = %q", parsed.MessageID, parsed.InReplyTo)
+	}
+	if len(parsed.References) != 2 || parsed.ManagementCode != "code-1" {
+		t.Errorf("References = %q, management code = %q", parsed.References, parsed.ManagementCode)
+	}
+
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := msg.Header.Date(); err != nil {
+		t.Errorf("Date header: %v", err)
+	}
+}
+
+func TestBuildRawMessagePreventsHeaderInjection(t *testing.T) {
+	raw, _ := buildAndParse(t, &entity.OutgoingMail{
+		From:     "sender@example.com",
+		To:       []string{"user@example.com"},
+		Subject:  "hello\r\nBcc: victim@example.com",
+		TextBody: "body",
+	})
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bcc := msg.Header.Get("Bcc"); bcc != "" {
+		t.Errorf("injected Bcc header: %q", bcc)
+	}
+}
+
+func assertASCIIHeaders(t *testing.T, raw []byte) {
+	t.Helper()
+	header, _, _ := bytes.Cut(raw, []byte("\r\n\r\n"))
+	for _, b := range header {
+		if b >= 0x80 {
+			t.Errorf("header contains non-ASCII bytes:\n%s", header)
+			return
+		}
+	}
+}
+
+func normalizeNewlines(s string) string {
+	return strings.TrimRight(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+}
@@ -3,6 +3,7 @@ package mime
 import (
 	"bytes"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"io"
 	"mime"
@@ -15,6 +16,10 @@ import (
 	"github.com/rikut0904/mailer-backend/internal/domain/entity"
 )
 
+// ErrAttachmentNotFound is returned by WriteAttachment when the message has
+// no attachment at the index.
+var ErrAttachmentNotFound = errors.New("attachment not found")
+
 func Parse(raw []byte, s3Key string) (*entity.ParsedMail, error) {
 	msg, err := mail.ReadMessage(bytes.NewReader(raw))
 	if err != nil {
@@ -26,9 +31,19 @@ func Parse(raw []byte, s3Key string) (*entity.ParsedMail, error) {
 		MessageID: msg.Header.Get("Message-ID"),
 		From:      decodeHeader(msg.Header.Get("From")),
 		To:        decodeHeader(msg.Header.Get("To")),
+		Cc:        decodeHeader(msg.Header.Get("Cc")),
 		Subject:   decodeHeader(msg.Header.Get("Subject")),
 	}
 
+	if ids := ParseMessageIDs(msg.Header.Get("In-Reply-To")); len(ids) > 0 {
+		parsed.InReplyTo = ids[0]
+	}
+	parsed.References = ParseMessageIDs(msg.Header.Get("References"))
+	parsed.ManagementCode = strings.TrimSpace(msg.Header.Get(entity.ManagementCodeHeader))
+	for _, name := range []string{"Delivered-To", "X-Original-To"} {
+		parsed.DeliveredTo = append(parsed.DeliveredTo, msg.Header[name]...)
+	}
+
 	if dateStr := msg.Header.Get("Date"); dateStr != "" {
 		if t, err := mail.ParseDate(dateStr); err == nil {
 			parsed.Date = t
@@ -133,15 +148,84 @@ func readBody(r io.Reader, encoding string) (string, error) {
 }
 
 func readBodyBytes(r io.Reader, encoding string) ([]byte, error) {
+	return io.ReadAll(decodeBody(r, encoding))
+}
+
+func decodeBody(r io.Reader, encoding string) io.Reader {
 	switch strings.ToLower(encoding) {
 	case "base64":
-		decoder := base64.NewDecoder(base64.StdEncoding, r)
-		return io.ReadAll(decoder)
+		return base64.NewDecoder(base64.StdEncoding, r)
 	case "quoted-printable":
-		decoder := quotedprintable.NewReader(r)
-		return io.ReadAll(decoder)
+		return quotedprintable.NewReader(r)
 	default:
-		return io.ReadAll(r)
+		return r
+	}
+}
+
+// WriteAttachment decodes the attachment at index, counted as Parse counts
+// them, from the message read from r into w without holding the message or
+// the attachment in memory. The returned Attachment has no Content.
+func WriteAttachment(r io.Reader, index int, w io.Writer) (*entity.Attachment, error) {
+	msg, err := mail.ReadMessage(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read message: %w", err)
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || !strings.HasPrefix(mediaType, "multipart/") {
+		return nil, ErrAttachmentNotFound
+	}
+
+	seen := 0
+	attachment, err := writeAttachment(msg.Body, params["boundary"], index, &seen, w)
+	if err != nil {
+		return nil, err
+	}
+	if attachment == nil {
+		return nil, ErrAttachmentNotFound
+	}
+	return attachment, nil
+}
+
+func writeAttachment(r io.Reader, boundary string, index int, seen *int, w io.Writer) (*entity.Attachment, error) {
+	mr := multipart.NewReader(r, boundary)
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse multipart: %w", err)
+		}
+
+		mediaType, params, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		if strings.HasPrefix(mediaType, "multipart/") {
+			attachment, err := writeAttachment(part, params["boundary"], index, seen, w)
+			if attachment != nil || err != nil {
+				return attachment, err
+			}
+			continue
+		}
+		if !strings.Contains(part.Header.Get("Content-Disposition"), "attachment") && part.FileName() == "" {
+			continue
+		}
+
+		body := decodeBody(part, part.Header.Get("Content-Transfer-Encoding"))
+		if *seen < index {
+			// Parse skips attachments that fail to decode.
+			if _, err := io.Copy(io.Discard, body); err == nil {
+				*seen++
+			}
+			continue
+		}
+		size, err := io.Copy(w, body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode attachment: %w", err)
+		}
+		return &entity.Attachment{
+			Filename:    part.FileName(),
+			ContentType: mediaType,
+			Size:        int(size),
+		}, nil
 	}
 }
 
@@ -0,0 +1,37 @@
+package mime
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/rikut0904/mailer-backend/internal/domain/entity"
+)
+
+func TestWriteAttachmentMatchesParse(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 1000)
+	raw, _ := buildAndParse(t, &entity.OutgoingMail{
+		From:     "sender@example.com",
+		To:       []string{"user@example.com"},
+		Subject:  "attachments",
+		TextBody: "body",
+		HTMLBody: "<p>body</p>",
+		Attachments: []entity.Attachment{
+			{Filename: "a.txt", Content: []byte("first")},
+			{Filename: "データ.bin", ContentType: "application/octet-stream", Content: content},
+		},
+	})
+
+	var buf bytes.Buffer
+	attachment, err := WriteAttachment(bytes.NewReader(raw), 1, &buf)
+	if err != nil {
+		t.Fatalf("WriteAttachment: %v", err)
+	}
+	if attachment.Filename != "データ.bin" || attachment.Size != len(content) || !bytes.Equal(buf.Bytes(), content) {
+		t.Errorf("attachment = %q (%d bytes)", attachment.Filename, attachment.Size)
+	}
+
+	if _, err := WriteAttachment(bytes.NewReader(raw), 2, io.Discard); err != ErrAttachmentNotFound {
+		t.Errorf("index out of range: err = %v", err)
+	}
+}
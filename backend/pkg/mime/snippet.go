@@ -0,0 +1,26 @@
+package mime
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	htmlStripRegex  = regexp.MustCompile(`(?is)<(script|style)[^>]*>.*?</(script|style)>|<[^>]+>`)
+	whitespaceRegex = regexp.MustCompile(`\s+`)
+)
+
+func Snippet(body, htmlBody string, maxRunes int) string {
+	text := body
+	if strings.TrimSpace(text) == "" && htmlBody != "" {
+		text = html.UnescapeString(htmlStripRegex.ReplaceAllString(htmlBody, " "))
+	}
+
+	text = strings.TrimSpace(whitespaceRegex.ReplaceAllString(text, " "))
+	runes := []rune(text)
+	if maxRunes > 0 && len(runes) > maxRunes {
+		return string(runes[:maxRunes])
+	}
+	return text
+}
@@ -0,0 +1,39 @@
+package mime
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	messageIDRegex     = regexp.MustCompile(`<[^<>\s]+>`)
+	subjectPrefixRegex = regexp.MustCompile(`(?i)^\s*(re|fwd?|aw|wg|sv|vs|tr|返信|転送)\s*(\[\d+\]|\(\d+\))?\s*[:：]\s*`)
+	subjectTagRegex    = regexp.MustCompile(`^\s*\[[^\]]*\]\s*`)
+)
+
+// ParseMessageIDs extracts the <id> tokens of an In-Reply-To or References
+// header in the order they appear. Comments and other text are ignored.
+func ParseMessageIDs(header string) []string {
+	return messageIDRegex.FindAllString(header, -1)
+}
+
+// NormalizeSubject strips reply/forward prefixes ("Re:", "Fwd:", "Re[2]:",
+// "返信:" ...) and leading list tags, returning the base subject and whether
+// any reply or forward prefix was present.
+func NormalizeSubject(subject string) (string, bool) {
+	isReply := false
+	s := subject
+	for {
+		if loc := subjectPrefixRegex.FindStringIndex(s); loc != nil {
+			s = s[loc[1]:]
+			isReply = true
+			continue
+		}
+		if loc := subjectTagRegex.FindStringIndex(s); loc != nil && loc[1] < len(s) {
+			s = s[loc[1]:]
+			continue
+		}
+		break
+	}
+	return strings.Join(strings.Fields(s), " "), isReply
+}
@@ -0,0 +1,128 @@
+"use client";
+
+import { useEffect, useState } from "react";
+import { useRouter } from "next/navigation";
+import { useAuth } from "@/hooks/useAuth";
+import { deleteDraft, getDraft, getDrafts } from "@/lib/api";
+import ComposeForm from "@/components/compose/ComposeForm";
+import type { Draft } from "@/types";
+
+export default function DraftsPage() {
+  const { user, loading: authLoading } = useAuth();
+  const router = useRouter();
+  const [drafts, setDrafts] = useState<Draft[]>([]);
+  const [editing, setEditing] = useState<Draft | null>(null);
+  const [loading, setLoading] = useState(true);
+  const [message, setMessage] = useState<string | null>(null);
+
+  const load = async () => {
+    try {
+      setLoading(true);
+      setDrafts(await getDrafts());
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  useEffect(() => {
+    if (!authLoading && !user) {
+      router.push("/login");
+      return;
+    }
+
+    if (!authLoading && user) {
+      load();
+    }
+  }, [authLoading, user, router]);
+
+  const handleOpen = async (id: string) => {
+    setMessage(null);
+    try {
+      setEditing(await getDraft(id));
+    } catch (err) {
+      setMessage(err instanceof Error ? err.message : "下書きの読み込みに失敗しました");
+    }
+  };
+
+  const handleDelete = async (id: string) => {
+    if (!confirm("この下書きを削除しますか？")) return;
+    setMessage(null);
+    try {
+      await deleteDraft(id);
+      setDrafts((prev) => prev.filter((d) => d.id !== id));
+      setMessage("下書きを削除しました");
+    } catch (err) {
+      setMessage(err instanceof Error ? err.message : "削除に失敗しました");
+    }
+  };
+
+  if (authLoading || !user || loading) {
+    return (
+      <div className="flex items-center justify-center min-h-screen">
+        <div className="animate-spin rounded-full h-8 w-8 border-b-2 border-[var(--primary-color)]" />
+      </div>
+    );
+  }
+
+  return (
+    <div className="min-h-screen bg-[var(--background)] p-4">
+      <div className="max-w-3xl mx-auto bg-[var(--card-background)] rounded-xl shadow p-6 border border-[var(--card-border)]">
+        <div className="flex items-center justify-between mb-6">
+          <h1 className="text-xl font-bold text-[var(--text-heading)]">下書き</h1>
+          <button
+            onClick={() => router.push("/mail")}
+            className="text-[var(--text-body)] hover:opacity-80"
+          >
+            ← 戻る
+          </button>
+        </div>
+
+        {message && <p className="text-sm text-[var(--text-body)] mb-3">{message}</p>}
+
+        <div className="space-y-2">
+          {drafts.map((d) => (
+            <div
+              key={d.id}
+              className="flex items-center justify-between gap-2 px-3 py-2 border border-[var(--card-border)] rounded-lg"
+            >
+              <button onClick={() => handleOpen(d.id)} className="text-left min-w-0 flex-1">
+                <p className="text-sm font-medium text-[var(--text-heading)] truncate">
+                  {d.subject || "（件名なし）"}
+                </p>
+                <p className="text-xs text-[var(--text-body)] truncate">
+                  {[...(d.to ?? []), ...(d.cc ?? []), ...(d.bcc ?? [])].join(", ") || "宛先未入力"}
+                  {" ・ "}
+                  {new Date(d.updated_at).toLocaleString()}
+                </p>
+              </button>
+              <button
+                onClick={() => handleDelete(d.id)}
+                className="text-sm text-red-600 hover:opacity-80"
+              >
+                削除
+              </button>
+            </div>
+          ))}
+          {drafts.length === 0 && (
+            <p className="text-sm text-[var(--text-body)]">下書きはありません</p>
+          )}
+        </div>
+      </div>
+
+      {editing && (
+        <ComposeForm
+          draft={editing}
+          onClose={() => {
+            setEditing(null);
+            load();
+          }}
+          onSent={() => {
+            setEditing(null);
+            setMessage("下書きを送信しました");
+            load();
+          }}
+        />
+      )}
+    </div>
+  );
+}
@@ -10,8 +10,15 @@ import MailList from "@/components/mail/MailList";
 import MailDetail from "@/components/mail/MailDetail";
 import ComposeForm from "@/components/compose/ComposeForm";
 import ThreadTimeline from "@/components/thread/ThreadTimeline";
-import type { ParsedMail } from "@/types";
-import { getDomains, getRecipients, getUserSettings, updateUserSettings } from "@/lib/api";
+import type { ParsedMail, SendResponse } from "@/types";
+import {
+  cancelSend,
+  getDomains,
+  getMail,
+  getRecipients,
+  getUserSettings,
+  updateUserSettings,
+} from "@/lib/api";
 
 export default function MailPage() {
   const { user, loading: authLoading, signOut } = useAuth();
@@ -23,6 +30,7 @@ export default function MailPage() {
   const [domains, setDomains] = useState<{ id: string; name: string }[]>([]);
   const [selectedDomainId, setSelectedDomainId] = useState("");
   const [recipients, setRecipients] = useState<string[]>([]);
+  const [pendingSend, setPendingSend] = useState<SendResponse | null>(null);
 
   // Compose state for reply/forward
   const [composeProps, setComposeProps] = useState<{
@@ -31,7 +39,10 @@ export default function MailPage() {
     initialBody?: string;
     threadId?: string;
     replyCode?: string;
+    inReplyTo?: string;
+    references?: string[];
     sendType: "new" | "reply" | "forward";
+    forwardS3Key?: string;
   }>({ sendType: "new" });
 
   const {
@@ -65,10 +76,7 @@ export default function MailPage() {
           const domainId = settings.selected_domain_id || domainList[0]?.id || "";
           setSelectedDomainId(domainId);
           if (domainId && domainId !== settings.selected_domain_id) {
-            await updateUserSettings({
-              discord_webhook_url: settings.discord_webhook_url || "",
-              selected_domain_id: domainId,
-            });
+            await updateUserSettings({ ...settings, selected_domain_id: domainId });
           }
           const recipientRes = await getRecipients();
           setRecipients(recipientRes.recipients || []);
@@ -91,12 +99,20 @@ export default function MailPage() {
     );
   }
 
-  const handleSelectMail = (mail: ParsedMail) => {
+  const handleSelectMail = async (mail: ParsedMail) => {
     setSelectedMail(mail);
     setViewingThread(null);
     if (!mail.is_read) {
       markRead(mail.s3_key, true);
     }
+    try {
+      const full = await getMail(mail.s3_key);
+      setSelectedMail((prev) =>
+        prev?.s3_key === full.s3_key ? { ...full, is_read: true } : prev
+      );
+    } catch (err) {
+      console.error(err);
+    }
   };
 
   const handleReply = (mail: ParsedMail) => {
@@ -105,6 +121,8 @@ export default function MailPage() {
       initialSubject: `Re: ${mail.subject}`,
       initialBody: `\n\n---\n${new Date(mail.date).toLocaleString("ja-JP")} に ${mail.from} さんが書きました:\n${mail.body}`,
       threadId: mail.thread_id || undefined,
+      inReplyTo: mail.message_id || undefined,
+      references: mail.message_id ? [...(mail.references ?? []), mail.message_id] : undefined,
       sendType: "reply",
     });
     setShowCompose(true);
@@ -116,6 +134,7 @@ export default function MailPage() {
       initialBody: `\n\n---\n転送メッセージ:\n差出人: ${mail.from}\n日時: ${new Date(mail.date).toLocaleString("ja-JP")}\n件名: ${mail.subject}\n\n${mail.body}`,
       threadId: mail.thread_id || undefined,
       sendType: "forward",
+      forwardS3Key: mail.attachments?.length ? mail.s3_key : undefined,
     });
     setShowCompose(true);
   };
@@ -125,6 +144,26 @@ export default function MailPage() {
     setShowCompose(true);
   };
 
+  // Hide the undo banner once the send has been released
+  useEffect(() => {
+    if (!pendingSend) return;
+    const remaining = new Date(pendingSend.send_at).getTime() - Date.now();
+    const timer = setTimeout(() => setPendingSend(null), Math.max(remaining, 0));
+    return () => clearTimeout(timer);
+  }, [pendingSend]);
+
+  const handleUndoSend = async () => {
+    if (!pendingSend) return;
+    try {
+      await cancelSend(pendingSend.batch_id);
+      alert("送信を取り消しました");
+    } catch (err) {
+      alert(err instanceof Error ? err.message : "取り消しに失敗しました");
+    } finally {
+      setPendingSend(null);
+    }
+  };
+
   const handleDelete = async (s3Key: string) => {
     if (!confirm("このメールを削除しますか？")) return;
     await removeMail(s3Key);
@@ -166,10 +205,7 @@ export default function MailPage() {
           onDomainChange={async (domainId) => {
             setSelectedDomainId(domainId);
             const settings = await getUserSettings();
-            await updateUserSettings({
-              discord_webhook_url: settings.discord_webhook_url || "",
-              selected_domain_id: domainId,
-            });
+            await updateUserSettings({ ...settings, selected_domain_id: domainId });
             setRecipient("");
             setSelectedMail(null);
             const recipientRes = await getRecipients();
@@ -178,6 +214,9 @@ export default function MailPage() {
             await refresh();
           }}
           onCompose={handleCompose}
+          onDrafts={() => router.push("/drafts")}
+          onMerge={() => router.push("/merge")}
+          onScheduled={() => router.push("/scheduled")}
           onSettings={() => router.push("/settings")}
           onSignOut={signOut}
         />
@@ -207,12 +246,28 @@ export default function MailPage() {
         <ComposeForm
           {...composeProps}
           onClose={() => setShowCompose(false)}
-          onSent={() => {
+          onSent={(result) => {
             setShowCompose(false);
+            // Offer undo only for immediate sends held for the undo window
+            if (new Date(result.send_at).getTime() - Date.now() <= 60_000) {
+              setPendingSend(result);
+            }
             refresh();
           }}
         />
       )}
+
+      {pendingSend && (
+        <div className="fixed bottom-4 left-1/2 -translate-x-1/2 z-50 flex items-center gap-4 px-4 py-3 rounded-lg shadow-lg bg-[var(--card-background)] border border-[var(--card-border)]">
+          <span className="text-sm text-[var(--text-body)]">送信しました</span>
+          <button
+            onClick={handleUndoSend}
+            className="text-sm font-medium text-[var(--text-heading)] hover:opacity-80"
+          >
+            取り消す
+          </button>
+        </div>
+      )}
     </>
   );
 }
@@ -0,0 +1,212 @@
+"use client";
+
+import { useEffect, useState } from "react";
+import { useRouter } from "next/navigation";
+import { useAuth } from "@/hooks/useAuth";
+import { createMerge, downloadMergeReport, getSendStatus, getTemplates } from "@/lib/api";
+import type { MailTemplate, SendStatus } from "@/types";
+
+const inputClass =
+  "w-full px-3 py-2 border border-[var(--input-border)] bg-[var(--input-background)] rounded-lg";
+
+export default function MailMergePage() {
+  const { user, loading: authLoading } = useAuth();
+  const router = useRouter();
+  const [templates, setTemplates] = useState<MailTemplate[]>([]);
+  const [templateId, setTemplateId] = useState("");
+  const [fromAddress, setFromAddress] = useState("");
+  const [subject, setSubject] = useState("");
+  const [body, setBody] = useState("");
+  const [csv, setCsv] = useState<File | null>(null);
+  const [files, setFiles] = useState<File[]>([]);
+  const [sending, setSending] = useState(false);
+  const [batchId, setBatchId] = useState<string | null>(null);
+  const [status, setStatus] = useState<SendStatus | null>(null);
+  const [message, setMessage] = useState<string | null>(null);
+
+  useEffect(() => {
+    if (!authLoading && !user) {
+      router.push("/login");
+      return;
+    }
+
+    if (!authLoading && user) {
+      getTemplates()
+        .then(setTemplates)
+        .catch(() => setTemplates([]));
+    }
+  }, [authLoading, user, router]);
+
+  // Poll until every row has a final state.
+  useEffect(() => {
+    if (!batchId) return;
+
+    let timer: ReturnType<typeof setTimeout>;
+    const poll = async () => {
+      try {
+        const current = await getSendStatus(batchId);
+        setStatus(current);
+        const done =
+          current.status === "canceled" ||
+          (current.status === "released" &&
+            current.recipients.every((r) => r.status === "sent" || r.status === "failed"));
+        if (done) return;
+      } catch (err) {
+        setMessage(err instanceof Error ? err.message : "状態の取得に失敗しました");
+      }
+      timer = setTimeout(poll, 5000);
+    };
+    poll();
+    return () => clearTimeout(timer);
+  }, [batchId]);
+
+  const handleSubmit = async (e: React.FormEvent) => {
+    e.preventDefault();
+    if (!csv) return;
+    setMessage(null);
+    setSending(true);
+    try {
+      const result = await createMerge(
+        templateId
+          ? { template_id: templateId, from_address: fromAddress }
+          : { subject, body, from_address: fromAddress },
+        csv,
+        files
+      );
+      setStatus(null);
+      setBatchId(result.batch_id);
+      setMessage("差し込み送信を開始しました");
+    } catch (err) {
+      setMessage(err instanceof Error ? err.message : "送信に失敗しました");
+    } finally {
+      setSending(false);
+    }
+  };
+
+  if (authLoading || !user) {
+    return (
+      <div className="flex items-center justify-center min-h-screen">
+        <div className="animate-spin rounded-full h-8 w-8 border-b-2 border-[var(--primary-color)]" />
+      </div>
+    );
+  }
+
+  const count = (state: string) =>
+    status?.recipients.filter((r) => r.status === state).length ?? 0;
+
+  return (
+    <div className="min-h-screen bg-[var(--background)] p-4">
+      <div className="max-w-3xl mx-auto bg-[var(--card-background)] rounded-xl shadow p-6 border border-[var(--card-border)]">
+        <div className="flex items-center justify-between mb-6">
+          <h1 className="text-xl font-bold text-[var(--text-heading)]">差し込み送信</h1>
+          <button
+            onClick={() => router.push("/mail")}
+            className="text-[var(--text-body)] hover:opacity-80"
+          >
+            ← 戻る
+          </button>
+        </div>
+
+        <p className="text-sm text-[var(--text-body)] mb-4">
+          1 行目を見出し行とし、<code>email</code> 列を含む CSV をアップロードしてください。各列は{" "}
+          <code>{"{{.列名}}"}</code> で件名・本文に差し込めます。
+        </p>
+
+        <form onSubmit={handleSubmit} className="space-y-3 mb-6">
+          <input
+            type="email"
+            value={fromAddress}
+            onChange={(e) => setFromAddress(e.target.value)}
+            placeholder="差出人"
+            className={inputClass}
+            required
+          />
+          <select
+            value={templateId}
+            onChange={(e) => setTemplateId(e.target.value)}
+            className={inputClass}
+          >
+            <option value="">テンプレートを使わない</option>
+            {templates.map((t) => (
+              <option key={t.id} value={t.id}>
+                {t.name}
+              </option>
+            ))}
+          </select>
+          {!templateId && (
+            <>
+              <input
+                type="text"
+                value={subject}
+                onChange={(e) => setSubject(e.target.value)}
+                placeholder="件名"
+                className={inputClass}
+                required
+              />
+              <textarea
+                value={body}
+                onChange={(e) => setBody(e.target.value)}
+                placeholder="本文"
+                rows={8}
+                className={`${inputClass} font-mono text-sm`}
+                required
+              />
+            </>
+          )}
+          <label className="block text-sm text-[var(--text-body)]">
+            宛先 CSV
+            <input
+              type="file"
+              accept=".csv,text/csv"
+              onChange={(e) => setCsv(e.target.files?.[0] ?? null)}
+              className="block mt-1"
+              required
+            />
+          </label>
+          <label className="block text-sm text-[var(--text-body)]">
+            添付ファイル（全員共通）
+            <input
+              type="file"
+              multiple
+              onChange={(e) => setFiles(Array.from(e.target.files ?? []))}
+              className="block mt-1"
+            />
+          </label>
+          <button
+            type="submit"
+            disabled={sending || !csv}
+            className="px-4 py-2 bg-[var(--primary-color)] text-[var(--text-heading)] rounded-lg hover:opacity-90 border border-[var(--card-border)] disabled:opacity-50"
+          >
+            {sending ? "送信中..." : "送信"}
+          </button>
+        </form>
+
+        {message && <p className="text-sm text-[var(--text-body)] mb-3">{message}</p>}
+
+        {batchId && status && (
+          <div className="p-4 border border-[var(--card-border)] rounded-lg space-y-2">
+            <p className="text-sm text-[var(--text-heading)]">
+              {status.status === "scheduled"
+                ? `${new Date(status.send_at).toLocaleString("ja-JP")} に送信開始`
+                : status.status === "canceled"
+                  ? "取り消されました"
+                  : `送信済み ${count("sent")} / 失敗 ${count("failed")} / 待機中 ${
+                      count("queued") + count("sending")
+                    }`}
+            </p>
+            <button
+              onClick={() =>
+                downloadMergeReport(batchId).catch((err) =>
+                  setMessage(err instanceof Error ? err.message : "ダウンロードに失敗しました")
+                )
+              }
+              className="text-sm text-[var(--text-body)] hover:opacity-80 underline"
+            >
+              結果レポート (CSV) をダウンロード
+            </button>
+          </div>
+        )}
+      </div>
+    </div>
+  );
+}
@@ -0,0 +1,219 @@
+"use client";
+
+import { useEffect, useState } from "react";
+import { useRouter } from "next/navigation";
+import { useAuth } from "@/hooks/useAuth";
+import {
+  cancelSend,
+  getScheduledSends,
+  rescheduleSend,
+  updateScheduledSend,
+} from "@/lib/api";
+import type { ScheduledSend } from "@/types";
+
+// datetime-local inputs take local time without a zone suffix
+function toLocalInput(iso: string): string {
+  const d = new Date(iso);
+  const offset = d.getTimezoneOffset() * 60_000;
+  return new Date(d.getTime() - offset).toISOString().slice(0, 16);
+}
+
+export default function ScheduledSendsPage() {
+  const { user, loading: authLoading } = useAuth();
+  const router = useRouter();
+  const [sends, setSends] = useState<ScheduledSend[]>([]);
+  const [editing, setEditing] = useState<ScheduledSend | null>(null);
+  const [loading, setLoading] = useState(true);
+  const [message, setMessage] = useState<string | null>(null);
+
+  const load = async () => {
+    try {
+      setLoading(true);
+      setSends(await getScheduledSends());
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  useEffect(() => {
+    if (!authLoading && !user) {
+      router.push("/login");
+      return;
+    }
+
+    if (!authLoading && user) {
+      load();
+    }
+  }, [authLoading, user, router]);
+
+  const handleCancel = async (id: string) => {
+    if (!confirm("この予約送信を取り消しますか？")) return;
+    setMessage(null);
+    try {
+      await cancelSend(id);
+      setSends((prev) => prev.filter((s) => s.id !== id));
+      setMessage("予約送信を取り消しました");
+    } catch (err) {
+      setMessage(err instanceof Error ? err.message : "取り消しに失敗しました");
+    }
+  };
+
+  const handleReschedule = async (id: string, value: string) => {
+    if (!value) return;
+    setMessage(null);
+    try {
+      await rescheduleSend(id, new Date(value).toISOString());
+      await load();
+      setMessage("送信日時を変更しました");
+    } catch (err) {
+      setMessage(err instanceof Error ? err.message : "変更に失敗しました");
+    }
+  };
+
+  const handleSaveEdit = async (e: React.FormEvent) => {
+    e.preventDefault();
+    if (!editing) return;
+    setMessage(null);
+    try {
+      await updateScheduledSend(editing.id, {
+        to: editing.to,
+        cc: editing.cc,
+        bcc: editing.bcc,
+        subject: editing.subject,
+        body: editing.body,
+        html_body: editing.html_body,
+        send_type: editing.send_type,
+        from_address: editing.from_address,
+        send_at: editing.send_at,
+        in_reply_to: editing.in_reply_to,
+        references: editing.references,
+        reply_code: editing.reply_code,
+      });
+      setEditing(null);
+      await load();
+      setMessage("予約送信を更新しました");
+    } catch (err) {
+      setMessage(err instanceof Error ? err.message : "更新に失敗しました");
+    }
+  };
+
+  if (authLoading || !user || loading) {
+    return (
+      <div className="flex items-center justify-center min-h-screen">
+        <div className="animate-spin rounded-full h-8 w-8 border-b-2 border-[var(--primary-color)]" />
+      </div>
+    );
+  }
+
+  return (
+    <div className="min-h-screen bg-[var(--background)] p-4">
+      <div className="max-w-3xl mx-auto bg-[var(--card-background)] rounded-xl shadow p-6 border border-[var(--card-border)]">
+        <div className="flex items-center justify-between mb-6">
+          <h1 className="text-xl font-bold text-[var(--text-heading)]">予約送信</h1>
+          <button
+            onClick={() => router.push("/mail")}
+            className="text-[var(--text-body)] hover:opacity-80"
+          >
+            ← 戻る
+          </button>
+        </div>
+
+        {message && <p className="text-sm text-[var(--text-body)] mb-3">{message}</p>}
+
+        <div className="space-y-3">
+          {sends.map((s) =>
+            editing?.id === s.id ? (
+              <form
+                key={s.id}
+                onSubmit={handleSaveEdit}
+                className="space-y-2 px-3 py-3 border border-[var(--card-border)] rounded-lg"
+              >
+                <input
+                  type="text"
+                  value={editing.to.join(", ")}
+                  onChange={(e) =>
+                    setEditing({
+                      ...editing,
+                      to: e.target.value.split(",").map((r) => r.trim()).filter(Boolean),
+                    })
+                  }
+                  className="w-full px-3 py-2 border border-[var(--input-border)] bg-[var(--input-background)] rounded-lg"
+                />
+                <input
+                  type="text"
+                  value={editing.subject}
+                  onChange={(e) => setEditing({ ...editing, subject: e.target.value })}
+                  className="w-full px-3 py-2 border border-[var(--input-border)] bg-[var(--input-background)] rounded-lg"
+                  required
+                />
+                <textarea
+                  value={editing.body}
+                  onChange={(e) => setEditing({ ...editing, body: e.target.value })}
+                  rows={8}
+                  className="w-full px-3 py-2 border border-[var(--input-border)] bg-[var(--input-background)] rounded-lg resize-y"
+                  required
+                />
+                <div className="flex justify-end gap-3">
+                  <button
+                    type="button"
+                    onClick={() => setEditing(null)}
+                    className="text-sm text-[var(--text-body)] hover:opacity-80"
+                  >
+                    キャンセル
+                  </button>
+                  <button
+                    type="submit"
+                    className="px-4 py-2 text-sm bg-[var(--primary-color)] text-[var(--text-heading)] rounded-lg hover:opacity-90 border border-[var(--card-border)]"
+                  >
+                    保存
+                  </button>
+                </div>
+              </form>
+            ) : (
+              <div
+                key={s.id}
+                className="flex flex-col md:flex-row md:items-center justify-between gap-2 px-3 py-2 border border-[var(--card-border)] rounded-lg"
+              >
+                <div>
+                  <p className="text-sm font-medium text-[var(--text-heading)]">{s.subject}</p>
+                  <p className="text-xs text-[var(--text-body)]">
+                    {[...s.to, ...(s.cc ?? []), ...(s.bcc ?? [])].join(", ")}
+                  </p>
+                </div>
+                <div className="flex items-center gap-3">
+                  <input
+                    type="datetime-local"
+                    defaultValue={toLocalInput(s.send_at)}
+                    onBlur={(e) => {
+                      if (e.target.value !== toLocalInput(s.send_at)) {
+                        handleReschedule(s.id, e.target.value);
+                      }
+                    }}
+                    className="px-2 py-1 text-sm border border-[var(--input-border)] bg-[var(--input-background)] rounded-lg"
+                  />
+                  {s.kind !== "merge" && (
+                    <button
+                      onClick={() => setEditing(s)}
+                      className="text-sm text-[var(--text-body)] hover:opacity-80"
+                    >
+                      編集
+                    </button>
+                  )}
+                  <button
+                    onClick={() => handleCancel(s.id)}
+                    className="text-sm text-red-600 hover:opacity-80"
+                  >
+                    取消
+                  </button>
+                </div>
+              </div>
+            )
+          )}
+          {sends.length === 0 && (
+            <p className="text-sm text-[var(--text-body)]">予約中の送信はありません</p>
+          )}
+        </div>
+      </div>
+    </div>
+  );
+}
@@ -3,12 +3,72 @@
 import { useEffect, useState } from "react";
 import { useRouter } from "next/navigation";
 import { useAuth } from "@/hooks/useAuth";
-import { getUserSettings, updateUserSettings } from "@/lib/api";
+import { getRecipients, getUserSettings, updateUserSettings } from "@/lib/api";
+import type {
+  NotificationChannel,
+  NotificationChannelType,
+  NotificationRule,
+  QuietHours,
+  UnreadDigest,
+} from "@/types";
+
+const channelTypes: { value: NotificationChannelType; label: string; placeholder: string; help: string }[] = [
+  {
+    value: "discord",
+    label: "Discord",
+    placeholder: "https://discord.com/api/webhooks/...",
+    help: "チャンネル設定 → 連携サービス → ウェブフックから作成できます。",
+  },
+  {
+    value: "slack",
+    label: "Slack",
+    placeholder: "https://hooks.slack.com/services/...",
+    help: "Slack アプリの Incoming Webhooks を有効にして作成できます。",
+  },
+  {
+    value: "teams",
+    label: "Teams",
+    placeholder: "https://....webhook.office.com/... または Workflows の URL",
+    help: "チャンネルの「ワークフロー」→「Webhook 要求を受信したらチャネルに投稿する」で作成できます。",
+  },
+];
+
+const defaultQuietHours: QuietHours = {
+  enabled: false,
+  start: "22:00",
+  end: "07:00",
+  time_zone: Intl.DateTimeFormat().resolvedOptions().timeZone || "Asia/Tokyo",
+};
+
+const defaultUnreadDigest: UnreadDigest = {
+  enabled: false,
+  schedule: "0 8 * * *",
+  time_zone: defaultQuietHours.time_zone,
+  to_address: "",
+};
+
+const digestPresets = [
+  { label: "毎日 8:00", schedule: "0 8 * * *" },
+  { label: "平日 8:00", schedule: "0 8 * * 1-5" },
+  { label: "毎週月曜 8:00", schedule: "0 8 * * 1" },
+];
+
+const channelLabel = (channel: NotificationChannel, index: number) =>
+  channel.name?.trim() ||
+  `${channelTypes.find((t) => t.value === channel.type)?.label ?? channel.type} #${index + 1}`;
+
+const inputClass =
+  "px-3 py-2 border border-[var(--input-border)] bg-[var(--input-background)] rounded-lg focus:ring-2 focus:ring-[var(--primary-color)] focus:border-[var(--primary-color)] outline-none";
 
 export default function NotificationSettingsPage() {
   const { user, loading: authLoading } = useAuth();
   const router = useRouter();
-  const [webhookUrl, setWebhookUrl] = useState("");
+  const [channels, setChannels] = useState<NotificationChannel[]>([]);
+  const [rules, setRules] = useState<NotificationRule[]>([]);
+  const [quietHours, setQuietHours] = useState<QuietHours>(defaultQuietHours);
+  const [unreadDigest, setUnreadDigest] = useState<UnreadDigest>(defaultUnreadDigest);
+  const [digestSentAt, setDigestSentAt] = useState<string | null>(null);
+  const [recipients, setRecipients] = useState<string[]>([]);
   const [loading, setLoading] = useState(true);
   const [saving, setSaving] = useState(false);
   const [error, setError] = useState<string | null>(null);
@@ -25,7 +85,17 @@ export default function NotificationSettingsPage() {
         try {
           setLoading(true);
           const settings = await getUserSettings();
-          setWebhookUrl(settings.discord_webhook_url || "");
+          setChannels(settings.notification_channels ?? []);
+          setRules(settings.notification_rules ?? []);
+          if (settings.quiet_hours?.start) {
+            setQuietHours(settings.quiet_hours);
+          }
+          if (settings.unread_digest?.schedule) {
+            setUnreadDigest(settings.unread_digest);
+          }
+          setDigestSentAt(settings.unread_digest_sent_at ?? null);
+          const recipientRes = await getRecipients().catch(() => ({ recipients: [] }));
+          setRecipients(recipientRes.recipients || []);
         } catch (err) {
           setError(err instanceof Error ? err.message : "設定の取得に失敗しました");
         } finally {
@@ -35,6 +105,54 @@ export default function NotificationSettingsPage() {
     }
   }, [authLoading, user, router]);
 
+  const updateChannel = (index: number, patch: Partial<NotificationChannel>) => {
+    setChannels((prev) => prev.map((c, i) => (i === index ? { ...c, ...patch } : c)));
+    setSaved(false);
+  };
+
+  const addChannel = () => {
+    setChannels((prev) => [
+      ...prev,
+      { id: crypto.randomUUID(), name: "", type: "discord", webhook_url: "", recipient_address: "" },
+    ]);
+    setSaved(false);
+  };
+
+  const removeChannel = (index: number) => {
+    const removed = channels[index];
+    setChannels((prev) => prev.filter((_, i) => i !== index));
+    setRules((prev) => prev.filter((r) => r.channel_id !== removed.id));
+    setSaved(false);
+  };
+
+  const updateRule = (index: number, patch: Partial<NotificationRule>) => {
+    setRules((prev) => prev.map((r, i) => (i === index ? { ...r, ...patch } : r)));
+    setSaved(false);
+  };
+
+  const addRule = () => {
+    setRules((prev) => [
+      ...prev,
+      { id: crypto.randomUUID(), name: "", channel_id: channels[0]?.id ?? "", subject_keywords: [] },
+    ]);
+    setSaved(false);
+  };
+
+  const removeRule = (index: number) => {
+    setRules((prev) => prev.filter((_, i) => i !== index));
+    setSaved(false);
+  };
+
+  const updateQuietHours = (patch: Partial<QuietHours>) => {
+    setQuietHours((prev) => ({ ...prev, ...patch }));
+    setSaved(false);
+  };
+
+  const updateUnreadDigest = (patch: Partial<UnreadDigest>) => {
+    setUnreadDigest((prev) => ({ ...prev, ...patch }));
+    setSaved(false);
+  };
+
   const handleSave = async (e: React.FormEvent) => {
     e.preventDefault();
     setError(null);
@@ -42,10 +160,21 @@ export default function NotificationSettingsPage() {
     try {
       setSaving(true);
       const current = await getUserSettings();
-      await updateUserSettings({
-        discord_webhook_url: webhookUrl.trim(),
+      const updated = await updateUserSettings({
+        notification_channels: channels
+          .map((c) => ({
+            ...c,
+            webhook_url: c.webhook_url.trim(),
+            recipient_address: (c.recipient_address ?? "").trim(),
+          }))
+          .filter((c) => c.webhook_url),
+        notification_rules: rules,
+        quiet_hours: quietHours,
+        unread_digest: unreadDigest,
         selected_domain_id: current.selected_domain_id || "",
       });
+      setChannels(updated.notification_channels ?? []);
+      setRules(updated.notification_rules ?? []);
       setSaved(true);
     } catch (err) {
       setError(err instanceof Error ? err.message : "保存に失敗しました");
@@ -75,21 +204,278 @@ export default function NotificationSettingsPage() {
           </button>
         </div>
 
+        <p className="text-sm text-[var(--text-body)] mb-4">
+          選択中のドメインに届いたメールを通知します。宛先アドレスを指定すると、そのアドレス宛のメールだけを通知します。
+        </p>
+
         <form onSubmit={handleSave} className="space-y-4">
-          <div>
-            <label className="block text-sm font-medium text-[var(--text-body)] mb-1">
-              Discord Webhook URL
+          <datalist id="notification-recipients">
+            {recipients.map((r) => (
+              <option key={r} value={r} />
+            ))}
+          </datalist>
+
+          {channels.map((channel, index) => {
+            const type = channelTypes.find((t) => t.value === channel.type) ?? channelTypes[0];
+            return (
+              <div
+                key={index}
+                className="space-y-2 p-3 border border-[var(--card-border)] rounded-lg"
+              >
+                <input
+                  type="text"
+                  value={channel.name ?? ""}
+                  onChange={(e) => updateChannel(index, { name: e.target.value })}
+                  placeholder={`名前（例: ${channelLabel(channel, index)}）`}
+                  className={`w-full ${inputClass}`}
+                />
+                <div className="flex gap-2">
+                  <select
+                    value={channel.type}
+                    onChange={(e) =>
+                      updateChannel(index, { type: e.target.value as NotificationChannelType })
+                    }
+                    className={inputClass}
+                  >
+                    {channelTypes.map((t) => (
+                      <option key={t.value} value={t.value}>
+                        {t.label}
+                      </option>
+                    ))}
+                  </select>
+                  <input
+                    type="text"
+                    value={channel.recipient_address ?? ""}
+                    onChange={(e) => updateChannel(index, { recipient_address: e.target.value })}
+                    placeholder="宛先アドレス（空ならすべて）"
+                    list="notification-recipients"
+                    className={`flex-1 ${inputClass}`}
+                  />
+                  <button
+                    type="button"
+                    onClick={() => removeChannel(index)}
+                    className="text-sm text-red-600 hover:opacity-80"
+                  >
+                    削除
+                  </button>
+                </div>
+                <input
+                  type="text"
+                  value={channel.webhook_url}
+                  onChange={(e) => updateChannel(index, { webhook_url: e.target.value })}
+                  placeholder={type.placeholder}
+                  className={`w-full ${inputClass}`}
+                />
+                <p className="text-xs text-[var(--text-body)]">{type.help}</p>
+              </div>
+            );
+          })}
+
+          {channels.length === 0 && (
+            <p className="text-sm text-[var(--text-body)]">通知先は未設定です</p>
+          )}
+
+          <button
+            type="button"
+            onClick={addChannel}
+            className="text-sm text-[var(--text-body)] hover:opacity-80"
+          >
+            ＋ 通知先を追加
+          </button>
+
+          <div className="pt-4 border-t border-[var(--card-border)] space-y-3">
+            <h2 className="font-bold text-[var(--text-heading)]">通知ルール</h2>
+            <p className="text-sm text-[var(--text-body)]">
+              設定した条件をすべて満たすメールを指定の通知先へ送ります。ルールの対象になった通知先には、いずれかのルールに一致したメールだけが届きます。メンションには
+              Discord のロール ID、Slack のユーザー／グループ ID（here・channel も可）、Teams のユーザー（UPN）を指定します。
+            </p>
+
+            {rules.map((rule, index) => (
+              <div
+                key={rule.id}
+                className="space-y-2 p-3 border border-[var(--card-border)] rounded-lg"
+              >
+                <div className="flex gap-2">
+                  <input
+                    type="text"
+                    value={rule.name ?? ""}
+                    onChange={(e) => updateRule(index, { name: e.target.value })}
+                    placeholder="ルール名"
+                    className={`flex-1 ${inputClass}`}
+                  />
+                  <select
+                    value={rule.channel_id}
+                    onChange={(e) => updateRule(index, { channel_id: e.target.value })}
+                    className={inputClass}
+                  >
+                    {channels.map((c, i) => (
+                      <option key={c.id} value={c.id}>
+                        {channelLabel(c, i)}
+                      </option>
+                    ))}
+                  </select>
+                  <button
+                    type="button"
+                    onClick={() => removeRule(index)}
+                    className="text-sm text-red-600 hover:opacity-80"
+                  >
+                    削除
+                  </button>
+                </div>
+                <div className="flex gap-2">
+                  <input
+                    type="text"
+                    value={rule.recipient_address ?? ""}
+                    onChange={(e) => updateRule(index, { recipient_address: e.target.value })}
+                    placeholder="宛先アドレス"
+                    list="notification-recipients"
+                    className={`flex-1 ${inputClass}`}
+                  />
+                  <input
+                    type="text"
+                    value={rule.sender_pattern ?? ""}
+                    onChange={(e) => updateRule(index, { sender_pattern: e.target.value })}
+                    placeholder="送信者（例: *@example.com）"
+                    className={`flex-1 ${inputClass}`}
+                  />
+                </div>
+                <input
+                  type="text"
+                  value={(rule.subject_keywords ?? []).join(", ")}
+                  onChange={(e) => updateRule(index, { subject_keywords: e.target.value.split(",") })}
+                  placeholder="件名キーワード（カンマ区切り、いずれかを含む）"
+                  className={`w-full ${inputClass}`}
+                />
+                <div className="flex gap-2">
+                  <select
+                    value={rule.has_attachment == null ? "" : String(rule.has_attachment)}
+                    onChange={(e) =>
+                      updateRule(index, {
+                        has_attachment: e.target.value === "" ? null : e.target.value === "true",
+                      })
+                    }
+                    className={inputClass}
+                  >
+                    <option value="">添付ファイル: 問わない</option>
+                    <option value="true">添付ファイルあり</option>
+                    <option value="false">添付ファイルなし</option>
+                  </select>
+                  <input
+                    type="text"
+                    value={rule.mention ?? ""}
+                    onChange={(e) => updateRule(index, { mention: e.target.value })}
+                    placeholder="メンション（任意）"
+                    className={`flex-1 ${inputClass}`}
+                  />
+                </div>
+              </div>
+            ))}
+
+            {rules.length === 0 && (
+              <p className="text-sm text-[var(--text-body)]">ルールは未設定です</p>
+            )}
+
+            <button
+              type="button"
+              onClick={addRule}
+              disabled={channels.length === 0}
+              className="text-sm text-[var(--text-body)] hover:opacity-80 disabled:opacity-50"
+            >
+              ＋ ルールを追加
+            </button>
+          </div>
+
+          <div className="pt-4 border-t border-[var(--card-border)] space-y-3">
+            <h2 className="font-bold text-[var(--text-heading)]">おやすみ時間</h2>
+            <label className="flex items-center gap-2 text-sm text-[var(--text-body)]">
+              <input
+                type="checkbox"
+                checked={quietHours.enabled}
+                onChange={(e) => updateQuietHours({ enabled: e.target.checked })}
+              />
+              この時間帯の通知を控え、終了後にまとめて送る
+            </label>
+            <div className="flex items-center gap-2">
+              <input
+                type="time"
+                value={quietHours.start}
+                onChange={(e) => updateQuietHours({ start: e.target.value })}
+                disabled={!quietHours.enabled}
+                className={inputClass}
+              />
+              <span className="text-[var(--text-body)]">〜</span>
+              <input
+                type="time"
+                value={quietHours.end}
+                onChange={(e) => updateQuietHours({ end: e.target.value })}
+                disabled={!quietHours.enabled}
+                className={inputClass}
+              />
+              <input
+                type="text"
+                value={quietHours.time_zone}
+                onChange={(e) => updateQuietHours({ time_zone: e.target.value })}
+                disabled={!quietHours.enabled}
+                placeholder="Asia/Tokyo"
+                className={`flex-1 ${inputClass}`}
+              />
+            </div>
+          </div>
+
+          <div className="pt-4 border-t border-[var(--card-border)] space-y-3">
+            <h2 className="font-bold text-[var(--text-heading)]">未読まとめメール</h2>
+            <label className="flex items-center gap-2 text-sm text-[var(--text-body)]">
+              <input
+                type="checkbox"
+                checked={unreadDigest.enabled}
+                onChange={(e) => updateUnreadDigest({ enabled: e.target.checked })}
+              />
+              宛先アドレスごとの未読メールの一覧を定期的にメールで受け取る
             </label>
             <input
-              type="text"
-              value={webhookUrl}
-              onChange={(e) => setWebhookUrl(e.target.value)}
-              placeholder="https://discord.com/api/webhooks/..."
-              className="w-full px-3 py-2 border border-[var(--input-border)] bg-[var(--input-background)] rounded-lg focus:ring-2 focus:ring-[var(--primary-color)] focus:border-[var(--primary-color)] outline-none"
+              type="email"
+              value={unreadDigest.to_address}
+              onChange={(e) => updateUnreadDigest({ to_address: e.target.value })}
+              disabled={!unreadDigest.enabled}
+              placeholder="送信先アドレス"
+              className={`w-full ${inputClass}`}
             />
-            <p className="mt-2 text-xs text-[var(--text-body)]">
-              Discordのチャンネル設定 → 連携サービス → ウェブフックから作成できます。
-            </p>
+            <div className="flex items-center gap-2">
+              <input
+                type="text"
+                value={unreadDigest.schedule}
+                onChange={(e) => updateUnreadDigest({ schedule: e.target.value })}
+                disabled={!unreadDigest.enabled}
+                placeholder="分 時 日 月 曜日（例: 0 8 * * 1-5）"
+                className={`flex-1 font-mono ${inputClass}`}
+              />
+              <input
+                type="text"
+                value={unreadDigest.time_zone}
+                onChange={(e) => updateUnreadDigest({ time_zone: e.target.value })}
+                disabled={!unreadDigest.enabled}
+                placeholder="Asia/Tokyo"
+                className={inputClass}
+              />
+            </div>
+            <div className="flex flex-wrap gap-2">
+              {digestPresets.map((preset) => (
+                <button
+                  key={preset.schedule}
+                  type="button"
+                  onClick={() => updateUnreadDigest({ schedule: preset.schedule })}
+                  disabled={!unreadDigest.enabled}
+                  className="text-xs px-2 py-1 border border-[var(--card-border)] rounded text-[var(--text-body)] hover:opacity-80 disabled:opacity-50"
+                >
+                  {preset.label}
+                </button>
+              ))}
+            </div>
+            {digestSentAt && (
+              <p className="text-xs text-[var(--text-body)]">
+                前回の送信: {new Date(digestSentAt).toLocaleString()}
+              </p>
+            )}
           </div>
 
           {error && <p className="text-sm text-red-600">{error}</p>}
@@ -38,6 +38,46 @@ export default function SettingsHomePage() {
               S3ドメイン設定
             </p>
           </button>
+
+          <button
+            onClick={() => router.push("/settings/sender-identities")}
+            className="text-left p-4 rounded-lg border border-[var(--card-border)] bg-[var(--card-background)] hover:opacity-90 transition"
+          >
+            <p className="text-sm text-[var(--text-body)]">送信</p>
+            <p className="text-base font-medium text-[var(--text-heading)]">
+              送信元アドレス
+            </p>
+          </button>
+
+          <button
+            onClick={() => router.push("/settings/templates")}
+            className="text-left p-4 rounded-lg border border-[var(--card-border)] bg-[var(--card-background)] hover:opacity-90 transition"
+          >
+            <p className="text-sm text-[var(--text-body)]">送信</p>
+            <p className="text-base font-medium text-[var(--text-heading)]">
+              テンプレート
+            </p>
+          </button>
+
+          <button
+            onClick={() => router.push("/settings/suppressions")}
+            className="text-left p-4 rounded-lg border border-[var(--card-border)] bg-[var(--card-background)] hover:opacity-90 transition"
+          >
+            <p className="text-sm text-[var(--text-body)]">送信</p>
+            <p className="text-base font-medium text-[var(--text-heading)]">
+              配信停止リスト
+            </p>
+          </button>
+
+          <button
+            onClick={() => router.push("/settings/webhooks")}
+            className="text-left p-4 rounded-lg border border-[var(--card-border)] bg-[var(--card-background)] hover:opacity-90 transition"
+          >
+            <p className="text-sm text-[var(--text-body)]">連携</p>
+            <p className="text-base font-medium text-[var(--text-heading)]">
+              Webhook
+            </p>
+          </button>
         </div>
       </div>
     </div>
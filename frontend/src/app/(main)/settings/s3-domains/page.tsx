@@ -10,12 +10,24 @@ export default function S3DomainsPage() {
   const { user, loading: authLoading } = useAuth();
   const router = useRouter();
   const [domains, setDomains] = useState<S3Domain[]>([]);
-  const [domainForm, setDomainForm] = useState({
+  const [domainForm, setDomainForm] = useState<{
+    name: string;
+    storage_type: S3Domain["storage_type"];
+    bucket: string;
+    region: string;
+    access_key_id: string;
+    secret_key: string;
+    endpoint: string;
+    insecure_tls: boolean;
+  }>({
     name: "",
+    storage_type: "s3",
     bucket: "",
     region: "",
     access_key_id: "",
     secret_key: "",
+    endpoint: "",
+    insecure_tls: false,
   });
   const [loading, setLoading] = useState(true);
   const [message, setMessage] = useState<string | null>(null);
@@ -47,10 +59,13 @@ export default function S3DomainsPage() {
       setDomains((prev) => [...prev, created]);
       setDomainForm({
         name: "",
+        storage_type: "s3",
         bucket: "",
         region: "",
         access_key_id: "",
         secret_key: "",
+        endpoint: "",
+        insecure_tls: false,
       });
       setMessage("ドメインを追加しました");
     } catch (err) {
@@ -69,6 +84,8 @@ export default function S3DomainsPage() {
     }
   };
 
+  const isS3 = domainForm.storage_type === "s3";
+
   if (authLoading || !user || loading) {
     return (
       <div className="flex items-center justify-center min-h-screen">
@@ -100,11 +117,21 @@ export default function S3DomainsPage() {
               className="px-3 py-2 border border-[var(--input-border)] bg-[var(--input-background)] rounded-lg"
               required
             />
+            <select
+              value={domainForm.storage_type}
+              onChange={(e) =>
+                setDomainForm({ ...domainForm, storage_type: e.target.value as S3Domain["storage_type"] })
+              }
+              className="px-3 py-2 border border-[var(--input-border)] bg-[var(--input-background)] rounded-lg"
+            >
+              <option value="s3">S3</option>
+              <option value="filesystem">ローカル (Maildir / .eml)</option>
+            </select>
             <input
               type="text"
               value={domainForm.bucket}
               onChange={(e) => setDomainForm({ ...domainForm, bucket: e.target.value })}
-              placeholder="バケット名"
+              placeholder={isS3 ? "バケット名" : "ディレクトリ（LOCAL_STORAGE_ROOT からの相対パス）"}
               className="px-3 py-2 border border-[var(--input-border)] bg-[var(--input-background)] rounded-lg"
               required
             />
@@ -114,7 +141,8 @@ export default function S3DomainsPage() {
               onChange={(e) => setDomainForm({ ...domainForm, region: e.target.value })}
               placeholder="リージョン"
               className="px-3 py-2 border border-[var(--input-border)] bg-[var(--input-background)] rounded-lg"
-              required
+              required={isS3}
+              disabled={!isS3}
             />
             <input
               type="text"
@@ -122,7 +150,8 @@ export default function S3DomainsPage() {
               onChange={(e) => setDomainForm({ ...domainForm, access_key_id: e.target.value })}
               placeholder="Access Key ID"
               className="px-3 py-2 border border-[var(--input-border)] bg-[var(--input-background)] rounded-lg"
-              required
+              required={isS3}
+              disabled={!isS3}
             />
             <input
               type="password"
@@ -130,8 +159,26 @@ export default function S3DomainsPage() {
               onChange={(e) => setDomainForm({ ...domainForm, secret_key: e.target.value })}
               placeholder="Secret Access Key"
               className="px-3 py-2 border border-[var(--input-border)] bg-[var(--input-background)] rounded-lg"
-              required
+              required={isS3}
+              disabled={!isS3}
+            />
+            <input
+              type="text"
+              disabled={!isS3}
+              value={domainForm.endpoint}
+              onChange={(e) => setDomainForm({ ...domainForm, endpoint: e.target.value })}
+              placeholder="エンドポイント（MinIO 等、任意）"
+              className="px-3 py-2 border border-[var(--input-border)] bg-[var(--input-background)] rounded-lg"
             />
+            <label className="flex items-center gap-2 text-sm text-[var(--text-body)]">
+              <input
+                type="checkbox"
+                disabled={!isS3}
+                checked={domainForm.insecure_tls}
+                onChange={(e) => setDomainForm({ ...domainForm, insecure_tls: e.target.checked })}
+              />
+              TLS 証明書の検証をスキップ
+            </label>
           </div>
           <button
             type="submit"
@@ -152,7 +199,9 @@ export default function S3DomainsPage() {
               <div>
                 <p className="text-sm font-medium text-[var(--text-heading)]">{d.name}</p>
                 <p className="text-xs text-[var(--text-body)]">
-                  {d.bucket} / {d.region}
+                  {d.storage_type === "filesystem"
+                    ? `ローカル: ${d.bucket}`
+                    : `${d.bucket} / ${d.region}${d.endpoint ? ` / ${d.endpoint}` : ""}`}
                 </p>
               </div>
               <button
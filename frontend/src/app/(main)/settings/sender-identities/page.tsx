@@ -0,0 +1,381 @@
+"use client";
+
+import { useEffect, useState } from "react";
+import { useRouter } from "next/navigation";
+import { useAuth } from "@/hooks/useAuth";
+import {
+  createSenderIdentity,
+  deleteSenderIdentity,
+  getSenderIdentities,
+  getSESIdentities,
+  updateSenderIdentity,
+} from "@/lib/api";
+import type { EmailIdentityStatus, SenderIdentity } from "@/types";
+
+const emptyForm = {
+  address: "",
+  display_name: "",
+  signature_text: "",
+  signature_html: "",
+  allowed_uids: "",
+  omit_code_from_body: false,
+  reply_domain: "",
+  return_path: "",
+};
+
+// sesStatusFor returns the SES identity covering address: the address itself,
+// or failing that its domain.
+function sesStatusFor(address: string, statuses: EmailIdentityStatus[]) {
+  const lower = address.toLowerCase();
+  const domain = lower.split("@").pop() ?? "";
+  return (
+    statuses.find((s) => s.identity.toLowerCase() === lower) ??
+    statuses.find((s) => s.identity.toLowerCase() === domain)
+  );
+}
+
+const inputClass =
+  "px-3 py-2 border border-[var(--input-border)] bg-[var(--input-background)] rounded-lg";
+
+export default function SenderIdentitiesPage() {
+  const { user, loading: authLoading } = useAuth();
+  const router = useRouter();
+  const [identities, setIdentities] = useState<SenderIdentity[]>([]);
+  const [form, setForm] = useState(emptyForm);
+  const [editingId, setEditingId] = useState<string | null>(null);
+  const [loading, setLoading] = useState(true);
+  const [message, setMessage] = useState<string | null>(null);
+  const [sesIdentities, setSESIdentities] = useState<EmailIdentityStatus[] | null>(null);
+  const [sesError, setSESError] = useState<string | null>(null);
+  const [sesLoading, setSESLoading] = useState(false);
+
+  const loadSESIdentities = async (refresh = false) => {
+    setSESLoading(true);
+    setSESError(null);
+    try {
+      setSESIdentities(await getSESIdentities(refresh));
+    } catch (err) {
+      setSESIdentities(null);
+      setSESError(err instanceof Error ? err.message : "SES の状態を取得できませんでした");
+    } finally {
+      setSESLoading(false);
+    }
+  };
+
+  useEffect(() => {
+    if (!authLoading && !user) {
+      router.push("/login");
+      return;
+    }
+
+    if (!authLoading && user) {
+      (async () => {
+        try {
+          setLoading(true);
+          setIdentities(await getSenderIdentities());
+        } finally {
+          // Admin only; other users get an error, shown in the SES section.
+          loadSESIdentities();
+          setLoading(false);
+        }
+      })();
+    }
+  }, [authLoading, user, router]);
+
+  const resetForm = () => {
+    setForm(emptyForm);
+    setEditingId(null);
+  };
+
+  const handleSubmit = async (e: React.FormEvent) => {
+    e.preventDefault();
+    setMessage(null);
+    const input = {
+      ...form,
+      allowed_uids: form.allowed_uids
+        .split(",")
+        .map((uid) => uid.trim())
+        .filter(Boolean),
+    };
+    try {
+      if (editingId) {
+        const updated = await updateSenderIdentity(editingId, input);
+        setIdentities((prev) => prev.map((i) => (i.id === updated.id ? updated : i)));
+        setMessage("送信元アドレスを更新しました");
+      } else {
+        const created = await createSenderIdentity(input);
+        setIdentities((prev) => [...prev, created]);
+        setMessage("送信元アドレスを追加しました");
+      }
+      resetForm();
+    } catch (err) {
+      setMessage(err instanceof Error ? err.message : "保存に失敗しました");
+    }
+  };
+
+  const handleEdit = (identity: SenderIdentity) => {
+    setForm({
+      address: identity.address,
+      display_name: identity.display_name,
+      signature_text: identity.signature_text,
+      signature_html: identity.signature_html,
+      allowed_uids: (identity.allowed_uids ?? []).join(", "),
+      omit_code_from_body: identity.omit_code_from_body,
+      reply_domain: identity.reply_domain,
+      return_path: identity.return_path ?? "",
+    });
+    setEditingId(identity.id);
+    setMessage(null);
+  };
+
+  const handleToggleBodyCode = async (identity: SenderIdentity) => {
+    setMessage(null);
+    try {
+      const updated = await updateSenderIdentity(identity.id, {
+        ...identity,
+        omit_code_from_body: !identity.omit_code_from_body,
+      });
+      setIdentities((prev) => prev.map((i) => (i.id === updated.id ? updated : i)));
+    } catch (err) {
+      setMessage(err instanceof Error ? err.message : "更新に失敗しました");
+    }
+  };
+
+  const handleDelete = async (id: string) => {
+    setMessage(null);
+    try {
+      await deleteSenderIdentity(id);
+      setIdentities((prev) => prev.filter((i) => i.id !== id));
+      if (editingId === id) resetForm();
+      setMessage("送信元アドレスを削除しました");
+    } catch (err) {
+      setMessage(err instanceof Error ? err.message : "削除に失敗しました");
+    }
+  };
+
+  if (authLoading || !user || loading) {
+    return (
+      <div className="flex items-center justify-center min-h-screen">
+        <div className="animate-spin rounded-full h-8 w-8 border-b-2 border-[var(--primary-color)]" />
+      </div>
+    );
+  }
+
+  return (
+    <div className="min-h-screen bg-[var(--background)] p-4">
+      <div className="max-w-3xl mx-auto bg-[var(--card-background)] rounded-xl shadow p-6 border border-[var(--card-border)]">
+        <div className="flex items-center justify-between mb-6">
+          <h1 className="text-xl font-bold text-[var(--text-heading)]">送信元アドレス</h1>
+          <button
+            onClick={() => router.push("/settings")}
+            className="text-[var(--text-body)] hover:opacity-80"
+          >
+            ← 戻る
+          </button>
+        </div>
+
+        <p className="text-sm text-[var(--text-body)] mb-4">
+          登録済みのアドレスからのみ送信できます（管理者のみ変更可）。
+        </p>
+
+        <form onSubmit={handleSubmit} className="space-y-3 mb-6">
+          <div className="grid grid-cols-1 md:grid-cols-2 gap-3">
+            <input
+              type="email"
+              value={form.address}
+              onChange={(e) => setForm({ ...form, address: e.target.value })}
+              placeholder="noreply@ml.rikut0904.site"
+              className={inputClass}
+              required
+            />
+            <input
+              type="text"
+              value={form.display_name}
+              onChange={(e) => setForm({ ...form, display_name: e.target.value })}
+              placeholder="表示名（From に表示、任意）"
+              className={inputClass}
+            />
+            <input
+              type="text"
+              value={form.reply_domain}
+              onChange={(e) => setForm({ ...form, reply_domain: e.target.value })}
+              placeholder="返信受付ドメイン（reply+コード@ドメイン、任意）"
+              className={inputClass}
+            />
+            <input
+              type="email"
+              value={form.return_path}
+              onChange={(e) => setForm({ ...form, return_path: e.target.value })}
+              placeholder="バウンス受付アドレス（SMTP の MAIL FROM、空なら送信元アドレス）"
+              className={inputClass}
+            />
+            <input
+              type="text"
+              value={form.allowed_uids}
+              onChange={(e) => setForm({ ...form, allowed_uids: e.target.value })}
+              placeholder="利用できるユーザー UID（カンマ区切り、空なら全員）"
+              className={inputClass}
+            />
+            <textarea
+              value={form.signature_text}
+              onChange={(e) => setForm({ ...form, signature_text: e.target.value })}
+              placeholder="署名（テキスト）"
+              rows={4}
+              className={`${inputClass} text-sm`}
+            />
+            <textarea
+              value={form.signature_html}
+              onChange={(e) => setForm({ ...form, signature_html: e.target.value })}
+              placeholder="署名（HTML、任意）"
+              rows={4}
+              className={`${inputClass} font-mono text-sm`}
+            />
+            <label className="flex items-center gap-2 text-sm text-[var(--text-body)]">
+              <input
+                type="checkbox"
+                checked={form.omit_code_from_body}
+                onChange={(e) => setForm({ ...form, omit_code_from_body: e.target.checked })}
+              />
+              本文に管理コードを表示しない
+            </label>
+          </div>
+          <div className="flex gap-3">
+            <button
+              type="submit"
+              className="px-4 py-2 bg-[var(--primary-color)] text-[var(--text-heading)] rounded-lg hover:opacity-90 border border-[var(--card-border)]"
+            >
+              {editingId ? "更新" : "追加"}
+            </button>
+            {editingId && (
+              <button
+                type="button"
+                onClick={resetForm}
+                className="px-4 py-2 text-[var(--text-body)] rounded-lg hover:opacity-80 border border-[var(--card-border)]"
+              >
+                キャンセル
+              </button>
+            )}
+          </div>
+        </form>
+
+        {message && <p className="text-sm text-[var(--text-body)] mb-3">{message}</p>}
+
+        <div className="space-y-2">
+          {identities.map((identity) => (
+            <div
+              key={identity.id}
+              className="flex items-center justify-between px-3 py-2 border border-[var(--card-border)] rounded-lg"
+            >
+              <div className="min-w-0">
+                <p className="text-sm font-medium text-[var(--text-heading)]">
+                  {identity.display_name
+                    ? `${identity.display_name} <${identity.address}>`
+                    : identity.address}
+                </p>
+                <p className="text-xs text-[var(--text-body)] truncate">
+                  {identity.reply_domain ? `Reply-To: reply+コード@${identity.reply_domain}` : "Reply-To なし"}
+                  {" ・ "}
+                  {identity.allowed_uids?.length
+                    ? `利用者 ${identity.allowed_uids.length} 名`
+                    : "全ユーザーが利用可"}
+                  {identity.signature_text || identity.signature_html ? " ・ 署名あり" : ""}
+                </p>
+                {sesIdentities && (() => {
+                  const status = sesStatusFor(identity.address, sesIdentities);
+                  return (
+                    <p
+                      className={`text-xs ${
+                        status?.verified_for_sending ? "text-green-600" : "text-red-600"
+                      }`}
+                    >
+                      {status
+                        ? `SES: ${status.verified_for_sending ? "検証済み" : status.verification_status}（${status.identity}）`
+                        : "SES: 未登録（送信できません）"}
+                    </p>
+                  );
+                })()}
+              </div>
+              <div className="flex items-center gap-3 shrink-0">
+                <label className="flex items-center gap-1 text-xs text-[var(--text-body)]">
+                  <input
+                    type="checkbox"
+                    checked={!identity.omit_code_from_body}
+                    onChange={() => handleToggleBodyCode(identity)}
+                  />
+                  本文に管理コード
+                </label>
+                <button
+                  onClick={() => handleEdit(identity)}
+                  className="text-sm text-[var(--text-body)] hover:opacity-80"
+                >
+                  編集
+                </button>
+                <button
+                  onClick={() => handleDelete(identity.id)}
+                  className="text-sm text-red-600 hover:opacity-80"
+                >
+                  削除
+                </button>
+              </div>
+            </div>
+          ))}
+          {identities.length === 0 && (
+            <p className="text-sm text-[var(--text-body)]">送信元アドレスが未登録です</p>
+          )}
+        </div>
+
+        <div className="mt-8">
+          <div className="flex items-center justify-between mb-3">
+            <h2 className="text-lg font-bold text-[var(--text-heading)]">SES の検証状況</h2>
+            <button
+              onClick={() => loadSESIdentities(true)}
+              disabled={sesLoading}
+              className="text-sm text-[var(--text-body)] hover:opacity-80 disabled:opacity-50"
+            >
+              {sesLoading ? "取得中..." : "再取得"}
+            </button>
+          </div>
+          {sesError && <p className="text-sm text-[var(--text-body)]">{sesError}</p>}
+          {sesIdentities && (
+            <table className="w-full text-sm text-[var(--text-body)]">
+              <thead>
+                <tr className="text-left text-xs">
+                  <th className="py-1">ID</th>
+                  <th className="py-1">検証</th>
+                  <th className="py-1">DKIM</th>
+                  <th className="py-1">MAIL FROM</th>
+                </tr>
+              </thead>
+              <tbody>
+                {sesIdentities.map((status) => (
+                  <tr key={status.identity} className="border-t border-[var(--card-border)]">
+                    <td className="py-1 break-all">{status.identity}</td>
+                    <td className="py-1">
+                      <span className={status.verified_for_sending ? "text-green-600" : "text-red-600"}>
+                        {status.verification_status || "-"}
+                      </span>
+                    </td>
+                    <td className="py-1">
+                      {status.dkim_status || "-"}
+                      {status.dkim_status && !status.dkim_signing_enabled ? "（署名無効）" : ""}
+                    </td>
+                    <td className="py-1">
+                      {status.mail_from_domain
+                        ? `${status.mail_from_domain}（${status.mail_from_status || "-"}）`
+                        : "-"}
+                    </td>
+                  </tr>
+                ))}
+                {sesIdentities.length === 0 && (
+                  <tr>
+                    <td colSpan={4} className="py-2">SES に ID が登録されていません</td>
+                  </tr>
+                )}
+              </tbody>
+            </table>
+          )}
+        </div>
+      </div>
+    </div>
+  );
+}
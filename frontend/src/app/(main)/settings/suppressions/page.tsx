@@ -0,0 +1,136 @@
+"use client";
+
+import { useEffect, useState } from "react";
+import { useRouter } from "next/navigation";
+import { useAuth } from "@/hooks/useAuth";
+import { createSuppression, deleteSuppression, getSuppressions } from "@/lib/api";
+import type { SuppressedAddress } from "@/types";
+
+export default function SuppressionsPage() {
+  const { user, loading: authLoading } = useAuth();
+  const router = useRouter();
+  const [addresses, setAddresses] = useState<SuppressedAddress[]>([]);
+  const [email, setEmail] = useState("");
+  const [loading, setLoading] = useState(true);
+  const [message, setMessage] = useState<string | null>(null);
+
+  useEffect(() => {
+    if (!authLoading && !user) {
+      router.push("/login");
+      return;
+    }
+
+    if (!authLoading && user) {
+      (async () => {
+        try {
+          setLoading(true);
+          setAddresses(await getSuppressions());
+        } catch (err) {
+          setMessage(err instanceof Error ? err.message : "取得に失敗しました");
+        } finally {
+          setLoading(false);
+        }
+      })();
+    }
+  }, [authLoading, user, router]);
+
+  const handleCreate = async (e: React.FormEvent) => {
+    e.preventDefault();
+    setMessage(null);
+    try {
+      const created = await createSuppression(email);
+      setAddresses((prev) => [created, ...prev.filter((a) => a.email !== created.email)]);
+      setEmail("");
+      setMessage("配信停止リストに追加しました");
+    } catch (err) {
+      setMessage(err instanceof Error ? err.message : "追加に失敗しました");
+    }
+  };
+
+  const handleDelete = async (target: string) => {
+    if (!confirm(`${target} への送信を再開しますか？`)) return;
+    setMessage(null);
+    try {
+      await deleteSuppression(target);
+      setAddresses((prev) => prev.filter((a) => a.email !== target));
+      setMessage("配信停止リストから削除しました");
+    } catch (err) {
+      setMessage(err instanceof Error ? err.message : "削除に失敗しました");
+    }
+  };
+
+  if (authLoading || !user || loading) {
+    return (
+      <div className="flex items-center justify-center min-h-screen">
+        <div className="animate-spin rounded-full h-8 w-8 border-b-2 border-[var(--primary-color)]" />
+      </div>
+    );
+  }
+
+  return (
+    <div className="min-h-screen bg-[var(--background)] p-4">
+      <div className="max-w-3xl mx-auto bg-[var(--card-background)] rounded-xl shadow p-6 border border-[var(--card-border)]">
+        <div className="flex items-center justify-between mb-6">
+          <h1 className="text-xl font-bold text-[var(--text-heading)]">配信停止リスト</h1>
+          <button
+            onClick={() => router.push("/settings")}
+            className="text-[var(--text-body)] hover:opacity-80"
+          >
+            ← 戻る
+          </button>
+        </div>
+
+        <p className="text-sm text-[var(--text-body)] mb-4">
+          ハードバウンスまたは迷惑メール報告があったアドレスには送信できません（管理者のみ変更可）。
+        </p>
+
+        <form onSubmit={handleCreate} className="flex gap-3 mb-6">
+          <input
+            type="email"
+            value={email}
+            onChange={(e) => setEmail(e.target.value)}
+            placeholder="user@example.com"
+            className="flex-1 px-3 py-2 border border-[var(--input-border)] bg-[var(--input-background)] rounded-lg"
+            required
+          />
+          <button
+            type="submit"
+            className="px-4 py-2 bg-[var(--primary-color)] text-[var(--text-heading)] rounded-lg hover:opacity-90 border border-[var(--card-border)]"
+          >
+            追加
+          </button>
+        </form>
+
+        {message && <p className="text-sm text-[var(--text-body)] mb-3">{message}</p>}
+
+        <div className="space-y-2">
+          {addresses.map((a) => (
+            <div
+              key={a.email}
+              className="flex items-center justify-between px-3 py-2 border border-[var(--card-border)] rounded-lg"
+            >
+              <div className="min-w-0">
+                <p className="text-sm font-medium text-[var(--text-heading)]">{a.email}</p>
+                <p className="text-xs text-[var(--text-body)] truncate">
+                  {a.reason === "bounce" ? "バウンス" : "迷惑メール報告"}
+                  {a.detail ? ` ・ ${a.detail}` : ""}
+                  {" ・ "}
+                  {new Date(a.updated_at).toLocaleString("ja-JP")}
+                </p>
+              </div>
+              <button
+                onClick={() => handleDelete(a.email)}
+                className="text-sm text-red-600 hover:opacity-80"
+              >
+                削除
+              </button>
+            </div>
+          ))}
+          {addresses.length === 0 && (
+            <p className="text-sm text-[var(--text-body)]">配信停止中のアドレスはありません</p>
+          )}
+        </div>
+      </div>
+    </div>
+  );
+}
@@ -0,0 +1,287 @@
+"use client";
+
+import { useEffect, useState } from "react";
+import { useRouter } from "next/navigation";
+import { useAuth } from "@/hooks/useAuth";
+import {
+  createTemplate,
+  deleteTemplate,
+  getTemplates,
+  previewMail,
+  updateTemplate,
+} from "@/lib/api";
+import type { MailTemplate, MailTemplateInput, MessagePreview } from "@/types";
+
+const emptyForm: MailTemplateInput = {
+  name: "",
+  subject: "",
+  text_body: "",
+  html_body: "",
+};
+
+export default function TemplatesPage() {
+  const { user, loading: authLoading } = useAuth();
+  const router = useRouter();
+  const [templates, setTemplates] = useState<MailTemplate[]>([]);
+  const [form, setForm] = useState<MailTemplateInput>(emptyForm);
+  const [editingId, setEditingId] = useState<string | null>(null);
+  const [fromAddress, setFromAddress] = useState("");
+  const [variables, setVariables] = useState("{\n  \"Name\": \"山田 太郎\"\n}");
+  const [preview, setPreview] = useState<MessagePreview | null>(null);
+  const [loading, setLoading] = useState(true);
+  const [message, setMessage] = useState<string | null>(null);
+
+  useEffect(() => {
+    if (!authLoading && !user) {
+      router.push("/login");
+      return;
+    }
+
+    if (!authLoading && user) {
+      (async () => {
+        try {
+          setLoading(true);
+          setTemplates(await getTemplates());
+        } catch (err) {
+          setMessage(err instanceof Error ? err.message : "取得に失敗しました");
+        } finally {
+          setLoading(false);
+        }
+      })();
+    }
+  }, [authLoading, user, router]);
+
+  const resetForm = () => {
+    setForm(emptyForm);
+    setEditingId(null);
+    setPreview(null);
+  };
+
+  const handleSubmit = async (e: React.FormEvent) => {
+    e.preventDefault();
+    setMessage(null);
+    try {
+      if (editingId) {
+        const updated = await updateTemplate(editingId, form);
+        setTemplates((prev) => prev.map((t) => (t.id === updated.id ? updated : t)));
+        setMessage("テンプレートを更新しました");
+      } else {
+        const created = await createTemplate(form);
+        setTemplates((prev) => [...prev, created].sort((a, b) => a.name.localeCompare(b.name)));
+        setEditingId(created.id);
+        setMessage("テンプレートを追加しました");
+      }
+    } catch (err) {
+      setMessage(err instanceof Error ? err.message : "保存に失敗しました");
+    }
+  };
+
+  const handleEdit = (template: MailTemplate) => {
+    setForm({
+      name: template.name,
+      subject: template.subject,
+      text_body: template.text_body,
+      html_body: template.html_body,
+    });
+    setEditingId(template.id);
+    setPreview(null);
+    setMessage(null);
+  };
+
+  const handleDelete = async (template: MailTemplate) => {
+    if (!confirm(`テンプレート「${template.name}」を削除しますか？`)) return;
+    setMessage(null);
+    try {
+      await deleteTemplate(template.id);
+      setTemplates((prev) => prev.filter((t) => t.id !== template.id));
+      if (editingId === template.id) resetForm();
+      setMessage("テンプレートを削除しました");
+    } catch (err) {
+      setMessage(err instanceof Error ? err.message : "削除に失敗しました");
+    }
+  };
+
+  const handlePreview = async () => {
+    if (!editingId) return;
+    setMessage(null);
+    let vars: Record<string, unknown>;
+    try {
+      vars = variables.trim() ? JSON.parse(variables) : {};
+    } catch {
+      setMessage("変数は JSON で入力してください");
+      return;
+    }
+    try {
+      setPreview(
+        await previewMail({
+          template_id: editingId,
+          variables: vars,
+          from_address: fromAddress,
+          to: [],
+          send_type: "new",
+        })
+      );
+    } catch (err) {
+      setPreview(null);
+      setMessage(err instanceof Error ? err.message : "プレビューに失敗しました");
+    }
+  };
+
+  if (authLoading || !user || loading) {
+    return (
+      <div className="flex items-center justify-center min-h-screen">
+        <div className="animate-spin rounded-full h-8 w-8 border-b-2 border-[var(--primary-color)]" />
+      </div>
+    );
+  }
+
+  const inputClass =
+    "w-full px-3 py-2 border border-[var(--input-border)] bg-[var(--input-background)] rounded-lg";
+
+  return (
+    <div className="min-h-screen bg-[var(--background)] p-4">
+      <div className="max-w-3xl mx-auto bg-[var(--card-background)] rounded-xl shadow p-6 border border-[var(--card-border)]">
+        <div className="flex items-center justify-between mb-6">
+          <h1 className="text-xl font-bold text-[var(--text-heading)]">テンプレート</h1>
+          <button
+            onClick={() => router.push("/settings")}
+            className="text-[var(--text-body)] hover:opacity-80"
+          >
+            ← 戻る
+          </button>
+        </div>
+
+        <p className="text-sm text-[var(--text-body)] mb-4">
+          件名・本文には <code>{"{{.Name}}"}</code> のように変数を記述できます（Go テンプレート形式）。
+        </p>
+
+        <form onSubmit={handleSubmit} className="space-y-3 mb-6">
+          <input
+            type="text"
+            value={form.name}
+            onChange={(e) => setForm({ ...form, name: e.target.value })}
+            placeholder="テンプレート名"
+            className={inputClass}
+            required
+          />
+          <input
+            type="text"
+            value={form.subject}
+            onChange={(e) => setForm({ ...form, subject: e.target.value })}
+            placeholder="件名"
+            className={inputClass}
+            required
+          />
+          <textarea
+            value={form.text_body}
+            onChange={(e) => setForm({ ...form, text_body: e.target.value })}
+            placeholder="本文（テキスト）"
+            rows={8}
+            className={`${inputClass} font-mono text-sm`}
+            required
+          />
+          <textarea
+            value={form.html_body}
+            onChange={(e) => setForm({ ...form, html_body: e.target.value })}
+            placeholder="本文（HTML、任意）"
+            rows={4}
+            className={`${inputClass} font-mono text-sm`}
+          />
+          <div className="flex gap-3">
+            <button
+              type="submit"
+              className="px-4 py-2 bg-[var(--primary-color)] text-[var(--text-heading)] rounded-lg hover:opacity-90 border border-[var(--card-border)]"
+            >
+              {editingId ? "更新" : "追加"}
+            </button>
+            {editingId && (
+              <button
+                type="button"
+                onClick={resetForm}
+                className="px-4 py-2 text-[var(--text-body)] rounded-lg hover:opacity-80 border border-[var(--card-border)]"
+              >
+                新規作成に切り替え
+              </button>
+            )}
+          </div>
+        </form>
+
+        {editingId && (
+          <div className="space-y-3 mb-6 p-4 border border-[var(--card-border)] rounded-lg">
+            <p className="text-sm font-medium text-[var(--text-heading)]">プレビュー</p>
+            <input
+              type="email"
+              value={fromAddress}
+              onChange={(e) => setFromAddress(e.target.value)}
+              placeholder="送信元アドレス"
+              className={inputClass}
+            />
+            <textarea
+              value={variables}
+              onChange={(e) => setVariables(e.target.value)}
+              placeholder='{"Name": "山田 太郎"}'
+              rows={4}
+              className={`${inputClass} font-mono text-sm`}
+            />
+            <button
+              type="button"
+              onClick={handlePreview}
+              disabled={!fromAddress}
+              className="px-4 py-2 text-[var(--text-heading)] rounded-lg hover:opacity-90 border border-[var(--card-border)] disabled:opacity-50"
+            >
+              プレビュー
+            </button>
+            {preview && (
+              <div className="space-y-2">
+                <p className="text-sm text-[var(--text-heading)]">件名: {preview.subject}</p>
+                <pre className="text-sm text-[var(--text-body)] whitespace-pre-wrap p-3 rounded-lg bg-[var(--input-background)]">
+                  {preview.body}
+                </pre>
+                {preview.html_body && (
+                  <iframe
+                    sandbox=""
+                    srcDoc={preview.html_body}
+                    className="w-full h-64 border border-[var(--card-border)] rounded-lg bg-white"
+                  />
+                )}
+              </div>
+            )}
+          </div>
+        )}
+
+        {message && <p className="text-sm text-[var(--text-body)] mb-3">{message}</p>}
+
+        <div className="space-y-2">
+          {templates.map((t) => (
+            <div
+              key={t.id}
+              className="flex items-center justify-between px-3 py-2 border border-[var(--card-border)] rounded-lg"
+            >
+              <div className="min-w-0">
+                <p className="text-sm font-medium text-[var(--text-heading)]">{t.name}</p>
+                <p className="text-xs text-[var(--text-body)] truncate">{t.subject}</p>
+              </div>
+              <div className="flex gap-3 shrink-0">
+                <button
+                  onClick={() => handleEdit(t)}
+                  className="text-sm text-[var(--text-body)] hover:opacity-80"
+                >
+                  編集
+                </button>
+                <button
+                  onClick={() => handleDelete(t)}
+                  className="text-sm text-red-600 hover:opacity-80"
+                >
+                  削除
+                </button>
+              </div>
+            </div>
+          ))}
+          {templates.length === 0 && (
+            <p className="text-sm text-[var(--text-body)]">テンプレートが未登録です</p>
+          )}
+        </div>
+      </div>
+    </div>
+  );
+}
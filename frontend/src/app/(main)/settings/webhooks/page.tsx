@@ -0,0 +1,318 @@
+"use client";
+
+import { useEffect, useState } from "react";
+import { useRouter } from "next/navigation";
+import { useAuth } from "@/hooks/useAuth";
+import {
+  createWebhook,
+  deleteWebhook,
+  getWebhookDeliveries,
+  getWebhooks,
+  updateWebhook,
+} from "@/lib/api";
+import { WEBHOOK_EVENTS } from "@/types";
+import type { WebhookDelivery, WebhookSubscription, WebhookSubscriptionInput } from "@/types";
+
+const emptyForm: WebhookSubscriptionInput = {
+  url: "",
+  events: [],
+  secret: "",
+  description: "",
+  enabled: true,
+};
+
+const inputClass =
+  "px-3 py-2 border border-[var(--input-border)] bg-[var(--input-background)] rounded-lg";
+
+const deliveryStatusLabels: Record<WebhookDelivery["status"], string> = {
+  pending: "再送待ち",
+  sending: "送信中",
+  succeeded: "成功",
+  failed: "失敗",
+};
+
+export default function WebhooksPage() {
+  const { user, loading: authLoading } = useAuth();
+  const router = useRouter();
+  const [subscriptions, setSubscriptions] = useState<WebhookSubscription[]>([]);
+  const [form, setForm] = useState<WebhookSubscriptionInput>(emptyForm);
+  const [editingId, setEditingId] = useState<string | null>(null);
+  const [logId, setLogId] = useState<string | null>(null);
+  const [deliveries, setDeliveries] = useState<WebhookDelivery[]>([]);
+  const [loading, setLoading] = useState(true);
+  const [message, setMessage] = useState<string | null>(null);
+
+  useEffect(() => {
+    if (!authLoading && !user) {
+      router.push("/login");
+      return;
+    }
+
+    if (!authLoading && user) {
+      (async () => {
+        try {
+          setLoading(true);
+          setSubscriptions(await getWebhooks());
+        } catch (err) {
+          setMessage(err instanceof Error ? err.message : "取得に失敗しました");
+        } finally {
+          setLoading(false);
+        }
+      })();
+    }
+  }, [authLoading, user, router]);
+
+  const resetForm = () => {
+    setForm(emptyForm);
+    setEditingId(null);
+  };
+
+  const toggleEvent = (event: string) => {
+    setForm((prev) => ({
+      ...prev,
+      events: prev.events.includes(event)
+        ? prev.events.filter((e) => e !== event)
+        : [...prev.events, event],
+    }));
+  };
+
+  const handleSubmit = async (e: React.FormEvent) => {
+    e.preventDefault();
+    setMessage(null);
+    try {
+      if (editingId) {
+        const updated = await updateWebhook(editingId, form);
+        setSubscriptions((prev) => prev.map((s) => (s.id === updated.id ? updated : s)));
+        setMessage("Webhook を更新しました");
+      } else {
+        const created = await createWebhook(form);
+        setSubscriptions((prev) => [...prev, created]);
+        setMessage("Webhook を追加しました。シークレットは一覧から確認できます");
+      }
+      resetForm();
+    } catch (err) {
+      setMessage(err instanceof Error ? err.message : "保存に失敗しました");
+    }
+  };
+
+  const handleEdit = (subscription: WebhookSubscription) => {
+    setForm({
+      url: subscription.url,
+      events: subscription.events ?? [],
+      secret: "",
+      description: subscription.description,
+      enabled: subscription.enabled,
+    });
+    setEditingId(subscription.id);
+    setMessage(null);
+  };
+
+  const handleDelete = async (id: string) => {
+    if (!confirm("この Webhook と配信ログを削除しますか？")) return;
+    setMessage(null);
+    try {
+      await deleteWebhook(id);
+      setSubscriptions((prev) => prev.filter((s) => s.id !== id));
+      if (editingId === id) resetForm();
+      if (logId === id) setLogId(null);
+      setMessage("Webhook を削除しました");
+    } catch (err) {
+      setMessage(err instanceof Error ? err.message : "削除に失敗しました");
+    }
+  };
+
+  const handleShowLog = async (id: string) => {
+    if (logId === id) {
+      setLogId(null);
+      return;
+    }
+    setMessage(null);
+    try {
+      setDeliveries(await getWebhookDeliveries(id));
+      setLogId(id);
+    } catch (err) {
+      setMessage(err instanceof Error ? err.message : "配信ログの取得に失敗しました");
+    }
+  };
+
+  if (authLoading || !user || loading) {
+    return (
+      <div className="flex items-center justify-center min-h-screen">
+        <div className="animate-spin rounded-full h-8 w-8 border-b-2 border-[var(--primary-color)]" />
+      </div>
+    );
+  }
+
+  return (
+    <div className="min-h-screen bg-[var(--background)] p-4">
+      <div className="max-w-3xl mx-auto bg-[var(--card-background)] rounded-xl shadow p-6 border border-[var(--card-border)]">
+        <div className="flex items-center justify-between mb-6">
+          <h1 className="text-xl font-bold text-[var(--text-heading)]">Webhook</h1>
+          <button
+            onClick={() => router.push("/settings")}
+            className="text-[var(--text-body)] hover:opacity-80"
+          >
+            ← 戻る
+          </button>
+        </div>
+
+        <p className="text-sm text-[var(--text-body)] mb-4">
+          メールのイベントを署名付き JSON で外部サービスへ送信します（管理者のみ）。
+          署名は <code>X-Mailer-Signature</code> ヘッダーに
+          <code>sha256=HMAC(シークレット, タイムスタンプ + &quot;.&quot; + 本文)</code> の形式で付与されます。
+        </p>
+
+        <form onSubmit={handleSubmit} className="space-y-3 mb-6">
+          <div className="grid grid-cols-1 md:grid-cols-2 gap-3">
+            <input
+              type="url"
+              value={form.url}
+              onChange={(e) => setForm({ ...form, url: e.target.value })}
+              placeholder="https://example.com/hooks/mailer"
+              className={inputClass}
+              required
+            />
+            <input
+              type="text"
+              value={form.description}
+              onChange={(e) => setForm({ ...form, description: e.target.value })}
+              placeholder="説明（任意）"
+              className={inputClass}
+            />
+            <input
+              type="text"
+              value={form.secret}
+              onChange={(e) => setForm({ ...form, secret: e.target.value })}
+              placeholder={editingId ? "シークレット（空なら変更しない）" : "シークレット（空なら自動生成）"}
+              className={`${inputClass} md:col-span-2`}
+            />
+          </div>
+          <div className="flex flex-wrap gap-3 text-sm text-[var(--text-body)]">
+            {WEBHOOK_EVENTS.map((event) => (
+              <label key={event} className="flex items-center gap-1">
+                <input
+                  type="checkbox"
+                  checked={form.events.includes(event)}
+                  onChange={() => toggleEvent(event)}
+                />
+                {event}
+              </label>
+            ))}
+            <span className="text-xs">（未選択なら全イベント）</span>
+          </div>
+          <label className="flex items-center gap-2 text-sm text-[var(--text-body)]">
+            <input
+              type="checkbox"
+              checked={form.enabled}
+              onChange={(e) => setForm({ ...form, enabled: e.target.checked })}
+            />
+            有効
+          </label>
+          <div className="flex gap-3">
+            <button
+              type="submit"
+              className="px-4 py-2 bg-[var(--primary-color)] text-[var(--text-heading)] rounded-lg hover:opacity-90 border border-[var(--card-border)]"
+            >
+              {editingId ? "更新" : "追加"}
+            </button>
+            {editingId && (
+              <button
+                type="button"
+                onClick={resetForm}
+                className="px-4 py-2 text-[var(--text-body)] rounded-lg hover:opacity-80 border border-[var(--card-border)]"
+              >
+                キャンセル
+              </button>
+            )}
+          </div>
+        </form>
+
+        {message && <p className="text-sm text-[var(--text-body)] mb-3">{message}</p>}
+
+        <div className="space-y-2">
+          {subscriptions.map((subscription) => (
+            <div
+              key={subscription.id}
+              className="px-3 py-2 border border-[var(--card-border)] rounded-lg"
+            >
+              <div className="flex items-center justify-between">
+                <div className="min-w-0">
+                  <p className="text-sm font-medium text-[var(--text-heading)] truncate">
+                    {subscription.url}
+                  </p>
+                  <p className="text-xs text-[var(--text-body)] truncate">
+                    {subscription.enabled ? "有効" : "無効"}
+                    {" ・ "}
+                    {subscription.events?.length ? subscription.events.join(", ") : "全イベント"}
+                    {subscription.description ? ` ・ ${subscription.description}` : ""}
+                  </p>
+                  <p className="text-xs text-[var(--text-body)] font-mono truncate">
+                    {subscription.secret}
+                  </p>
+                </div>
+                <div className="flex items-center gap-3 shrink-0">
+                  <button
+                    onClick={() => handleShowLog(subscription.id)}
+                    className="text-sm text-[var(--text-body)] hover:opacity-80"
+                  >
+                    {logId === subscription.id ? "ログを閉じる" : "配信ログ"}
+                  </button>
+                  <button
+                    onClick={() => handleEdit(subscription)}
+                    className="text-sm text-[var(--text-body)] hover:opacity-80"
+                  >
+                    編集
+                  </button>
+                  <button
+                    onClick={() => handleDelete(subscription.id)}
+                    className="text-sm text-red-600 hover:opacity-80"
+                  >
+                    削除
+                  </button>
+                </div>
+              </div>
+
+              {logId === subscription.id && (
+                <div className="mt-3 space-y-1">
+                  {deliveries.map((delivery) => (
+                    <details key={delivery.id} className="text-xs text-[var(--text-body)]">
+                      <summary className="cursor-pointer">
+                        {new Date(delivery.created_at).toLocaleString("ja-JP")}
+                        {" ・ "}
+                        {delivery.event}
+                        {" ・ "}
+                        <span
+                          className={
+                            delivery.status === "succeeded"
+                              ? "text-green-600"
+                              : delivery.status === "failed"
+                                ? "text-red-600"
+                                : ""
+                          }
+                        >
+                          {deliveryStatusLabels[delivery.status] ?? delivery.status}
+                        </span>
+                        {delivery.response_status ? ` (HTTP ${delivery.response_status})` : ""}
+                        {` ・ 試行 ${delivery.attempts} 回`}
+                      </summary>
+                      {delivery.last_error && (
+                        <p className="mt-1 text-red-600 break-all">{delivery.last_error}</p>
+                      )}
+                      <pre className="mt-1 p-2 bg-[var(--background)] rounded overflow-x-auto">
+                        {delivery.payload}
+                      </pre>
+                    </details>
+                  ))}
+                  {deliveries.length === 0 && <p className="text-xs">配信ログはありません</p>}
+                </div>
+              )}
+            </div>
+          ))}
+          {subscriptions.length === 0 && (
+            <p className="text-sm text-[var(--text-body)]">Webhook は未登録です</p>
+          )}
+        </div>
+      </div>
+    </div>
+  );
+}
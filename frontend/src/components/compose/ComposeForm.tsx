@@ -1,7 +1,17 @@
 "use client";
 
-import { useState } from "react";
-import { sendMail } from "@/lib/api";
+import { useCallback, useEffect, useRef, useState } from "react";
+import {
+  createDraft,
+  deleteDraftAttachment,
+  getSenderIdentities,
+  sendDraft,
+  updateDraft,
+  uploadDraftAttachments,
+} from "@/lib/api";
+import type { Draft, DraftAttachment, DraftInput, SendResponse, SenderIdentity } from "@/types";
+
+const AUTOSAVE_DELAY_MS = 1500;
 
 interface ComposeFormProps {
   initialTo?: string;
@@ -9,59 +19,195 @@ interface ComposeFormProps {
   initialBody?: string;
   threadId?: string;
   replyCode?: string;
+  inReplyTo?: string;
+  references?: string[];
   sendType?: "new" | "reply" | "forward";
+  forwardS3Key?: string;
+  // Resumes a saved draft; its fields take precedence over the initial props
+  draft?: Draft;
   onClose: () => void;
-  onSent: () => void;
+  onSent: (result: SendResponse) => void;
 }
 
+const splitAddresses = (value: string) =>
+  value
+    .split(",")
+    .map((r) => r.trim())
+    .filter(Boolean);
+
 export default function ComposeForm({
   initialTo = "",
   initialSubject = "",
   initialBody = "",
   threadId,
   replyCode,
+  inReplyTo,
+  references,
   sendType = "new",
+  forwardS3Key,
+  draft,
   onClose,
   onSent,
 }: ComposeFormProps) {
-  const [to, setTo] = useState(initialTo);
-  const [subject, setSubject] = useState(initialSubject);
-  const [body, setBody] = useState(initialBody);
-  const [fromAddress, setFromAddress] = useState("");
+  const [to, setTo] = useState(draft?.to?.join(", ") ?? initialTo);
+  const [cc, setCc] = useState(draft?.cc?.join(", ") ?? "");
+  const [bcc, setBcc] = useState(draft?.bcc?.join(", ") ?? "");
+  const [replyTo, setReplyTo] = useState(draft?.reply_to ?? "");
+  const [sendAt, setSendAt] = useState("");
+  const [subject, setSubject] = useState(draft?.subject ?? initialSubject);
+  const [body, setBody] = useState(draft?.body ?? initialBody);
+  const [fromAddress, setFromAddress] = useState(draft?.from_address ?? "");
+  const [identities, setIdentities] = useState<SenderIdentity[]>([]);
+  const [attachments, setAttachments] = useState<DraftAttachment[]>(draft?.attachments ?? []);
+  const [uploading, setUploading] = useState(false);
+  const [includeOriginalAttachments, setIncludeOriginalAttachments] = useState(
+    draft ? (draft.attachment_refs?.length ?? 0) > 0 : true
+  );
   const [sending, setSending] = useState(false);
+  const [savedAt, setSavedAt] = useState<Date | null>(null);
   const [error, setError] = useState<string | null>(null);
 
+  const mode = draft?.send_type ?? sendType;
+  const originalS3Key = draft?.attachment_refs?.[0]?.s3_key ?? forwardS3Key;
+
+  const draftIdRef = useRef<string | null>(draft?.id ?? null);
+  const creatingRef = useRef<Promise<Draft> | null>(null);
+  const dirtyRef = useRef(false);
+  const sentRef = useRef(false);
+
+  const buildDraftInput = useCallback(
+    (): DraftInput => ({
+      to: splitAddresses(to),
+      cc: splitAddresses(cc),
+      bcc: splitAddresses(bcc),
+      reply_to: replyTo.trim(),
+      from_address: fromAddress.trim(),
+      subject,
+      body,
+      send_type: mode,
+      thread_id: draft?.thread_id ?? threadId,
+      reply_code: draft?.reply_code ?? replyCode,
+      in_reply_to: draft?.in_reply_to ?? inReplyTo,
+      references: draft?.references ?? references,
+      attachment_refs:
+        originalS3Key && includeOriginalAttachments ? [{ s3_key: originalS3Key }] : [],
+    }),
+    [
+      to,
+      cc,
+      bcc,
+      replyTo,
+      fromAddress,
+      subject,
+      body,
+      mode,
+      draft,
+      threadId,
+      replyCode,
+      inReplyTo,
+      references,
+      originalS3Key,
+      includeOriginalAttachments,
+    ]
+  );
+
+  // saveDraft creates the draft on first use and updates it afterwards.
+  const saveDraft = useCallback(async (): Promise<string> => {
+    const input = buildDraftInput();
+    let id = draftIdRef.current;
+    if (!id && creatingRef.current) {
+      id = (await creatingRef.current).id;
+      draftIdRef.current = id;
+    }
+
+    if (id) {
+      await updateDraft(id, input);
+    } else {
+      creatingRef.current = createDraft(input);
+      try {
+        id = (await creatingRef.current).id;
+      } catch (err) {
+        creatingRef.current = null;
+        throw err;
+      }
+      draftIdRef.current = id;
+    }
+    dirtyRef.current = false;
+    setSavedAt(new Date());
+    return id;
+  }, [buildDraftInput]);
+
+  // Only configured sender identities can be sent from; preselect the first.
+  useEffect(() => {
+    getSenderIdentities()
+      .then((list) => {
+        setIdentities(list);
+        if (list.length > 0) setFromAddress((current) => current || list[0].address);
+      })
+      .catch(() => setIdentities([]));
+  }, []);
+
+  // Autosave shortly after the user stops typing.
+  useEffect(() => {
+    if (!dirtyRef.current || sentRef.current) return;
+    const timer = setTimeout(() => {
+      saveDraft().catch(() => setError("下書きの保存に失敗しました"));
+    }, AUTOSAVE_DELAY_MS);
+    return () => clearTimeout(timer);
+  }, [saveDraft]);
+
+  const edit = <T,>(setter: (value: T) => void) => (value: T) => {
+    dirtyRef.current = true;
+    setter(value);
+  };
+
+  const handleFiles = async (files: File[]) => {
+    if (files.length === 0) return;
+    setError(null);
+    try {
+      setUploading(true);
+      const id = await saveDraft();
+      const updated = await uploadDraftAttachments(id, files);
+      setAttachments(updated.attachments);
+    } catch (err) {
+      setError(err instanceof Error ? err.message : "添付ファイルのアップロードに失敗しました");
+    } finally {
+      setUploading(false);
+    }
+  };
+
+  const handleRemoveAttachment = async (attachmentId: string) => {
+    if (!draftIdRef.current) return;
+    setError(null);
+    try {
+      const updated = await deleteDraftAttachment(draftIdRef.current, attachmentId);
+      setAttachments(updated.attachments);
+    } catch (err) {
+      setError(err instanceof Error ? err.message : "添付ファイルの削除に失敗しました");
+    }
+  };
+
   const handleSubmit = async (e: React.FormEvent) => {
     e.preventDefault();
     setError(null);
 
-    const recipients = to
-      .split(",")
-      .map((r) => r.trim())
-      .filter(Boolean);
-
-    if (recipients.length === 0) {
+    const input = buildDraftInput();
+    if (input.to.length + input.cc.length + input.bcc.length === 0) {
       setError("宛先を1件以上入力してください");
       return;
     }
 
-    if (!fromAddress.trim()) {
-      setError("差出人を入力してください");
+    if (!input.from_address) {
+      setError("差出人を選択してください");
       return;
     }
 
     try {
       setSending(true);
-      await sendMail({
-        to: recipients,
-        subject,
-        body,
-        send_type: sendType,
-        thread_id: threadId,
-        reply_code: replyCode,
-        from_address: fromAddress || undefined,
-      });
-      onSent();
+      const id = await saveDraft();
+      const result = await sendDraft(id, sendAt ? new Date(sendAt).toISOString() : undefined);
+      sentRef.current = true;
+      onSent(result);
     } catch (err) {
       setError(err instanceof Error ? err.message : "送信に失敗しました");
     } finally {
@@ -75,9 +221,9 @@ export default function ComposeForm({
         {/* Header */}
         <div className="flex items-center justify-between p-4 border-b border-[var(--card-border)]">
           <h2 className="font-semibold text-[var(--text-heading)]">
-            {sendType === "new"
+            {mode === "new"
               ? "新規メール"
-              : sendType === "reply"
+              : mode === "reply"
               ? "返信"
               : "転送"}
           </h2>
@@ -96,14 +242,24 @@ export default function ComposeForm({
               <label className="block text-sm font-medium text-[var(--text-body)] mb-1">
                 差出人
               </label>
-              <input
-                type="email"
+              <select
                 value={fromAddress}
-                onChange={(e) => setFromAddress(e.target.value)}
-                placeholder="noreply@ml.rikut0904.site"
+                onChange={(e) => edit(setFromAddress)(e.target.value)}
                 className="w-full px-3 py-2 border border-[var(--input-border)] bg-[var(--input-background)] rounded-lg focus:ring-2 focus:ring-[var(--primary-color)] focus:border-[var(--primary-color)] outline-none"
                 required
-              />
+              >
+                {identities.length === 0 && <option value="">送信元アドレスが未登録です</option>}
+                {fromAddress && !identities.some((i) => i.address === fromAddress) && (
+                  <option value={fromAddress}>{fromAddress}</option>
+                )}
+                {identities.map((identity) => (
+                  <option key={identity.id} value={identity.address}>
+                    {identity.display_name
+                      ? `${identity.display_name} <${identity.address}>`
+                      : identity.address}
+                  </option>
+                ))}
+              </select>
             </div>
             <div>
               <label className="block text-sm font-medium text-[var(--text-body)] mb-1">
@@ -112,10 +268,45 @@ export default function ComposeForm({
               <input
                 type="text"
                 value={to}
-                onChange={(e) => setTo(e.target.value)}
+                onChange={(e) => edit(setTo)(e.target.value)}
                 placeholder="user@example.com"
                 className="w-full px-3 py-2 border border-[var(--input-border)] bg-[var(--input-background)] rounded-lg focus:ring-2 focus:ring-[var(--primary-color)] focus:border-[var(--primary-color)] outline-none"
-                required
+              />
+            </div>
+            <div>
+              <label className="block text-sm font-medium text-[var(--text-body)] mb-1">
+                CC（カンマ区切り）
+              </label>
+              <input
+                type="text"
+                value={cc}
+                onChange={(e) => edit(setCc)(e.target.value)}
+                placeholder="cc@example.com"
+                className="w-full px-3 py-2 border border-[var(--input-border)] bg-[var(--input-background)] rounded-lg focus:ring-2 focus:ring-[var(--primary-color)] focus:border-[var(--primary-color)] outline-none"
+              />
+            </div>
+            <div>
+              <label className="block text-sm font-medium text-[var(--text-body)] mb-1">
+                BCC（カンマ区切り）
+              </label>
+              <input
+                type="text"
+                value={bcc}
+                onChange={(e) => edit(setBcc)(e.target.value)}
+                placeholder="bcc@example.com"
+                className="w-full px-3 py-2 border border-[var(--input-border)] bg-[var(--input-background)] rounded-lg focus:ring-2 focus:ring-[var(--primary-color)] focus:border-[var(--primary-color)] outline-none"
+              />
+            </div>
+            <div>
+              <label className="block text-sm font-medium text-[var(--text-body)] mb-1">
+                返信先（Reply-To）
+              </label>
+              <input
+                type="email"
+                value={replyTo}
+                onChange={(e) => edit(setReplyTo)(e.target.value)}
+                placeholder="reply@example.com"
+                className="w-full px-3 py-2 border border-[var(--input-border)] bg-[var(--input-background)] rounded-lg focus:ring-2 focus:ring-[var(--primary-color)] focus:border-[var(--primary-color)] outline-none"
               />
             </div>
             <div>
@@ -125,7 +316,7 @@ export default function ComposeForm({
               <input
                 type="text"
                 value={subject}
-                onChange={(e) => setSubject(e.target.value)}
+                onChange={(e) => edit(setSubject)(e.target.value)}
                 className="w-full px-3 py-2 border border-[var(--input-border)] bg-[var(--input-background)] rounded-lg focus:ring-2 focus:ring-[var(--primary-color)] focus:border-[var(--primary-color)] outline-none"
                 required
               />
@@ -136,12 +327,72 @@ export default function ComposeForm({
               </label>
               <textarea
                 value={body}
-                onChange={(e) => setBody(e.target.value)}
+                onChange={(e) => edit(setBody)(e.target.value)}
                 rows={12}
                 className="w-full px-3 py-2 border border-[var(--input-border)] bg-[var(--input-background)] rounded-lg focus:ring-2 focus:ring-[var(--primary-color)] focus:border-[var(--primary-color)] outline-none resize-y"
                 required
               />
             </div>
+            <div>
+              <label className="block text-sm font-medium text-[var(--text-body)] mb-1">
+                添付ファイル
+              </label>
+              <input
+                type="file"
+                multiple
+                disabled={uploading}
+                onChange={(e) => {
+                  handleFiles(Array.from(e.target.files ?? []));
+                  e.target.value = "";
+                }}
+                className="text-sm text-[var(--text-body)]"
+              />
+              {attachments.length > 0 && (
+                <ul className="mt-2 space-y-1">
+                  {attachments.map((att) => (
+                    <li
+                      key={att.id}
+                      className="flex items-center justify-between text-sm text-[var(--text-body)]"
+                    >
+                      <span className="truncate">
+                        {att.filename}（{Math.ceil(att.size / 1024)} KB）
+                      </span>
+                      <button
+                        type="button"
+                        onClick={() => handleRemoveAttachment(att.id)}
+                        className="text-red-600 hover:opacity-80"
+                      >
+                        削除
+                      </button>
+                    </li>
+                  ))}
+                </ul>
+              )}
+              {uploading && (
+                <p className="mt-1 text-xs text-[var(--text-body)]">アップロード中...</p>
+              )}
+              {originalS3Key && (
+                <label className="flex items-center gap-2 mt-2 text-sm text-[var(--text-body)]">
+                  <input
+                    type="checkbox"
+                    checked={includeOriginalAttachments}
+                    onChange={(e) => edit(setIncludeOriginalAttachments)(e.target.checked)}
+                  />
+                  元のメールの添付ファイルを含める
+                </label>
+              )}
+            </div>
+            <div>
+              <label className="block text-sm font-medium text-[var(--text-body)] mb-1">
+                予約送信（任意）
+              </label>
+              <input
+                type="datetime-local"
+                value={sendAt}
+                onChange={(e) => setSendAt(e.target.value)}
+                className="px-3 py-2 border border-[var(--input-border)] bg-[var(--input-background)] rounded-lg focus:ring-2 focus:ring-[var(--primary-color)] focus:border-[var(--primary-color)] outline-none"
+              />
+            </div>
             {error && (
               <p className="text-sm text-red-600">{error}</p>
             )}
@@ -149,6 +400,11 @@ export default function ComposeForm({
 
           {/* Footer */}
           <div className="flex items-center justify-end gap-3 p-4 border-t border-[var(--card-border)]">
+            {savedAt && (
+              <span className="mr-auto text-xs text-[var(--text-body)]">
+                下書きを保存しました（{savedAt.toLocaleTimeString()}）
+              </span>
+            )}
             <button
               type="button"
               onClick={onClose}
@@ -158,10 +414,10 @@ export default function ComposeForm({
             </button>
             <button
               type="submit"
-              disabled={sending}
+              disabled={sending || uploading}
               className="px-6 py-2 text-sm bg-[var(--primary-color)] text-[var(--text-heading)] rounded-lg hover:opacity-90 disabled:opacity-50 transition-colors border border-[var(--card-border)]"
             >
-              {sending ? "送信中..." : "送信"}
+              {sending ? "送信中..." : sendAt ? "予約" : "送信"}
             </button>
           </div>
         </form>
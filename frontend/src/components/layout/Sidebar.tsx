@@ -10,6 +10,9 @@ interface SidebarProps {
   onRecipientChange: (recipient: string) => void;
   onDomainChange: (domainId: string) => void;
   onCompose: () => void;
+  onDrafts: () => void;
+  onMerge: () => void;
+  onScheduled: () => void;
   onSettings: () => void;
   onSignOut: () => void;
 }
@@ -22,6 +25,9 @@ export default function Sidebar({
   onRecipientChange,
   onDomainChange,
   onCompose,
+  onDrafts,
+  onMerge,
+  onScheduled,
   onSettings,
   onSignOut,
 }: SidebarProps) {
@@ -137,6 +143,33 @@ export default function Sidebar({
         </nav>
 
         <div className="p-4 border-t border-[var(--card-border)]">
+          <button
+            onClick={() => {
+              onDrafts();
+              setIsOpen(false);
+            }}
+            className="w-full py-2 px-4 text-sm text-[var(--text-body)] hover:opacity-80 transition-colors"
+          >
+            下書き
+          </button>
+          <button
+            onClick={() => {
+              onMerge();
+              setIsOpen(false);
+            }}
+            className="w-full py-2 px-4 text-sm text-[var(--text-body)] hover:opacity-80 transition-colors"
+          >
+            差し込み送信
+          </button>
+          <button
+            onClick={() => {
+              onScheduled();
+              setIsOpen(false);
+            }}
+            className="w-full py-2 px-4 text-sm text-[var(--text-body)] hover:opacity-80 transition-colors"
+          >
+            予約送信
+          </button>
           <button
             onClick={() => {
               onSettings();
@@ -1,6 +1,7 @@
 "use client";
 
 import { useState } from "react";
+import { downloadAttachment, downloadRawMail } from "@/lib/api";
 import type { ParsedMail } from "@/types";
 
 interface MailDetailProps {
@@ -95,6 +96,12 @@ export default function MailDetail({
         >
           削除
         </button>
+        <button
+          onClick={() => downloadRawMail(mail.s3_key).catch(console.error)}
+          className="px-3 py-1.5 text-sm bg-[var(--primary-light)] text-[var(--text-heading)] rounded hover:opacity-90 transition-colors"
+        >
+          .eml を保存
+        </button>
         {mail.html_body && (
           <button
             onClick={() => setShowHtml(!showHtml)}
@@ -125,12 +132,15 @@ export default function MailDetail({
           </h3>
           <div className="flex flex-wrap gap-2">
             {mail.attachments.map((att, idx) => (
-              <div
+              <button
                 key={idx}
-                className="px-3 py-2 bg-[var(--primary-light)] rounded text-sm text-[var(--text-body)]"
+                onClick={() =>
+                  downloadAttachment(mail.s3_key, idx, att.filename).catch(console.error)
+                }
+                className="px-3 py-2 bg-[var(--primary-light)] rounded text-sm text-[var(--text-body)] hover:opacity-90"
               >
                 {att.filename} ({(att.size / 1024).toFixed(1)} KB)
-              </div>
+              </button>
             ))}
           </div>
         </div>
@@ -96,7 +96,7 @@ export default function MailList({
                     {mail.subject || "(件名なし)"}
                   </p>
                   <p className="text-xs text-[var(--text-body)] truncate mt-0.5">
-                    {mail.body?.substring(0, 80)}
+                    {(mail.snippet ?? mail.body)?.substring(0, 80)}
                   </p>
                 </div>
               </div>
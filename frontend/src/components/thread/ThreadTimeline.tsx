@@ -2,7 +2,15 @@
 
 import { useEffect, useState } from "react";
 import { getThread } from "@/lib/api";
-import type { ThreadResponse } from "@/types";
+import type { DeliveryStatus, ThreadResponse } from "@/types";
+
+const deliveryLabels: Record<DeliveryStatus, { label: string; className: string }> = {
+  sent: { label: "送信済み", className: "text-[var(--text-body)]" },
+  delivered: { label: "配達済み", className: "text-green-700" },
+  soft_bounced: { label: "一時エラー", className: "text-yellow-700" },
+  bounced: { label: "不達", className: "text-red-600" },
+  complained: { label: "迷惑メール報告", className: "text-red-600" },
+};
 
 interface ThreadTimelineProps {
   threadId: string;
@@ -82,6 +90,14 @@ export default function ThreadTimeline({ threadId, onClose }: ThreadTimelineProp
                 <span className="text-sm font-medium text-[var(--text-body)]">
                   {msg.type === "sent" ? `宛先: ${msg.to}` : `差出人: ${msg.from}`}
                 </span>
+                {msg.type === "sent" && msg.delivery_status && (
+                  <span
+                    title={msg.delivery_detail}
+                    className={`text-xs ${deliveryLabels[msg.delivery_status].className}`}
+                  >
+                    {deliveryLabels[msg.delivery_status].label}
+                  </span>
+                )}
               </div>
               <span className="text-xs text-[var(--text-body)]">
                 {new Date(msg.date).toLocaleString("ja-JP")}
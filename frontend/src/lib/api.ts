@@ -6,8 +6,21 @@ import type {
   ThreadResponse,
   SendRequest,
   SendResponse,
+  SendStatus,
+  ScheduledSend,
   UserSettings,
   S3Domain,
+  SenderIdentity,
+  EmailIdentityStatus,
+  Draft,
+  DraftInput,
+  SuppressedAddress,
+  MailTemplate,
+  MailTemplateInput,
+  MessagePreview,
+  WebhookSubscription,
+  WebhookSubscriptionInput,
+  WebhookDelivery,
 } from "@/types";
 
 const API_URL = process.env.NEXT_PUBLIC_API_URL || "http://localhost:8080";
@@ -55,6 +68,43 @@ export async function getMail(s3Key: string): Promise<ParsedMail> {
   return apiFetch<ParsedMail>(`/api/mails/${encodeURIComponent(s3Key)}`);
 }
 
+async function downloadFile(path: string, fallbackName: string): Promise<void> {
+  const headers = await getAuthHeaders();
+  const res = await fetch(`${API_URL}${path}`, { headers });
+  if (!res.ok) {
+    const body = await res.json().catch(() => ({}));
+    throw new Error(body.error || `API error: ${res.status}`);
+  }
+
+  const disposition = res.headers.get("Content-Disposition") || "";
+  const match = disposition.match(/filename\*=utf-8''([^;]+)|filename="?([^";]+)"?/i);
+  const filename = match
+    ? decodeURIComponent(match[1] || match[2])
+    : fallbackName;
+
+  const url = URL.createObjectURL(await res.blob());
+  const a = document.createElement("a");
+  a.href = url;
+  a.download = filename;
+  a.click();
+  URL.revokeObjectURL(url);
+}
+
+export async function downloadRawMail(s3Key: string): Promise<void> {
+  await downloadFile(`/api/mails/${encodeURIComponent(s3Key)}/raw`, `${s3Key}.eml`);
+}
+
+export async function downloadAttachment(
+  s3Key: string,
+  index: number,
+  filename: string
+): Promise<void> {
+  await downloadFile(
+    `/api/mails/${encodeURIComponent(s3Key)}/attachments/${index}`,
+    filename
+  );
+}
+
 export async function updateReadStatus(
   s3Key: string,
   isRead: boolean
@@ -93,13 +143,179 @@ export async function getThread(threadId: string): Promise<ThreadResponse> {
   return apiFetch<ThreadResponse>(`/api/threads/${encodeURIComponent(threadId)}`);
 }
 
-export async function sendMail(req: SendRequest): Promise<SendResponse> {
-  return apiFetch<SendResponse>("/api/send", {
+export async function sendMail(
+  req: SendRequest,
+  files: File[] = []
+): Promise<SendResponse> {
+  if (files.length === 0) {
+    return apiFetch<SendResponse>("/api/send", {
+      method: "POST",
+      body: JSON.stringify(req),
+    });
+  }
+
+  const form = new FormData();
+  form.append("payload", JSON.stringify(req));
+  files.forEach((file) => form.append("attachments", file));
+
+  const { Authorization } = (await getAuthHeaders()) as Record<string, string>;
+  const res = await fetch(`${API_URL}/api/send`, {
+    method: "POST",
+    headers: { Authorization },
+    body: form,
+  });
+  if (!res.ok) {
+    const body = await res.json().catch(() => ({}));
+    throw new Error(body.error || `API error: ${res.status}`);
+  }
+  return res.json();
+}
+
+export async function previewMail(req: Partial<SendRequest>): Promise<MessagePreview> {
+  return apiFetch<MessagePreview>("/api/send/preview", {
     method: "POST",
     body: JSON.stringify(req),
   });
 }
 
+export async function getSendStatus(batchId: string): Promise<SendStatus> {
+  return apiFetch<SendStatus>(`/api/send/${encodeURIComponent(batchId)}`);
+}
+
+export async function getScheduledSends(): Promise<ScheduledSend[]> {
+  return apiFetch<ScheduledSend[]>("/api/send/scheduled");
+}
+
+export async function updateScheduledSend(
+  batchId: string,
+  req: SendRequest
+): Promise<SendStatus> {
+  return apiFetch<SendStatus>(`/api/send/${encodeURIComponent(batchId)}`, {
+    method: "PUT",
+    body: JSON.stringify(req),
+  });
+}
+
+export async function rescheduleSend(batchId: string, sendAt: string): Promise<SendStatus> {
+  return apiFetch<SendStatus>(`/api/send/${encodeURIComponent(batchId)}/schedule`, {
+    method: "PATCH",
+    body: JSON.stringify({ send_at: sendAt }),
+  });
+}
+
+export async function cancelSend(batchId: string): Promise<void> {
+  await apiFetch(`/api/send/${encodeURIComponent(batchId)}`, { method: "DELETE" });
+}
+
+export async function createMerge(
+  req: Partial<SendRequest>,
+  csv: File,
+  files: File[] = []
+): Promise<SendResponse> {
+  const form = new FormData();
+  form.append("payload", JSON.stringify(req));
+  form.append("csv", csv);
+  files.forEach((file) => form.append("attachments", file));
+
+  const { Authorization } = (await getAuthHeaders()) as Record<string, string>;
+  const res = await fetch(`${API_URL}/api/merges`, {
+    method: "POST",
+    headers: { Authorization },
+    body: form,
+  });
+  if (!res.ok) {
+    const body = await res.json().catch(() => ({}));
+    throw new Error(body.error || `API error: ${res.status}`);
+  }
+  return res.json();
+}
+
+export async function downloadMergeReport(batchId: string): Promise<void> {
+  await downloadFile(
+    `/api/merges/${encodeURIComponent(batchId)}/report`,
+    `merge-${batchId}.csv`
+  );
+}
+
+export async function getDrafts(): Promise<Draft[]> {
+  return apiFetch<Draft[]>("/api/drafts");
+}
+
+export async function getDraft(id: string): Promise<Draft> {
+  return apiFetch<Draft>(`/api/drafts/${encodeURIComponent(id)}`);
+}
+
+export async function createDraft(draft: DraftInput): Promise<Draft> {
+  return apiFetch<Draft>("/api/drafts", {
+    method: "POST",
+    body: JSON.stringify(draft),
+  });
+}
+
+export async function updateDraft(id: string, draft: DraftInput): Promise<Draft> {
+  return apiFetch<Draft>(`/api/drafts/${encodeURIComponent(id)}`, {
+    method: "PUT",
+    body: JSON.stringify(draft),
+  });
+}
+
+export async function deleteDraft(id: string): Promise<void> {
+  await apiFetch(`/api/drafts/${encodeURIComponent(id)}`, { method: "DELETE" });
+}
+
+export async function uploadDraftAttachments(id: string, files: File[]): Promise<Draft> {
+  const form = new FormData();
+  files.forEach((file) => form.append("attachments", file));
+
+  const { Authorization } = (await getAuthHeaders()) as Record<string, string>;
+  const res = await fetch(`${API_URL}/api/drafts/${encodeURIComponent(id)}/attachments`, {
+    method: "POST",
+    headers: { Authorization },
+    body: form,
+  });
+  if (!res.ok) {
+    const body = await res.json().catch(() => ({}));
+    throw new Error(body.error || `API error: ${res.status}`);
+  }
+  return res.json();
+}
+
+export async function deleteDraftAttachment(id: string, attachmentId: string): Promise<Draft> {
+  return apiFetch<Draft>(
+    `/api/drafts/${encodeURIComponent(id)}/attachments/${encodeURIComponent(attachmentId)}`,
+    { method: "DELETE" }
+  );
+}
+
+export async function sendDraft(id: string, sendAt?: string): Promise<SendResponse> {
+  return apiFetch<SendResponse>(`/api/drafts/${encodeURIComponent(id)}/send`, {
+    method: "POST",
+    body: JSON.stringify(sendAt ? { send_at: sendAt } : {}),
+  });
+}
+
+export async function getTemplates(): Promise<MailTemplate[]> {
+  return apiFetch<MailTemplate[]>("/api/templates");
+}
+
+export async function createTemplate(template: MailTemplateInput): Promise<MailTemplate> {
+  return apiFetch<MailTemplate>("/api/templates", {
+    method: "POST",
+    body: JSON.stringify(template),
+  });
+}
+
+export async function updateTemplate(id: string, template: MailTemplateInput): Promise<MailTemplate> {
+  return apiFetch<MailTemplate>(`/api/templates/${encodeURIComponent(id)}`, {
+    method: "PUT",
+    body: JSON.stringify(template),
+  });
+}
+
+export async function deleteTemplate(id: string): Promise<void> {
+  await apiFetch(`/api/templates/${encodeURIComponent(id)}`, { method: "DELETE" });
+}
+
 export async function getUserSettings(): Promise<UserSettings> {
   return apiFetch<UserSettings>("/api/settings");
 }
@@ -138,3 +354,80 @@ export async function updateDomain(id: string, domain: Partial<S3Domain>): Promi
 export async function deleteDomain(id: string): Promise<void> {
   await apiFetch(`/api/domains/${encodeURIComponent(id)}`, { method: "DELETE" });
 }
+
+export async function getSenderIdentities(): Promise<SenderIdentity[]> {
+  return apiFetch<SenderIdentity[]>("/api/sender-identities");
+}
+
+export async function createSenderIdentity(
+  identity: Partial<SenderIdentity>
+): Promise<SenderIdentity> {
+  return apiFetch<SenderIdentity>("/api/sender-identities", {
+    method: "POST",
+    body: JSON.stringify(identity),
+  });
+}
+
+export async function updateSenderIdentity(
+  id: string,
+  identity: Partial<SenderIdentity>
+): Promise<SenderIdentity> {
+  return apiFetch<SenderIdentity>(`/api/sender-identities/${encodeURIComponent(id)}`, {
+    method: "PUT",
+    body: JSON.stringify(identity),
+  });
+}
+
+export async function deleteSenderIdentity(id: string): Promise<void> {
+  await apiFetch(`/api/sender-identities/${encodeURIComponent(id)}`, { method: "DELETE" });
+}
+
+export async function getSESIdentities(refresh = false): Promise<EmailIdentityStatus[]> {
+  return apiFetch<EmailIdentityStatus[]>(
+    `/api/system/ses-identities${refresh ? "?refresh=true" : ""}`
+  );
+}
+
+export async function getWebhooks(): Promise<WebhookSubscription[]> {
+  return apiFetch<WebhookSubscription[]>("/api/webhooks");
+}
+
+export async function createWebhook(input: WebhookSubscriptionInput): Promise<WebhookSubscription> {
+  return apiFetch<WebhookSubscription>("/api/webhooks", {
+    method: "POST",
+    body: JSON.stringify(input),
+  });
+}
+
+export async function updateWebhook(
+  id: string,
+  input: WebhookSubscriptionInput
+): Promise<WebhookSubscription> {
+  return apiFetch<WebhookSubscription>(`/api/webhooks/${encodeURIComponent(id)}`, {
+    method: "PUT",
+    body: JSON.stringify(input),
+  });
+}
+
+export async function deleteWebhook(id: string): Promise<void> {
+  await apiFetch(`/api/webhooks/${encodeURIComponent(id)}`, { method: "DELETE" });
+}
+
+export async function getWebhookDeliveries(id: string): Promise<WebhookDelivery[]> {
+  return apiFetch<WebhookDelivery[]>(`/api/webhooks/${encodeURIComponent(id)}/deliveries`);
+}
+
+export async function getSuppressions(): Promise<SuppressedAddress[]> {
+  return apiFetch<SuppressedAddress[]>("/api/suppressions");
+}
+
+export async function createSuppression(email: string, detail = ""): Promise<SuppressedAddress> {
+  return apiFetch<SuppressedAddress>("/api/suppressions", {
+    method: "POST",
+    body: JSON.stringify({ email, detail }),
+  });
+}
+
+export async function deleteSuppression(email: string): Promise<void> {
+  await apiFetch(`/api/suppressions/${encodeURIComponent(email)}`, { method: "DELETE" });
+}
@@ -1,13 +1,19 @@
 export interface ParsedMail {
   s3_key: string;
   message_id: string;
+  in_reply_to?: string;
+  references?: string[];
   from: string;
   to: string;
+  cc?: string;
   subject: string;
   body: string;
   html_body?: string;
   date: string;
   attachments?: Attachment[];
+  snippet?: string;
+  attachment_count?: number;
+  attachment_size?: number;
   is_read: boolean;
   is_starred: boolean;
   thread_id?: string;
@@ -43,6 +49,19 @@ export interface ThreadMessage {
   management_code?: string;
   is_read?: boolean;
   is_starred?: boolean;
+  delivery_status?: DeliveryStatus;
+  delivery_detail?: string;
+}
+
+export type DeliveryStatus = "sent" | "delivered" | "soft_bounced" | "bounced" | "complained";
+
+export interface SuppressedAddress {
+  email: string;
+  reason: "bounce" | "complaint";
+  detail?: string;
+  management_code?: string;
+  created_at: string;
+  updated_at: string;
 }
 
 export interface ThreadResponse {
@@ -53,6 +72,9 @@ export interface ThreadResponse {
 
 export interface SendRequest {
   to: string[];
+  cc?: string[];
+  bcc?: string[];
+  reply_to?: string;
   subject: string;
   body: string;
   html_body?: string;
@@ -60,24 +82,259 @@ export interface SendRequest {
   reply_code?: string;
   send_type: "new" | "reply" | "forward";
   from_address?: string;
+  in_reply_to?: string;
+  references?: string[];
+  send_at?: string;
+  attachment_refs?: AttachmentRef[];
+  omit_signature?: boolean;
+  template_id?: string;
+  variables?: Record<string, unknown>;
+}
+
+export interface MailTemplateInput {
+  name: string;
+  subject: string;
+  text_body: string;
+  html_body: string;
+}
+
+export interface MailTemplate extends MailTemplateInput {
+  id: string;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface MessagePreview {
+  from: string;
+  to: string[];
+  cc?: string[];
+  reply_to?: string;
+  subject: string;
+  body: string;
+  html_body?: string;
+  management_code: string;
+  attachments?: string[];
+}
+
+export interface AttachmentRef {
+  s3_key: string;
+  indexes?: number[];
+}
+
+export interface DraftInput {
+  to: string[];
+  cc: string[];
+  bcc: string[];
+  reply_to: string;
+  from_address: string;
+  subject: string;
+  body: string;
+  html_body?: string;
+  send_type: "new" | "reply" | "forward";
+  thread_id?: string;
+  reply_code?: string;
+  in_reply_to?: string;
+  references?: string[];
+  attachment_refs?: AttachmentRef[];
+}
+
+export interface DraftAttachment {
+  id: string;
+  draft_id: string;
+  filename: string;
+  content_type: string;
+  size: number;
+  created_at: string;
+}
+
+export interface Draft extends DraftInput {
+  id: string;
+  status: "editing" | "sending";
+  attachments: DraftAttachment[];
+  created_at: string;
+  updated_at: string;
 }
 
 export interface SendResponse {
+  batch_id: string;
   thread_id: string;
+  status: "scheduled" | "released" | "canceled";
+  send_at: string;
   management_codes: string[];
 }
 
+export interface OutboxRecipient {
+  id: string;
+  row_number?: number;
+  management_code: string;
+  recipient_email: string;
+  recipient_type: "to" | "cc" | "bcc";
+  status: "queued" | "sending" | "sent" | "failed";
+  attempts: number;
+  next_attempt_at: string;
+  last_error?: string;
+  sent_at?: string;
+}
+
+export interface SendStatus {
+  id: string;
+  thread_id: string;
+  kind: "send" | "merge";
+  send_type: "new" | "reply" | "forward";
+  subject: string;
+  status: "scheduled" | "released" | "canceled";
+  send_at: string;
+  created_at: string;
+  recipients: OutboxRecipient[];
+}
+
+export interface ScheduledSend {
+  id: string;
+  thread_id: string;
+  kind: "send" | "merge";
+  send_type: "new" | "reply" | "forward";
+  subject: string;
+  status: "scheduled";
+  send_at: string;
+  created_at: string;
+  to: string[];
+  cc?: string[];
+  bcc?: string[];
+  from_address: string;
+  body: string;
+  html_body?: string;
+  in_reply_to?: string;
+  references?: string[];
+  reply_code?: string;
+}
+
+export type NotificationChannelType = "discord" | "slack" | "teams";
+
+export interface NotificationChannel {
+  id: string;
+  name?: string;
+  type: NotificationChannelType;
+  webhook_url: string;
+  // Empty notifies every mail of the selected domain; ignored once a rule targets the channel
+  recipient_address?: string;
+}
+
+// A rule routes mail matching all of its set conditions to channel_id
+export interface NotificationRule {
+  id: string;
+  name?: string;
+  recipient_address?: string;
+  // Glob such as "*@example.com"; without wildcards matches senders containing it
+  sender_pattern?: string;
+  subject_keywords?: string[];
+  has_attachment?: boolean | null;
+  channel_id: string;
+  // Discord role ID, Slack user/group ID or Teams user principal name
+  mention?: string;
+}
+
+export interface QuietHours {
+  enabled: boolean;
+  start: string;
+  end: string;
+  time_zone: string;
+}
+
+export interface UnreadDigest {
+  enabled: boolean;
+  // Cron expression ("0 8 * * 1-5") or @daily / @weekly
+  schedule: string;
+  time_zone: string;
+  to_address: string;
+}
+
 export interface UserSettings {
-  discord_webhook_url: string;
+  notification_channels: NotificationChannel[];
+  notification_rules: NotificationRule[];
+  quiet_hours: QuietHours;
+  unread_digest: UnreadDigest;
+  // Read-only
+  unread_digest_sent_at?: string | null;
   selected_domain_id: string;
 }
 
+export interface SenderIdentity {
+  id: string;
+  address: string;
+  display_name: string;
+  signature_text: string;
+  signature_html: string;
+  // Only returned to admins; empty means every user may send from the address
+  allowed_uids?: string[];
+  omit_code_from_body: boolean;
+  reply_domain: string;
+  // SMTP MAIL FROM; empty uses address
+  return_path: string;
+}
+
+export interface EmailIdentityStatus {
+  identity: string;
+  type: "EMAIL_ADDRESS" | "DOMAIN" | string;
+  verification_status: string;
+  verified_for_sending: boolean;
+  dkim_status?: string;
+  dkim_signing_enabled: boolean;
+  mail_from_domain?: string;
+  mail_from_status?: string;
+  checked_at: string;
+}
+
+export const WEBHOOK_EVENTS = [
+  "mail.received",
+  "mail.sent",
+  "mail.bounced",
+  "thread.linked",
+  "mail.deleted",
+] as const;
+
+export interface WebhookSubscription {
+  id: string;
+  url: string;
+  // Empty receives every event
+  events: string[];
+  secret: string;
+  description: string;
+  enabled: boolean;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface WebhookSubscriptionInput {
+  url: string;
+  events: string[];
+  // Empty generates a secret on create and keeps the current one on update
+  secret: string;
+  description: string;
+  enabled: boolean;
+}
+
+export interface WebhookDelivery {
+  id: string;
+  subscription_id: string;
+  event: string;
+  payload: string;
+  status: "pending" | "sending" | "succeeded" | "failed";
+  attempts: number;
+  next_attempt_at: string;
+  response_status?: number;
+  last_error?: string;
+  delivered_at?: string;
+  created_at: string;
+}
+
 export interface S3Domain {
   id: string;
   name: string;
+  storage_type: "s3" | "filesystem";
   bucket: string;
   region: string;
   access_key_id: string;
   secret_key: string;
   endpoint: string;
+  insecure_tls: boolean;
 }